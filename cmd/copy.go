@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go-hexagon/internal/adapter/database"
+	"go-hexagon/internal/adapter/repository"
+	"go-hexagon/internal/core/migration"
+	"go-hexagon/internal/core/port"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"gorm.io/gorm"
+)
+
+// productStore is a product backend opened by a command other than the
+// server.
+type productStore struct {
+	products port.ProductRepository
+	ids      port.ProductIDMap
+	close    func()
+}
+
+// runCopy implements `main copy`, which copies all products from one
+// backend to another and exits with 1 unless the copy was verified.
+func runCopy(args []string) int {
+	flags := flag.NewFlagSet("copy", flag.ExitOnError)
+	from := flags.String("from", "", "Backend products are copied from: mysql, postgres or mongodb")
+	to := flags.String("to", "", "Backend products are copied to: mysql, postgres or mongodb")
+	fromDSN := flags.String("from-dsn", "", "DSN of a MySQL or Postgres source (a local database when empty)")
+	toDSN := flags.String("to-dsn", "", "DSN of a MySQL or Postgres target (a local database when empty)")
+	batchSize := flags.Int("batch-size", migration.DefaultBatchSize, "Products read and written per batch")
+	checkpointFile := flags.String("checkpoint", "copy-checkpoint.json", "File the progress is kept in, to resume an interrupted copy (disabled when empty)")
+	flags.Parse(args)
+	if *from == "" || *to == "" || *from == *to {
+		fmt.Fprintln(os.Stderr, "copy needs two different backends in --from and --to")
+		flags.Usage()
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// The ID map is kept in the source, as with --dual-write, so a copy can
+	// backfill the store the server then mirrors its writes to.
+	source, err := openProductStore(ctx, *from, *fromDSN, false)
+	if err != nil {
+		slog.Error("Failed to open source", slog.String("backend", *from), slog.String("error", err.Error()))
+		return 1
+	}
+	defer source.close()
+	// The target may be a new database, whose products table is created.
+	target, err := openProductStore(ctx, *to, *toDSN, true)
+	if err != nil {
+		slog.Error("Failed to open target", slog.String("backend", *to), slog.String("error", err.Error()))
+		return 1
+	}
+	defer target.close()
+
+	copier := migration.NewCopier(source.products, target.products, source.ids, migration.CopyConfig{
+		Source:         *from,
+		Target:         *to,
+		BatchSize:      *batchSize,
+		CheckpointFile: *checkpointFile,
+	})
+	result, err := copier.Run(ctx)
+	if err != nil {
+		slog.Error("Copy failed", slog.String("error", err.Error()))
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(result)
+	if !result.Verified {
+		slog.Error("Copy does not match the source", slog.Any("source", result.Source), slog.Any("target", result.Target))
+		return 1
+	}
+	return 0
+}
+
+// openProductStore connects to backend without the decorators of the
+// server, so batch inserts reach the repository.
+func openProductStore(ctx context.Context, backend, dsn string, createProducts bool) (*productStore, error) {
+	switch backend {
+	case database.BackendMySQL, database.BackendPostgres:
+		if dsn == "" {
+			dsn = defaultDSNs[backend]
+		}
+		db, err := database.ConnectSQL(ctx, slog.Default(), backend, dsn, newGormConfig(), database.DefaultSQLPoolConfig(), database.DefaultRetryConfig())
+		if err != nil {
+			return nil, err
+		}
+		closeDB := func() {
+			sqlDBConn, _ := db.DB()
+			sqlDBConn.Close()
+		}
+		if err := migrateProductStore(db, createProducts); err != nil {
+			closeDB()
+			return nil, err
+		}
+		return &productStore{
+			products: repository.NewProductRepositoryMySQL(db),
+			ids:      repository.NewProductIDMapMySQL(db),
+			close:    closeDB,
+		}, nil
+	case "mongodb":
+		client, err := database.ConnectMongoDB(ctx, slog.Default(), database.DefaultRetryConfig())
+		if err != nil {
+			return nil, err
+		}
+		db := client.Database("mydb")
+		ids := repository.NewProductIDMapMongo(db).(*repository.ProductIDMapMongo)
+		if err := ids.EnsureIndexes(ctx); err != nil {
+			client.Disconnect(context.Background())
+			return nil, err
+		}
+		return &productStore{
+			products: repository.NewProductRepositoryMongo(db),
+			ids:      ids,
+			close:    func() { client.Disconnect(context.Background()) },
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown backend %q", backend)
+	}
+}
+
+// migrateProductStore migrates the tables a product store uses. The
+// products table is only created with createProducts.
+func migrateProductStore(db *gorm.DB, createProducts bool) error {
+	migrateProducts := repository.MigrateProducts
+	if createProducts {
+		migrateProducts = repository.CreateProducts
+	}
+	if err := migrateProducts(db); err != nil {
+		return err
+	}
+	return repository.MigrateProductIDMap(db)
+}
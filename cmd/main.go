@@ -3,109 +3,619 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
+	"go-hexagon/internal/adapter/auth"
+	"go-hexagon/internal/adapter/cache"
 	"go-hexagon/internal/adapter/database"
+	"go-hexagon/internal/adapter/eventstream"
+	"go-hexagon/internal/adapter/handler/graphql"
+	grpcadapter "go-hexagon/internal/adapter/handler/grpc"
 	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/adapter/health"
+	"go-hexagon/internal/adapter/idempotency"
+	"go-hexagon/internal/adapter/logging"
+	"go-hexagon/internal/adapter/metrics"
+	"go-hexagon/internal/adapter/openapi"
+	"go-hexagon/internal/adapter/ratelimit"
 	"go-hexagon/internal/adapter/repository"
 	"go-hexagon/internal/adapter/routes"
+	"go-hexagon/internal/adapter/tracing"
+	"go-hexagon/internal/adapter/webhook"
+	"go-hexagon/internal/core/policy"
+	"go-hexagon/internal/core/port"
 	"go-hexagon/internal/core/service"
-	"log"
+	"log/slog"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/mongo"
-	"gorm.io/driver/mysql"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+	"google.golang.org/grpc"
 	"gorm.io/gorm"
 )
 
+// v1Deprecated is when /api/v2 was introduced.
+var v1Deprecated = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
 var (
-	sqlDB   *gorm.DB
-	mongoDB *mongo.Client
+	sqlDB      *gorm.DB
+	replicaSet *database.ReplicaSet
+	mongoDB    *mongo.Client
+	dispatcher *webhook.Dispatcher
+	broker     *eventstream.Broker
+	grpcServer *grpc.Server
+	appMetrics *metrics.Metrics
+	checks     *health.Health
+
+	connectRetry database.RetryConfig
+	sqlPool      database.SQLPoolConfig
+	mongoPool    database.MongoPoolConfig
+
+	dualWriteBackend string
+	dualWriteDSN     string
+	dualWriteConfig  repository.DualWriteConfig
+	// secondaryDB is the SQL database products are mirrored to with
+	// --dual-write, if it is not sqlDB's backend.
+	secondaryDB *gorm.DB
+
+	cacheSize    int
+	cacheConfig  repository.CacheConfig
+	productCache *repository.CachedProductRepository
 )
 
 func main() {
-	dbType := flag.String("db", "mysql", "Database type: mysql or mongodb")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "copy":
+			os.Exit(runCopy(os.Args[2:]))
+		case "verify":
+			os.Exit(runVerify(os.Args[2:]))
+		}
+	}
+
+	dbType := flag.String("db", "mysql", "Database type: mysql, postgres or mongodb")
+	dsn := flag.String("db-dsn", "", "DSN of the MySQL or Postgres primary (a local database when empty)")
+	var replicaDSNs []string
+	flag.Func("db-replica-dsn", "DSN of a MySQL or Postgres read replica; repeat the flag for more replicas", func(dsn string) error {
+		replicaDSNs = append(replicaDSNs, dsn)
+		return nil
+	})
+	var trustedProxies []string
+	flag.Func("trusted-proxy", "IP or CIDR of a reverse proxy whose --proxy-header is trusted for the client IP; repeat the flag for more proxies", func(proxy string) error {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("not an IP or CIDR: %q", proxy)
+			}
+		}
+		trustedProxies = append(trustedProxies, proxy)
+		return nil
+	})
+	proxyHeader := flag.String("proxy-header", fiber.HeaderXForwardedFor, "Header the --trusted-proxy puts the client IP in")
+	grpcAddr := flag.String("grpc-addr", "", "Address for the gRPC server, e.g. :50051 (disabled when empty)")
+	v1Sunset := flag.String("v1-sunset", "", "Date (YYYY-MM-DD) announced in the Sunset header of /api/v1 responses")
+	jwksFile := flag.String("jwks-file", "", "JSON Web Key Set used to verify bearer tokens")
+	apiKeysFile := flag.String("api-keys-file", "", "JSON file with the SHA-256 of accepted API keys")
+	jwtIssuer := flag.String("jwt-issuer", "", "Required iss claim of bearer tokens")
+	jwtAudience := flag.String("jwt-audience", "", "Required aud claim of bearer tokens")
+	policyFile := flag.String("policy-file", "", "JSON file overriding the roles allowed per action and route")
+	rateLimitFile := flag.String("rate-limit-file", "", "JSON file with the read and write rate limits and per-client overrides")
+	idempotencyTTL := flag.Duration("idempotency-ttl", idempotency.DefaultTTL, "How long responses to requests with an Idempotency-Key are kept for replay")
+	cacheControlFile := flag.String("cache-control-file", "", "JSON file with the Cache-Control header per GET route")
+	redisAddr := flag.String("redis-addr", "", "Redis address shared by all instances for rate limiting, e.g. localhost:6379 (in memory when empty)")
+	logFormat := flag.String("log-format", logging.FormatJSON, "Log format: json or text")
+	logLevel := flag.String("log-level", "info", "Lowest level logged: debug, info, warn or error")
+	traceExporter := flag.String("trace-exporter", tracing.ExporterNone, "Where spans are written: none, stdout or file")
+	traceFile := flag.String("trace-file", "traces.jsonl", "File spans are appended to as OTLP JSON with --trace-exporter=file")
+	shutdownDrain := flag.Duration("shutdown-drain", 5*time.Second, "How long /readyz fails before the server stops accepting requests on shutdown")
+	connectRetry = database.DefaultRetryConfig()
+	flag.DurationVar(&connectRetry.MaxWait, "db-connect-wait", connectRetry.MaxWait, "How long connecting to the database is retried at startup (0 tries once)")
+	flag.DurationVar(&connectRetry.BaseBackoff, "db-connect-backoff", connectRetry.BaseBackoff, "Delay before the first retry, doubled after every failed attempt")
+	flag.DurationVar(&connectRetry.MaxBackoff, "db-connect-max-backoff", connectRetry.MaxBackoff, "Longest delay between two connection attempts")
+	sqlPool = database.DefaultSQLPoolConfig()
+	flag.IntVar(&sqlPool.MaxOpenConns, "db-max-open-conns", sqlPool.MaxOpenConns, "Maximum open SQL connections (0 is unlimited)")
+	flag.IntVar(&sqlPool.MaxIdleConns, "db-max-idle-conns", sqlPool.MaxIdleConns, "Maximum idle SQL connections kept in the pool")
+	flag.DurationVar(&sqlPool.ConnMaxLifetime, "db-conn-max-lifetime", sqlPool.ConnMaxLifetime, "How long a SQL connection is reused before it is closed (0 is forever)")
+	flag.DurationVar(&sqlPool.ConnMaxIdleTime, "db-conn-max-idle-time", sqlPool.ConnMaxIdleTime, "How long a SQL connection may stay idle before it is closed (0 is forever)")
+	mongoPool = database.DefaultMongoPoolConfig()
+	flag.Uint64Var(&mongoPool.MinPoolSize, "mongo-min-pool-size", mongoPool.MinPoolSize, "Minimum MongoDB connections kept open per server")
+	flag.Uint64Var(&mongoPool.MaxPoolSize, "mongo-max-pool-size", mongoPool.MaxPoolSize, "Maximum MongoDB connections per server")
+	flag.DurationVar(&mongoPool.MaxConnIdleTime, "mongo-max-conn-idle-time", mongoPool.MaxConnIdleTime, "How long a MongoDB connection may stay idle before it is closed (0 is forever)")
+	flag.DurationVar(&mongoPool.ConnectTimeout, "mongo-connect-timeout", mongoPool.ConnectTimeout, "Timeout for opening a MongoDB connection")
+	flag.DurationVar(&mongoPool.ServerSelectionTimeout, "mongo-server-selection-timeout", mongoPool.ServerSelectionTimeout, "How long an operation waits for a usable MongoDB server")
+	flag.StringVar(&dualWriteBackend, "dual-write", "", "Backend every product write is mirrored to while migrating: mysql, postgres or mongodb (disabled when empty)")
+	flag.StringVar(&dualWriteDSN, "dual-write-dsn", "", "DSN of the MySQL or Postgres database of --dual-write (a local database when empty)")
+	flag.StringVar(&dualWriteConfig.ReadFrom, "dual-write-read", repository.SidePrimary, "Side products are read from with --dual-write: primary or secondary")
+	flag.BoolVar(&dualWriteConfig.CompareReads, "dual-write-compare", false, "Also read products by ID from the other side with --dual-write and report differences")
+	flag.IntVar(&cacheSize, "cache-size", 10000, "Number of product lookups kept in the in-process cache (0 disables it)")
+	flag.DurationVar(&cacheConfig.TTL, "cache-ttl", repository.DefaultCacheConfig().TTL, "How long a product looked up by ID stays cached")
+	flag.DurationVar(&cacheConfig.ListTTL, "cache-list-ttl", repository.DefaultCacheConfig().ListTTL, "How long product lists and pages stay cached")
+	flag.DurationVar(&cacheConfig.FillDelay, "cache-fill-delay", time.Second, "How long after a write product lookups are not cached, so a lagging read replica cannot put the old row back")
 	flag.Parse()
+	if dualWriteBackend == *dbType {
+		slog.Error("--dual-write must name another backend than --db", slog.String("db", *dbType))
+		os.Exit(1)
+	}
+
+	logger, err := logging.New(os.Stdout, *logFormat, *logLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid logging config: %v\n", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(tracing.Config{ServiceName: "go-hexagon", Exporter: *traceExporter, File: *traceFile})
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
+
+	authenticator := setupAuth(*jwksFile, *apiKeysFile, *jwtIssuer, *jwtAudience)
 
-	app := fiber.New()
+	checks = health.New()
+	// The client IP keys the IP rate limit and is logged and audited, so it
+	// is only taken from a header sent by a trusted proxy.
+	app := fiber.New(rest.ProxyConfig(trustedProxies, *proxyHeader))
+	// Registered first so the latency covers every other middleware.
+	appMetrics = metrics.New()
+	app.Use(appMetrics.Middleware())
+	app.Use(tracing.Middleware())
+	app.Use(rest.RequestInfo())
+	app.Use(logging.AccessLog(logger))
+	app.Use(rest.ReadYourWrites())
+	broker = eventstream.NewBroker(1000)
 
+	v1 := routes.APIVersion{Name: "v1", Deprecated: v1Deprecated, Successor: "v2"}
+	if *v1Sunset != "" {
+		sunset, err := time.Parse(time.DateOnly, *v1Sunset)
+		if err != nil {
+			fatal("Invalid --v1-sunset", err)
+		}
+		v1.Sunset = sunset
+	}
+	// Version negotiation rewrites unversioned paths, so it has to run before
+	// the validator matches the path against the document.
+	apiVersions := routes.NewAPIVersions(app, "v1", v1, routes.APIVersion{Name: "v2"})
+
+	// The IP limit runs before authentication so that failed and forbidden
+	// requests are throttled too; the per-principal limits run after it.
+	limiter := setupRateLimit(*rateLimitFile, *redisAddr)
+	app.Use(limiter.IPMiddleware())
+
+	// Without authentication there are no roles to check, so the policy is
+	// only enforced together with it.
+	var accessPolicy *policy.Policy
+	if authenticator.Enabled() {
+		accessPolicy = setupPolicy(*policyFile)
+		app.Use(authenticator.Middleware("/docs", "/openapi.json", "/metrics", "/healthz", "/readyz", "/check-mysql", "/check-mongo"))
+		app.Use(auth.Authorize(accessPolicy))
+	} else {
+		slog.Warn("No JWT keys or API keys configured, authentication is disabled")
+	}
+	app.Use(limiter.Middleware())
+
+	// The validator must be registered before any route so it runs first.
+	validator, err := openapi.NewValidator(openapi.Document(), openapi.DefaultValidatorConfig())
+	if err != nil {
+		fatal("Failed to build request validator", err)
+	}
+	app.Use(validator.Middleware())
+	app.Use(rest.CacheControl(setupCacheControl(*cacheControlFile)))
+
+	var productService *service.ProductService
 	switch *dbType {
-	case "mysql":
-		setupMySQL(app)
+	case database.BackendMySQL, database.BackendPostgres:
+		productService = setupSQL(app, apiVersions, *dbType, *dsn, replicaDSNs, accessPolicy, *idempotencyTTL)
 	case "mongodb":
-		setupMongo(app)
+		productService = setupMongo(app, apiVersions, accessPolicy, *idempotencyTTL)
 	default:
-		log.Fatalf("Unknown database type: %s", *dbType)
+		slog.Error("Unknown database type", slog.String("db", *dbType))
+		os.Exit(1)
+	}
+
+	graphqlHandler, err := graphql.NewHandler(productService)
+	if err != nil {
+		fatal("Failed to build GraphQL schema", err)
+	}
+	routes.GraphQLRoutes(app, graphqlHandler)
+
+	docsHandler, err := openapi.NewHandler()
+	if err != nil {
+		fatal("Failed to build OpenAPI document", err)
+	}
+	routes.DocsRoutes(app, docsHandler)
+	routes.HealthRoutes(app, checks)
+	routes.MetricsRoutes(app, appMetrics)
+
+	if *grpcAddr != "" {
+		var opts []grpc.ServerOption
+		if authenticator.Enabled() {
+			opts = append(opts,
+				grpc.ChainUnaryInterceptor(authenticator.UnaryServerInterceptor()),
+				grpc.ChainStreamInterceptor(authenticator.StreamServerInterceptor()),
+			)
+		}
+		startGRPC(*grpcAddr, grpcadapter.NewProductServer(productService, broker), opts...)
 	}
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	stopped := make(chan struct{})
 	go func() {
 		<-c
-		log.Println("Shutting down")
-		if sqlDB != nil {
-			sqlDBConn, _ := sqlDB.DB()
-			sqlDBConn.Close()
+		// Load balancers see /readyz fail and stop routing to this instance
+		// while it still serves what they already sent.
+		slog.Info("Draining", slog.Duration("period", *shutdownDrain))
+		checks.Drain()
+		time.Sleep(*shutdownDrain)
+
+		slog.Info("Shutting down")
+		if err := app.ShutdownWithTimeout(10 * time.Second); err != nil {
+			slog.Error("Failed to stop HTTP server", slog.String("error", err.Error()))
+		}
+		if grpcServer != nil {
+			stopGRPC()
+		}
+		if dispatcher != nil {
+			dispatcher.Stop()
+		}
+		if replicaSet != nil {
+			replicaSet.Stop()
+		}
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("Failed to flush traces", slog.String("error", err.Error()))
+		}
+		if productCache != nil {
+			stats := productCache.Stats()
+			slog.Info("Product cache", slog.Uint64("hits", stats.Hits), slog.Uint64("misses", stats.Misses))
+		}
+		for _, db := range []*gorm.DB{sqlDB, secondaryDB} {
+			if db != nil {
+				sqlDBConn, _ := db.DB()
+				sqlDBConn.Close()
+			}
 		}
 		if mongoDB != nil {
 			mongoDB.Disconnect(context.Background())
 		}
-		os.Exit(0)
+		close(stopped)
 	}()
 
-	log.Fatal(app.Listen(":3000"))
+	if err := app.Listen(":3000"); err != nil {
+		fatal("HTTP server stopped", err)
+	}
+	<-stopped
 }
 
-func setupMySQL(app *fiber.App) {
-	dsn := "root:@tcp(127.0.0.1:3306)/db_store_go?charset=utf8mb4&parseTime=True&loc=Local"
-	var err error
-	sqlDB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		log.Fatalf("Failed to connect to MySQL: %v", err)
+// setupSQL serves the products from MySQL or Postgres, reading from the
+// replicas if there are any.
+func setupSQL(app *fiber.App, apiVersions *routes.APIVersions, backend, dsn string, replicaDSNs []string, accessPolicy *policy.Policy, idempotencyTTL time.Duration) *service.ProductService {
+	sqlDB = connectSQL(backend, dsn)
+	if err := repository.MigrateSQL(sqlDB); err != nil {
+		fatal("Failed to migrate tables", err)
+	}
+
+	var replicas []*gorm.DB
+	for i, replicaDSN := range replicaDSNs {
+		replica, err := database.OpenReplica(backend, replicaDSN, newGormConfig(), sqlPool)
+		if err != nil {
+			fatal("Failed to open replica", err)
+		}
+		instrumentSQL(replica, fmt.Sprintf("%s-replica-%d", backend, i))
+		replicas = append(replicas, replica)
 	}
+	replicaSet = database.NewReplicaSet(sqlDB, replicas, database.DefaultReplicaConfig())
+	replicaSet.Start()
 
-	productRepo := repository.NewProductRepositoryMySQL(sqlDB)
-	productService := service.NewProductService(productRepo)
+	webhookRepo := repository.NewWebhookRepositoryMySQL(sqlDB)
+	dispatcher = webhook.NewDispatcher(webhookRepo, slog.Default(), webhook.DefaultConfig())
+	dispatcher.Start()
+	checks.AddReadiness("webhook_dispatcher", dispatcher)
+
+	auditRepo := repository.NewAuditRepositoryMySQL(sqlDB)
+	products := repository.NewProductRepositoryReplicated(replicaSet).(*repository.ProductRepositoryMySQL)
+	if err := products.EnsureSearchIndex(context.Background()); err != nil {
+		fatal("Failed to create the product search index", err)
+	}
+	productRepo := appMetrics.ProductRepository(products, backend)
+	productRepo = cacheProducts(dualWrite(productRepo, repository.NewProductIDMapMySQL(sqlDB)))
+	productService := service.NewProductService(productRepo,
+		service.WithSearcher(appMetrics.ProductSearcher(products, backend)),
+		service.WithPublisher(dispatcher),
+		service.WithPublisher(broker),
+		service.WithPolicy(accessPolicy),
+		service.WithAudit(auditRepo),
+		service.WithLogger(slog.Default()),
+	)
 	productHandler := rest.NewProductHandlerMySQL(productService)
+	webhookHandler := rest.NewWebhookHandler(service.NewWebhookService(webhookRepo, dispatcher))
+	auditHandler := rest.NewAuditHandler(service.NewAuditService(auditRepo, accessPolicy))
+
+	streamHandler := rest.NewProductStreamHandler(productService, broker)
+
+	// Registered here because the store needs the database, but still before
+	// the routes so it wraps them.
+	app.Use(idempotency.New(repository.NewIdempotencyStoreMySQL(sqlDB), idempotencyTTL).Middleware())
+
+	apiVersions.Handle("v1", func(api fiber.Router) {
+		routes.ProductStreamRoutes(api, streamHandler)
+		routes.ProductRoutesMySQL(api, productHandler)
+		routes.WebhookRoutes(api, webhookHandler)
+		routes.AuditRoutes(api, auditHandler)
+	})
+	apiVersions.Handle("v2", func(api fiber.Router) {
+		routes.ProductStreamRoutes(api, streamHandler)
+		routes.ProductRoutesMySQLV2(api, productHandler)
+		routes.WebhookRoutes(api, webhookHandler)
+		routes.AuditRoutes(api, auditHandler)
+	})
+
+	return productService
+}
+
+func setupMongo(app *fiber.App, apiVersions *routes.APIVersions, accessPolicy *policy.Policy, idempotencyTTL time.Duration) *service.ProductService {
+	mongoDB = connectMongo()
+	db := mongoDB.Database("mydb")
+	webhookRepo := repository.NewWebhookRepositoryMongo(db)
+	dispatcher = webhook.NewDispatcher(webhookRepo, slog.Default(), webhook.DefaultConfig())
+	dispatcher.Start()
+	checks.AddReadiness("webhook_dispatcher", dispatcher)
+
+	auditRepo := repository.NewAuditRepositoryMongo(db)
+	searcher := repository.NewProductRepositoryMongo(db).(*repository.ProductRepositoryMongo)
+	if err := searcher.EnsureSearchIndex(context.Background()); err != nil {
+		fatal("Failed to create the product search index", err)
+	}
+	productRepo := mongoProducts(db)
+	if dualWriteBackend != "" {
+		ids := repository.NewProductIDMapMongo(db).(*repository.ProductIDMapMongo)
+		if err := ids.EnsureIndexes(context.Background()); err != nil {
+			fatal("Failed to index the product ID map", err)
+		}
+		productRepo = dualWrite(productRepo, ids)
+	}
+	productRepo = cacheProducts(productRepo)
+	productService := service.NewProductService(productRepo,
+		service.WithSearcher(appMetrics.ProductSearcher(searcher, "mongodb")),
+		service.WithPublisher(dispatcher),
+		service.WithPublisher(broker),
+		service.WithPolicy(accessPolicy),
+		service.WithAudit(auditRepo),
+		service.WithLogger(slog.Default()),
+	)
+	productHandler := rest.NewProductHandlerMongo(productService)
+	webhookHandler := rest.NewWebhookHandler(service.NewWebhookService(webhookRepo, dispatcher))
+	auditHandler := rest.NewAuditHandler(service.NewAuditService(auditRepo, accessPolicy))
+
+	streamHandler := rest.NewProductStreamHandler(productService, broker)
 
-	routes.ProductRoutesMySQL(app, productHandler)
+	// Registered here because the store needs the database, but still before
+	// the routes so it wraps them.
+	app.Use(idempotency.New(repository.NewIdempotencyStoreMongo(db), idempotencyTTL).Middleware())
 
-	app.Get("/check-mysql", checkMySQL)
+	apiVersions.Handle("v1", func(api fiber.Router) {
+		routes.ProductStreamRoutes(api, streamHandler)
+		routes.ProductRoutesMongodb(api, productHandler)
+		routes.WebhookRoutes(api, webhookHandler)
+		routes.AuditRoutes(api, auditHandler)
+	})
+	apiVersions.Handle("v2", func(api fiber.Router) {
+		routes.ProductStreamRoutes(api, streamHandler)
+		routes.ProductRoutesMongodbV2(api, productHandler)
+		routes.WebhookRoutes(api, webhookHandler)
+		routes.AuditRoutes(api, auditHandler)
+	})
+
+	return productService
 }
 
-func setupMongo(app *fiber.App) {
-	var err error
-	mongoDB, err = database.ConnectMongoDB()
+// connectSQL connects to the primary database of backend and checks it for
+// readiness.
+func connectSQL(backend, dsn string) *gorm.DB {
+	if dsn == "" {
+		dsn = defaultDSNs[backend]
+	}
+	db, err := database.ConnectSQL(context.Background(), slog.Default(), backend, dsn, newGormConfig(), sqlPool, connectRetry)
 	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
+		fatal("Failed to connect to "+backend, err)
 	}
+	instrumentSQL(db, backend)
+	sqlDBConn, _ := db.DB()
+	checks.AddReadiness(backend, health.SQL(sqlDBConn))
+	return db
+}
 
-	db := mongoDB.Database("mydb")
-	productRepo := repository.NewProductRepositoryMongo(db)
-	productService := service.NewProductService(productRepo)
-	productHandler := rest.NewProductHandlerMongo(productService)
+func connectMongo() *mongo.Client {
+	client, err := database.ConnectMongoDB(context.Background(), slog.Default(), connectRetry, mongoPool.Options(), options.Client().
+		SetPoolMonitor(appMetrics.MongoPoolMonitor()).
+		SetMonitor(otelmongo.NewMonitor()))
+	if err != nil {
+		fatal("Failed to connect to MongoDB", err)
+	}
+	checks.AddReadiness("mongodb", health.Mongo(client))
+	return client
+}
 
-	routes.ProductRoutesMongodb(app, productHandler)
+// mongoProducts returns the instrumented product repository of db. MySQL
+// errors are logged by GORM, MongoDB's by a decorator.
+func mongoProducts(db *mongo.Database) port.ProductRepository {
+	productRepo := logging.ProductRepository(repository.NewProductRepositoryMongo(db), slog.Default(), "mongodb")
+	return appMetrics.ProductRepository(productRepo, "mongodb")
+}
+
+// dualWrite mirrors the writes to primary on the backend of --dual-write,
+// unless it is empty. ids is stored next to the primary, whose IDs clients
+// keep using.
+func dualWrite(primary port.ProductRepository, ids port.ProductIDMap) port.ProductRepository {
+	var secondary port.ProductRepository
+	switch dualWriteBackend {
+	case "":
+		return primary
+	case database.BackendMySQL, database.BackendPostgres:
+		secondaryDB = connectSQL(dualWriteBackend, dualWriteDSN)
+		if err := repository.CreateProducts(secondaryDB); err != nil {
+			fatal("Failed to create the --dual-write products table", err)
+		}
+		secondary = appMetrics.ProductRepository(repository.NewProductRepositoryMySQL(secondaryDB), dualWriteBackend)
+	case "mongodb":
+		mongoDB = connectMongo()
+		secondary = mongoProducts(mongoDB.Database("mydb"))
+	default:
+		fatal("Invalid --dual-write", fmt.Errorf("unknown backend %q", dualWriteBackend))
+	}
+	if dualWriteConfig.ReadFrom != repository.SidePrimary && dualWriteConfig.ReadFrom != repository.SideSecondary {
+		fatal("Invalid --dual-write-read", fmt.Errorf("unknown side %q", dualWriteConfig.ReadFrom))
+	}
 
-	app.Get("/check-mongo", checkMongo)
+	repo := repository.NewDualWriteProductRepository(primary, secondary, ids, dualWriteConfig)
+	countDivergence := appMetrics.DualWriteDivergences()
+	repo.OnDivergence = func(ctx context.Context, divergence repository.ProductDivergence) {
+		countDivergence(divergence.Kind, divergence.Operation)
+	}
+	slog.Info("Mirroring product writes", slog.String("secondary", dualWriteBackend), slog.String("read_from", dualWriteConfig.ReadFrom))
+	return repo
 }
 
-func checkMySQL(c *fiber.Ctx) error {
-	sqlDBConn, err := sqlDB.DB()
+var defaultDSNs = map[string]string{
+	database.BackendMySQL:    "root:@tcp(127.0.0.1:3306)/db_store_go?charset=utf8mb4&parseTime=True&loc=Local",
+	database.BackendPostgres: "host=localhost user=postgres dbname=db_store_go port=5432 sslmode=disable password=admin",
+}
+
+// newGormConfig returns a config per connection, since GORM keeps its
+// callbacks in it.
+func newGormConfig() *gorm.Config {
+	return &gorm.Config{Logger: logging.NewGormLogger(slog.Default())}
+}
+
+// instrumentSQL traces the statements of db and exports its pool metrics.
+func instrumentSQL(db *gorm.DB, name string) {
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		fatal("Failed to enable tracing for "+name, err)
+	}
+	sqlDBConn, err := db.DB()
 	if err != nil {
-		return c.Status(500).SendString("Failed to get MySQL database connection")
+		fatal("Failed to get database connection of "+name, err)
 	}
+	appMetrics.RegisterSQLPool(sqlDBConn, name)
+}
 
-	if err := sqlDBConn.Ping(); err != nil {
-		return c.Status(500).SendString("Failed to connect to MySQL")
+// setupAuth builds the authenticator from the flags and the JWT_SECRET
+// environment variable (HS256 tokens without a kid).
+func setupAuth(jwksFile, apiKeysFile, issuer, audience string) *auth.Authenticator {
+	keys := auth.NewKeySet()
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		keys.AddHMAC("", []byte(secret))
+	}
+	if jwksFile != "" {
+		if err := keys.LoadJWKS(jwksFile); err != nil {
+			fatal("Failed to load JWKS", err)
+		}
 	}
-	return c.SendString("Successfully connected to MySQL")
+
+	var apiKeys []auth.APIKey
+	if apiKeysFile != "" {
+		var err error
+		if apiKeys, err = auth.LoadAPIKeys(apiKeysFile); err != nil {
+			fatal("Failed to load API keys", err)
+		}
+	}
+
+	authenticator, err := auth.NewAuthenticator(auth.Config{Keys: keys, Issuer: issuer, Audience: audience, APIKeys: apiKeys})
+	if err != nil {
+		fatal("Invalid authentication config", err)
+	}
+	return authenticator
 }
 
-func checkMongo(c *fiber.Ctx) error {
-	if err := mongoDB.Ping(context.Background(), nil); err != nil {
-		return c.Status(500).SendString("Failed to connect to MongoDB")
+func setupPolicy(path string) *policy.Policy {
+	if path == "" {
+		return policy.Default()
+	}
+	p, err := policy.Load(path)
+	if err != nil {
+		fatal("Failed to load policy", err)
+	}
+	return p
+}
+
+// cacheProducts puts the read-through cache in front of repo unless
+// --cache-size is 0.
+func cacheProducts(repo port.ProductRepository) port.ProductRepository {
+	if cacheSize <= 0 {
+		return repo
+	}
+	store := cache.NewLRU(cacheSize)
+	checks.AddReadiness("cache", health.Cache(store))
+	productCache = repository.NewCachedProductRepository(repo, store, cacheConfig)
+	appMetrics.RegisterProductCache(productCache)
+	return productCache
+}
+
+func setupCacheControl(path string) rest.CacheControlConfig {
+	if path == "" {
+		return rest.DefaultCacheControlConfig()
+	}
+	config, err := rest.LoadCacheControlConfig(path)
+	if err != nil {
+		fatal("Failed to load cache control", err)
+	}
+	return config
+}
+
+func setupRateLimit(path, redisAddr string) *ratelimit.Limiter {
+	config := ratelimit.DefaultConfig()
+	if path != "" {
+		var err error
+		if config, err = ratelimit.LoadConfig(path); err != nil {
+			fatal("Failed to load rate limits", err)
+		}
+	}
+
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if redisAddr != "" {
+		client := redis.NewClient(&redis.Options{Addr: redisAddr})
+		checks.AddReadiness("redis", health.Redis(client))
+		store = ratelimit.NewRedisStore(client, "go-hexagon:ratelimit:")
+	}
+	return ratelimit.New(config, store)
+}
+
+// fatal logs msg with err and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, slog.String("error", err.Error()))
+	os.Exit(1)
+}
+
+func startGRPC(addr string, productServer *grpcadapter.ProductServer, opts ...grpc.ServerOption) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		fatal("Failed to listen for gRPC", err)
+	}
+
+	grpcServer = grpcadapter.NewServer(productServer, opts...)
+	go func() {
+		slog.Info("gRPC server listening", slog.String("addr", lis.Addr().String()))
+		if err := grpcServer.Serve(lis); err != nil {
+			slog.Error("gRPC server stopped", slog.String("error", err.Error()))
+		}
+	}()
+}
+
+// stopGRPC waits for in-flight calls, but cuts open watch streams after a
+// short grace period.
+func stopGRPC() {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		grpcServer.Stop()
 	}
-	return c.SendString("Successfully connected to MongoDB")
 }
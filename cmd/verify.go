@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go-hexagon/internal/core/migration"
+	"log/slog"
+	"os"
+	"os/signal"
+)
+
+// runVerify implements `main verify`, which compares the products of two
+// backends, optionally repairs one from the other, and exits with 1 unless
+// they agree in the end.
+func runVerify(args []string) int {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	from := flags.String("from", "", "Backend products were copied from: mysql, postgres or mongodb")
+	to := flags.String("to", "", "Backend products were copied to: mysql, postgres or mongodb")
+	fromDSN := flags.String("from-dsn", "", "DSN of a MySQL or Postgres source (a local database when empty)")
+	toDSN := flags.String("to-dsn", "", "DSN of a MySQL or Postgres target (a local database when empty)")
+	batchSize := flags.Int("batch-size", migration.DefaultBatchSize, "Products read per batch")
+	repair := flags.String("repair", "", "Repair the differences from the source of truth: source (--from) or target (--to); only reports when empty")
+	reportFile := flags.String("report", "verify-report.json", "File the JSON report is written to (- for stdout)")
+	flags.Parse(args)
+	if *from == "" || *to == "" || *from == *to {
+		fmt.Fprintln(os.Stderr, "verify needs two different backends in --from and --to")
+		flags.Usage()
+		return 2
+	}
+	if *repair != "" && *repair != migration.TruthSource && *repair != migration.TruthTarget {
+		fmt.Fprintln(os.Stderr, "--repair must be source or target")
+		flags.Usage()
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// The ID map is kept in the source, see runCopy.
+	source, err := openProductStore(ctx, *from, *fromDSN, false)
+	if err != nil {
+		slog.Error("Failed to open source", slog.String("backend", *from), slog.String("error", err.Error()))
+		return 1
+	}
+	defer source.close()
+	target, err := openProductStore(ctx, *to, *toDSN, false)
+	if err != nil {
+		slog.Error("Failed to open target", slog.String("backend", *to), slog.String("error", err.Error()))
+		return 1
+	}
+	defer target.close()
+
+	verifier := migration.NewVerifier(source.products, target.products, source.ids, migration.VerifyConfig{
+		Source:    *from,
+		Target:    *to,
+		BatchSize: *batchSize,
+		Repair:    *repair,
+	})
+	report, err := verifier.Run(ctx)
+	if err != nil {
+		slog.Error("Verify failed", slog.String("error", err.Error()))
+		return 1
+	}
+	if err := writeReport(*reportFile, report); err != nil {
+		slog.Error("Failed to write report", slog.String("file", *reportFile), slog.String("error", err.Error()))
+		return 1
+	}
+
+	if !report.Consistent {
+		slog.Error("Product stores differ",
+			slog.Int("missing", report.Missing), slog.Int("extra", report.Extra), slog.Int("differing", report.Differing),
+			slog.Int("repaired", report.Repaired), slog.String("report", *reportFile))
+		return 1
+	}
+	return 0
+}
+
+func writeReport(file string, report *migration.Report) error {
+	out := os.Stdout
+	if file != "-" {
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
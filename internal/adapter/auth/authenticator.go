@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-hexagon/internal/core/domain/entity"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrMissingCredentials = errors.New("Missing credentials")
+	ErrInvalidToken       = errors.New("Invalid or expired token")
+	ErrInvalidAPIKey      = errors.New("Invalid API key")
+)
+
+// APIKey is a static credential for service-to-service calls. Only the SHA-256
+// of the key is configured, e.g. `printf %s "$KEY" | sha256sum`.
+type APIKey struct {
+	Name   string   `json:"name"`
+	SHA256 string   `json:"sha256"`
+	Roles  []string `json:"roles"`
+}
+
+// LoadAPIKeys reads a file of the form {"keys": [{"name", "sha256", "roles"}]}.
+func LoadAPIKeys(path string) ([]APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Keys []APIKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse API keys %s: %w", path, err)
+	}
+	return file.Keys, nil
+}
+
+type Config struct {
+	Keys *KeySet
+	// Issuer and Audience are checked when set.
+	Issuer   string
+	Audience string
+	APIKeys  []APIKey
+}
+
+type claims struct {
+	Roles []string `json:"roles"`
+	jwt.RegisteredClaims
+}
+
+// Authenticator turns the credentials of a request into a principal.
+type Authenticator struct {
+	keys    *KeySet
+	parser  *jwt.Parser
+	apiKeys map[[sha256.Size]byte]APIKey
+}
+
+func NewAuthenticator(config Config) (*Authenticator, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "RS256"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+
+	a := &Authenticator{
+		keys:    config.Keys,
+		parser:  jwt.NewParser(options...),
+		apiKeys: make(map[[sha256.Size]byte]APIKey),
+	}
+	if a.keys == nil {
+		a.keys = NewKeySet()
+	}
+	for _, key := range config.APIKeys {
+		sum, err := hex.DecodeString(key.SHA256)
+		if err != nil || len(sum) != sha256.Size {
+			return nil, fmt.Errorf("API key %q: sha256 must be 64 hex characters", key.Name)
+		}
+		a.apiKeys[[sha256.Size]byte(sum)] = key
+	}
+	return a, nil
+}
+
+// Enabled reports whether any credential is configured at all.
+func (a *Authenticator) Enabled() bool {
+	return !a.keys.Empty() || len(a.apiKeys) > 0
+}
+
+// Authenticate checks an Authorization header value ("Bearer <jwt>") or, when
+// it is empty, an API key.
+func (a *Authenticator) Authenticate(authorization, apiKey string) (entity.Principal, error) {
+	if authorization != "" {
+		scheme, token, _ := strings.Cut(authorization, " ")
+		if !strings.EqualFold(scheme, "Bearer") || token == "" {
+			return entity.Principal{}, ErrInvalidToken
+		}
+		return a.verifyToken(token)
+	}
+	if apiKey != "" {
+		return a.verifyAPIKey(apiKey)
+	}
+	return entity.Principal{}, ErrMissingCredentials
+}
+
+func (a *Authenticator) verifyToken(raw string) (entity.Principal, error) {
+	var c claims
+	if _, err := a.parser.ParseWithClaims(raw, &c, a.keys.keyfunc); err != nil {
+		return entity.Principal{}, ErrInvalidToken
+	}
+	if c.Subject == "" {
+		return entity.Principal{}, ErrInvalidToken
+	}
+	return entity.Principal{Subject: c.Subject, Roles: c.Roles, Method: entity.AuthMethodJWT}, nil
+}
+
+func (a *Authenticator) verifyAPIKey(raw string) (entity.Principal, error) {
+	key, ok := a.apiKeys[sha256.Sum256([]byte(raw))]
+	if !ok {
+		return entity.Principal{}, ErrInvalidAPIKey
+	}
+	return entity.Principal{Subject: key.Name, Roles: key.Roles, Method: entity.AuthMethodAPIKey}, nil
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/policy"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const HeaderAPIKey = "X-API-Key"
+
+// Middleware rejects requests without valid credentials with 401 and stores
+// the principal in the request's user context. Paths listed in public are
+// served without authentication.
+func (a *Authenticator) Middleware(public ...string) fiber.Handler {
+	skip := make(map[string]bool, len(public))
+	for _, path := range public {
+		skip[path] = true
+	}
+
+	return func(c *fiber.Ctx) error {
+		if skip[c.Path()] {
+			return c.Next()
+		}
+
+		principal, err := a.Authenticate(c.Get(fiber.HeaderAuthorization), c.Get(HeaderAPIKey))
+		if err != nil {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="go-hexagon"`)
+			return c.Status(fiber.StatusUnauthorized).JSON(rest.ErrorResponse{Error: err.Error()})
+		}
+
+		c.SetUserContext(entity.WithPrincipal(c.UserContext(), principal))
+		return c.Next()
+	}
+}
+
+// Authorize enforces the route rules of the policy with 403. It must run
+// after Middleware, which stores the principal.
+func Authorize(p *policy.Policy) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := p.AuthorizeRoute(c.UserContext(), c.Method(), c.Path()); err != nil {
+			return c.Status(fiber.StatusForbidden).JSON(rest.ErrorResponse{Error: err.Error()})
+		}
+		return c.Next()
+	}
+}
@@ -2,29 +2,34 @@ package database
 
 import (
 	"context"
-	"log"
+	"log/slog"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func ConnectMongoDB() (*mongo.Client, error) {
+// ConnectMongoDB connects to the local server, retrying the first ping as
+// configured; opts are applied on top of the URI, e.g. to size the pool or
+// attach monitors. Failed attempts are logged to logger.
+func ConnectMongoDB(ctx context.Context, logger *slog.Logger, retryConfig RetryConfig, opts ...*options.ClientOptions) (*mongo.Client, error) {
 	uri := "mongodb://localhost:27017"
 	clientOptions := options.Client().ApplyURI(uri)
 
-	client, err := mongo.Connect(context.Background(), clientOptions)
+	// Connect only validates the options; the ping reaches the server.
+	client, err := mongo.Connect(ctx, append([]*options.ClientOptions{clientOptions}, opts...)...)
 	if err != nil {
-		log.Fatal(err)
 		return nil, err
 	}
 
-	err = client.Ping(context.Background(), nil)
+	err = retry(ctx, logger, retryConfig, "mongodb", func(ctx context.Context) error {
+		return client.Ping(ctx, nil)
+	})
 	if err != nil {
-		log.Fatal(err)
+		client.Disconnect(context.Background())
 		return nil, err
 	}
 
-	log.Println("Connected to MongoDB!")
+	logger.InfoContext(ctx, "Connected to MongoDB")
 
 	return client, nil
 }
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SQLPoolConfig sizes the connection pool of a sql.DB. Zero values keep the
+// database/sql defaults (unlimited open connections, 2 idle connections and
+// no maximum lifetime).
+type SQLPoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// DefaultSQLPoolConfig recycles connections before MySQL's default
+// wait_timeout of 8 hours, and well before proxies and load balancers
+// usually drop idle ones.
+func DefaultSQLPoolConfig() SQLPoolConfig {
+	return SQLPoolConfig{
+		MaxOpenConns:    25,
+		MaxIdleConns:    10,
+		ConnMaxLifetime: 30 * time.Minute,
+		ConnMaxIdleTime: 5 * time.Minute,
+	}
+}
+
+func (c SQLPoolConfig) Apply(db *sql.DB) {
+	if c.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(c.MaxOpenConns)
+	}
+	if c.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(c.MaxIdleConns)
+	}
+	if c.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(c.ConnMaxLifetime)
+	}
+	if c.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+	}
+}
+
+// MongoPoolConfig sizes the connection pool of a mongo.Client. Zero values
+// keep the driver defaults.
+type MongoPoolConfig struct {
+	MinPoolSize            uint64
+	MaxPoolSize            uint64
+	MaxConnIdleTime        time.Duration
+	ConnectTimeout         time.Duration
+	ServerSelectionTimeout time.Duration
+}
+
+// DefaultMongoPoolConfig uses the driver's pool size, but selects a server
+// faster than its 30 seconds so requests fail fast while MongoDB is down.
+func DefaultMongoPoolConfig() MongoPoolConfig {
+	return MongoPoolConfig{
+		MaxPoolSize:            100,
+		ConnectTimeout:         10 * time.Second,
+		ServerSelectionTimeout: 5 * time.Second,
+	}
+}
+
+func (c MongoPoolConfig) Options() *options.ClientOptions {
+	opts := options.Client()
+	if c.MinPoolSize > 0 {
+		opts.SetMinPoolSize(c.MinPoolSize)
+	}
+	if c.MaxPoolSize > 0 {
+		opts.SetMaxPoolSize(c.MaxPoolSize)
+	}
+	if c.MaxConnIdleTime > 0 {
+		opts.SetMaxConnIdleTime(c.MaxConnIdleTime)
+	}
+	if c.ConnectTimeout > 0 {
+		opts.SetConnectTimeout(c.ConnectTimeout)
+	}
+	if c.ServerSelectionTimeout > 0 {
+		opts.SetServerSelectionTimeout(c.ServerSelectionTimeout)
+	}
+	return opts
+}
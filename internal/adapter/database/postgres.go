@@ -1,17 +0,0 @@
-package database
-
-import (
-	"log"
-
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
-)
-
-func SetupDatabase() *gorm.DB {
-	dsn := "host=localhost user=postgres dbname=db_store_go port=5432 sslmode=disable password=admin"
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if err != nil {
-		log.Fatal(err)
-	}
-	return db
-}
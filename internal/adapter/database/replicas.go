@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"go-hexagon/internal/core/domain/entity"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReplicaConfig struct {
+	// HealthInterval is how often every replica is pinged.
+	HealthInterval time.Duration
+	HealthTimeout  time.Duration
+}
+
+func DefaultReplicaConfig() ReplicaConfig {
+	return ReplicaConfig{HealthInterval: 5 * time.Second, HealthTimeout: time.Second}
+}
+
+// ReplicaSet routes reads round-robin to the replicas that passed their last
+// health check, and everything else to the primary. Reads fall back to the
+// primary when no replica is healthy or the request has to read its own
+// writes (see entity.WithReadYourWrites).
+type ReplicaSet struct {
+	Primary *gorm.DB
+	Config  ReplicaConfig
+
+	replicas []*replica
+	next     atomic.Uint64
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+type replica struct {
+	name    string
+	db      *gorm.DB
+	healthy atomic.Bool
+}
+
+// NewReplicaSet expects replicas in the order of their DSNs; they are named
+// replica-0, replica-1 and so on in logs. Replicas start out healthy.
+func NewReplicaSet(primary *gorm.DB, replicas []*gorm.DB, config ReplicaConfig) *ReplicaSet {
+	s := &ReplicaSet{Primary: primary, Config: config, done: make(chan struct{})}
+	for i, db := range replicas {
+		r := &replica{name: "replica-" + strconv.Itoa(i), db: db}
+		r.healthy.Store(true)
+		s.replicas = append(s.replicas, r)
+	}
+	return s
+}
+
+// Start checks the replicas now and then every HealthInterval until Stop.
+func (s *ReplicaSet) Start() {
+	if len(s.replicas) == 0 {
+		return
+	}
+	s.checkReplicas()
+	go func() {
+		ticker := time.NewTicker(s.Config.HealthInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+				s.checkReplicas()
+			}
+		}
+	}()
+}
+
+func (s *ReplicaSet) Stop() {
+	s.stopOnce.Do(func() { close(s.done) })
+}
+
+// Reader returns the connection a read in ctx should use.
+func (s *ReplicaSet) Reader(ctx context.Context) *gorm.DB {
+	if len(s.replicas) > 0 && !entity.ReadFromPrimary(ctx) {
+		start := s.next.Add(1)
+		for i := range s.replicas {
+			r := s.replicas[(start+uint64(i))%uint64(len(s.replicas))]
+			if r.healthy.Load() {
+				return r.db.WithContext(ctx)
+			}
+		}
+	}
+	return s.Primary.WithContext(ctx)
+}
+
+// Writer returns the primary and pins the remaining reads of the request to
+// it.
+func (s *ReplicaSet) Writer(ctx context.Context) *gorm.DB {
+	entity.MarkWritten(ctx)
+	return s.Primary.WithContext(ctx)
+}
+
+// Healthy returns the number of replicas reads are currently routed to.
+func (s *ReplicaSet) Healthy() int {
+	healthy := 0
+	for _, r := range s.replicas {
+		if r.healthy.Load() {
+			healthy++
+		}
+	}
+	return healthy
+}
+
+func (s *ReplicaSet) checkReplicas() {
+	var wg sync.WaitGroup
+	for _, r := range s.replicas {
+		wg.Add(1)
+		go func(r *replica) {
+			defer wg.Done()
+			err := s.ping(r)
+			if healthy := err == nil; r.healthy.Swap(healthy) != healthy {
+				if healthy {
+					slog.Info("Replica is healthy again", slog.String("replica", r.name))
+				} else {
+					slog.Warn("Replica failed its health check, reads go elsewhere",
+						slog.String("replica", r.name), slog.String("error", err.Error()))
+				}
+			}
+		}(r)
+	}
+	wg.Wait()
+}
+
+func (s *ReplicaSet) ping(r *replica) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.Config.HealthTimeout)
+	defer cancel()
+	conn, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	return conn.PingContext(ctx)
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// RetryConfig controls how long connecting to a database is retried, e.g.
+// while docker-compose is still starting it.
+type RetryConfig struct {
+	MaxWait     time.Duration
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxWait:     time.Minute,
+		BaseBackoff: 500 * time.Millisecond,
+		MaxBackoff:  10 * time.Second,
+	}
+}
+
+// retry calls connect until it succeeds or MaxWait has passed, doubling the
+// delay between attempts. A MaxWait of 0 tries once.
+func retry(ctx context.Context, logger *slog.Logger, config RetryConfig, name string, connect func(ctx context.Context) error) error {
+	deadline := time.Now().Add(config.MaxWait)
+	for attempt := 1; ; attempt++ {
+		err := connect(ctx)
+		if err == nil {
+			return nil
+		}
+
+		delay := config.backoff(attempt)
+		if remaining := time.Until(deadline); remaining <= 0 {
+			return fmt.Errorf("connect to %s: giving up after %d attempts: %w", name, attempt, err)
+		} else if delay > remaining {
+			delay = remaining
+		}
+		logger.WarnContext(ctx, "Database not reachable, retrying",
+			slog.String("db", name),
+			slog.Int("attempt", attempt),
+			slog.Duration("retry_in", delay),
+			slog.String("error", err.Error()),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("connect to %s: %w", name, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+func (c RetryConfig) backoff(attempt int) time.Duration {
+	delay := c.BaseBackoff << (attempt - 1)
+	if delay <= 0 || delay > c.MaxBackoff {
+		return c.MaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const (
+	BackendMySQL    = "mysql"
+	BackendPostgres = "postgres"
+)
+
+// dialector returns the GORM dialector of backend for dsn. A lazy dialector
+// does not connect before the first statement.
+func dialector(backend, dsn string, lazy bool) (gorm.Dialector, error) {
+	switch backend {
+	case BackendMySQL:
+		// Only the migrations depend on the server version.
+		return mysql.New(mysql.Config{DSN: dsn, SkipInitializeWithVersion: lazy}), nil
+	case BackendPostgres:
+		return postgres.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("unknown SQL backend %q", backend)
+	}
+}
+
+// ConnectSQL opens dsn, retrying until the database accepts connections, and
+// sizes the connection pool. Failed attempts are logged to logger.
+func ConnectSQL(ctx context.Context, logger *slog.Logger, backend, dsn string, config *gorm.Config, pool SQLPoolConfig, retryConfig RetryConfig) (*gorm.DB, error) {
+	if _, err := dialector(backend, dsn, false); err != nil {
+		return nil, err
+	}
+
+	var db *gorm.DB
+	// gorm.Open pings the server, so a database that is not up yet fails here.
+	err := retry(ctx, logger, retryConfig, backend, func(ctx context.Context) error {
+		// A dialector may keep the connection of a failed attempt, so every
+		// attempt gets a new one.
+		open, _ := dialector(backend, dsn, false)
+		var err error
+		db, err = gorm.Open(open, config)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	pool.Apply(sqlDB)
+	return db, nil
+}
+
+// OpenReplica opens dsn without waiting for the server. A replica that is
+// down is skipped by the health checks of ReplicaSet rather than holding up
+// the startup.
+func OpenReplica(backend, dsn string, config *gorm.Config, pool SQLPoolConfig) (*gorm.DB, error) {
+	open, err := dialector(backend, dsn, true)
+	if err != nil {
+		return nil, err
+	}
+	config.DisableAutomaticPing = true
+	db, err := gorm.Open(open, config)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	pool.Apply(sqlDB)
+	return db, nil
+}
@@ -0,0 +1,136 @@
+package eventstream
+
+import (
+	"encoding/json"
+	"go-hexagon/internal/core/domain/entity"
+	"sync"
+	"time"
+)
+
+type Event struct {
+	ID        uint64
+	Type      string
+	ProductID string
+	Data      []byte
+	Change    entity.ProductEvent
+}
+
+type Subscription struct {
+	Events <-chan Event
+	// Backlog holds the buffered events published after the requested
+	// Last-Event-ID. Gap is set when some of them were already evicted.
+	Backlog []Event
+	Gap     bool
+	// LastID is the ID of the last event published before the subscription,
+	// which is where a client that reloads after a gap resumes.
+	LastID uint64
+
+	events chan Event
+}
+
+// Broker fans product events out to stream subscribers and keeps the last
+// Capacity events so that reconnecting clients can resume.
+type Broker struct {
+	Capacity int
+
+	mu          sync.Mutex
+	buffer      []Event
+	lastID      uint64
+	subscribers map[*Subscription]struct{}
+}
+
+func NewBroker(capacity int) *Broker {
+	return &Broker{
+		Capacity:    capacity,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+func (b *Broker) Publish(event entity.ProductEvent) {
+	data, err := json.Marshal(newPayload(event))
+	if err != nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	e := Event{ID: b.lastID, Type: event.Type, ProductID: event.Product.ID, Data: data, Change: event}
+
+	b.buffer = append(b.buffer, e)
+	if len(b.buffer) > b.Capacity {
+		b.buffer = b.buffer[len(b.buffer)-b.Capacity:]
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.events <- e:
+		default:
+			// A subscriber that cannot keep up is disconnected; it resumes
+			// from the buffer with Last-Event-ID.
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// Subscribe registers a subscriber. With a non-zero lastEventID the buffered
+// events after it are returned as backlog.
+func (b *Broker) Subscribe(lastEventID uint64) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := make(chan Event, 64)
+	sub := &Subscription{Events: events, LastID: b.lastID, events: events}
+
+	if lastEventID > b.lastID {
+		// The client saw IDs from before a restart.
+		sub.Gap = true
+	} else if lastEventID > 0 && lastEventID < b.lastID {
+		for _, e := range b.buffer {
+			if e.ID > lastEventID {
+				sub.Backlog = append(sub.Backlog, e)
+			}
+		}
+		sub.Gap = len(b.buffer) == 0 || b.buffer[0].ID > lastEventID+1
+	}
+
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+type productPayload struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Stock int    `json:"stock"`
+}
+
+type payload struct {
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Product    productPayload  `json:"product"`
+	Previous   *productPayload `json:"previous,omitempty"`
+}
+
+func newPayload(event entity.ProductEvent) payload {
+	p := payload{
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Product:    productPayload{ID: event.Product.ID, Name: event.Product.Name, Stock: event.Product.Stock},
+	}
+	if event.Previous != nil {
+		p.Previous = &productPayload{ID: event.Previous.ID, Name: event.Previous.Name, Stock: event.Previous.Stock}
+	}
+	return p
+}
@@ -0,0 +1,102 @@
+package graphql
+
+import (
+	"encoding/json"
+	"go-hexagon/internal/core/service"
+
+	"github.com/gofiber/fiber/v2"
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+type Handler struct {
+	Schema  gql.Schema
+	Service *service.ProductService
+}
+
+func NewHandler(productService *service.ProductService) (*Handler, error) {
+	schema, err := NewSchema(productService)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{Schema: schema, Service: productService}, nil
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Serve executes GraphQL requests sent as GET query parameters, a JSON object
+// or a JSON array of operations (batched in one round trip). GET only runs
+// queries, so that links and prefetches cannot change data.
+func (h *Handler) Serve(c *fiber.Ctx) error {
+	if c.Method() == fiber.MethodGet {
+		req := request{Query: c.Query("query"), OperationName: c.Query("operationName")}
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid variables"})
+			}
+		}
+		if !queryOnly(req) {
+			c.Set(fiber.HeaderAllow, fiber.MethodPost)
+			return c.Status(fiber.StatusMethodNotAllowed).JSON(fiber.Map{"error": "Only queries can be sent with GET, use POST"})
+		}
+		return c.Status(fiber.StatusOK).JSON(h.execute(c, req))
+	}
+
+	body := c.Body()
+	if len(body) > 0 && body[0] == '[' {
+		var batch []request
+		if err := json.Unmarshal(body, &batch); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		results := make([]*gql.Result, len(batch))
+		for i, req := range batch {
+			results[i] = h.execute(c, req)
+		}
+		return c.Status(fiber.StatusOK).JSON(results)
+	}
+
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusOK).JSON(h.execute(c, req))
+}
+
+func (h *Handler) execute(c *fiber.Ctx, req request) *gql.Result {
+	ctx := c.UserContext()
+	return gql.Do(gql.Params{
+		Schema:         h.Schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        withLoader(ctx, newProductLoader(ctx, h.Service)),
+	})
+}
+
+// queryOnly reports whether the operation a request selects is a query. Without
+// an operation name every operation of the document must be one. A document
+// that does not parse is left to execution, which reports the syntax error.
+func queryOnly(req request) bool {
+	document, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return true
+	}
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if req.OperationName != "" && (operation.Name == nil || operation.Name.Value != req.OperationName) {
+			continue
+		}
+		if operation.Operation != ast.OperationTypeQuery {
+			return false
+		}
+	}
+	return true
+}
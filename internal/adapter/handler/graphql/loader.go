@@ -0,0 +1,89 @@
+package graphql
+
+import (
+	"context"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+	"slices"
+	"sort"
+	"sync"
+)
+
+type loaderKey struct{}
+
+// productLoader batches the product(id:) lookups of one request into a single
+// GetProductsByIDs call. Resolvers register their key and return a thunk; the
+// executor runs the thunks after the whole level has been resolved.
+type productLoader struct {
+	ctx     context.Context
+	service *service.ProductService
+
+	mu      sync.Mutex
+	pending []string
+	loaded  map[string]*entity.Product
+	failed  map[string]error
+}
+
+func newProductLoader(ctx context.Context, service *service.ProductService) *productLoader {
+	return &productLoader{
+		ctx:     ctx,
+		service: service,
+		loaded:  make(map[string]*entity.Product),
+		failed:  make(map[string]error),
+	}
+}
+
+func withLoader(ctx context.Context, loader *productLoader) context.Context {
+	return context.WithValue(ctx, loaderKey{}, loader)
+}
+
+func loaderFrom(ctx context.Context) *productLoader {
+	return ctx.Value(loaderKey{}).(*productLoader)
+}
+
+func (l *productLoader) Load(key string) func() (interface{}, error) {
+	l.mu.Lock()
+	if _, ok := l.loaded[key]; !ok && !slices.Contains(l.pending, key) {
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.flush()
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if err := l.failed[key]; err != nil {
+			return nil, err
+		}
+		if product := l.loaded[key]; product != nil {
+			return product, nil
+		}
+		return nil, nil
+	}
+}
+
+func (l *productLoader) flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.pending) == 0 {
+		return
+	}
+	keys := l.pending
+	l.pending = nil
+	// Fields resolve in no particular order; sort so a request always makes
+	// the same call.
+	sort.Strings(keys)
+
+	products, err := l.service.GetProductsByIDs(l.ctx, keys)
+	for _, key := range keys {
+		l.loaded[key] = nil
+		if err != nil {
+			l.failed[key] = err
+		}
+	}
+	for i := range products {
+		l.loaded[products[i].ID] = &products[i]
+	}
+}
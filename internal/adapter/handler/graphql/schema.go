@@ -0,0 +1,265 @@
+package graphql
+
+import (
+	"errors"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+
+	gql "github.com/graphql-go/graphql"
+)
+
+type resolverError struct {
+	err  error
+	code string
+}
+
+func (e resolverError) Error() string {
+	return e.err.Error()
+}
+
+func (e resolverError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+func toGraphQLError(err error) error {
+	switch {
+	case errors.Is(err, entity.ErrProductNotFound):
+		return resolverError{err: err, code: "NOT_FOUND"}
+	case errors.Is(err, entity.ErrForbidden):
+		return resolverError{err: err, code: "FORBIDDEN"}
+	case errors.Is(err, entity.ErrInvalidProduct), errors.Is(err, entity.ErrInvalidID), errors.Is(err, entity.ErrNegativeStock):
+		return resolverError{err: err, code: "BAD_USER_INPUT"}
+	default:
+		return resolverError{err: err, code: "INTERNAL"}
+	}
+}
+
+func NewSchema(productService *service.ProductService) (gql.Schema, error) {
+	productType := gql.NewObject(gql.ObjectConfig{
+		Name: "Product",
+		Fields: gql.Fields{
+			"id": &gql.Field{
+				Type: gql.NewNonNull(gql.ID),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return p.Source.(*entity.Product).ID, nil
+				},
+			},
+			"name": &gql.Field{
+				Type: gql.NewNonNull(gql.String),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return p.Source.(*entity.Product).Name, nil
+				},
+			},
+			"stock": &gql.Field{
+				Type: gql.NewNonNull(gql.Int),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return p.Source.(*entity.Product).Stock, nil
+				},
+			},
+		},
+	})
+
+	productPageType := gql.NewObject(gql.ObjectConfig{
+		Name: "ProductPage",
+		Fields: gql.Fields{
+			"items": &gql.Field{
+				Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(productType))),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					page := p.Source.(*entity.ProductPage)
+					items := make([]*entity.Product, len(page.Products))
+					for i := range page.Products {
+						items[i] = &page.Products[i]
+					}
+					return items, nil
+				},
+			},
+			"total": &gql.Field{
+				Type: gql.NewNonNull(gql.Int),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return int(p.Source.(*entity.ProductPage).Total), nil
+				},
+			},
+			"offset": &gql.Field{
+				Type: gql.NewNonNull(gql.Int),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return p.Source.(*entity.ProductPage).Offset, nil
+				},
+			},
+			"limit": &gql.Field{
+				Type: gql.NewNonNull(gql.Int),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return p.Source.(*entity.ProductPage).Limit, nil
+				},
+			},
+			"hasMore": &gql.Field{
+				Type: gql.NewNonNull(gql.Boolean),
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					return p.Source.(*entity.ProductPage).HasMore(), nil
+				},
+			},
+		},
+	})
+
+	productFilterType := gql.NewInputObject(gql.InputObjectConfig{
+		Name: "ProductFilter",
+		Fields: gql.InputObjectConfigFieldMap{
+			"nameContains": &gql.InputObjectFieldConfig{Type: gql.String},
+			"minStock":     &gql.InputObjectFieldConfig{Type: gql.Int},
+			"maxStock":     &gql.InputObjectFieldConfig{Type: gql.Int},
+		},
+	})
+
+	query := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"product": &gql.Field{
+				Type: productType,
+				Args: gql.FieldConfigArgument{
+					"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+				},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					load := loaderFrom(p.Context).Load(p.Args["id"].(string))
+					return func() (interface{}, error) {
+						product, err := load()
+						if err != nil {
+							return nil, toGraphQLError(err)
+						}
+						return product, nil
+					}, nil
+				},
+			},
+			"products": &gql.Field{
+				Type: gql.NewNonNull(gql.NewList(productType)),
+				Args: gql.FieldConfigArgument{
+					"ids": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(gql.ID)))},
+				},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					rawIDs := p.Args["ids"].([]interface{})
+					ids := make([]string, len(rawIDs))
+					for i, raw := range rawIDs {
+						ids[i] = raw.(string)
+					}
+
+					products, err := productService.GetProductsByIDs(p.Context, ids)
+					if err != nil {
+						return nil, toGraphQLError(err)
+					}
+					byKey := make(map[string]*entity.Product, len(products))
+					for i := range products {
+						byKey[products[i].ID] = &products[i]
+					}
+					// Keep the requested order, with null for unknown IDs.
+					result := make([]interface{}, len(ids))
+					for i, id := range ids {
+						if product, ok := byKey[id]; ok {
+							result[i] = product
+						}
+					}
+					return result, nil
+				},
+			},
+			"productList": &gql.Field{
+				Type: gql.NewNonNull(productPageType),
+				Args: gql.FieldConfigArgument{
+					"filter": &gql.ArgumentConfig{Type: productFilterType},
+					"offset": &gql.ArgumentConfig{Type: gql.Int, DefaultValue: 0},
+					"limit":  &gql.ArgumentConfig{Type: gql.Int, DefaultValue: service.DefaultPageSize},
+				},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					query := entity.ProductQuery{
+						Offset: p.Args["offset"].(int),
+						Limit:  p.Args["limit"].(int),
+					}
+					if filter, ok := p.Args["filter"].(map[string]interface{}); ok {
+						if name, ok := filter["nameContains"].(string); ok {
+							query.NameContains = name
+						}
+						if minStock, ok := filter["minStock"].(int); ok {
+							query.MinStock = &minStock
+						}
+						if maxStock, ok := filter["maxStock"].(int); ok {
+							query.MaxStock = &maxStock
+						}
+					}
+
+					page, err := productService.ListProductsPage(p.Context, query)
+					if err != nil {
+						return nil, toGraphQLError(err)
+					}
+					return page, nil
+				},
+			},
+		},
+	})
+
+	mutation := gql.NewObject(gql.ObjectConfig{
+		Name: "Mutation",
+		Fields: gql.Fields{
+			"createProduct": &gql.Field{
+				Type: gql.NewNonNull(productType),
+				Args: gql.FieldConfigArgument{
+					"name":  &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+					"stock": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.Int)},
+				},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					product := &entity.Product{Name: p.Args["name"].(string), Stock: p.Args["stock"].(int)}
+					if err := productService.CreateProduct(p.Context, product); err != nil {
+						return nil, toGraphQLError(err)
+					}
+					return product, nil
+				},
+			},
+			"updateProduct": &gql.Field{
+				Type: gql.NewNonNull(productType),
+				Args: gql.FieldConfigArgument{
+					"id":    &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+					"name":  &gql.ArgumentConfig{Type: gql.String},
+					"stock": &gql.ArgumentConfig{Type: gql.Int},
+				},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					var changes service.ProductChanges
+					if name, ok := p.Args["name"].(string); ok {
+						changes.Name = &name
+					}
+					if stock, ok := p.Args["stock"].(int); ok {
+						changes.Stock = &stock
+					}
+
+					product, err := productService.PatchProduct(p.Context, p.Args["id"].(string), changes)
+					if err != nil {
+						return nil, toGraphQLError(err)
+					}
+					return product, nil
+				},
+			},
+			"adjustStock": &gql.Field{
+				Type: gql.NewNonNull(productType),
+				Args: gql.FieldConfigArgument{
+					"id":    &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+					"delta": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.Int)},
+				},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					product, err := productService.AdjustStock(p.Context, p.Args["id"].(string), p.Args["delta"].(int))
+					if err != nil {
+						return nil, toGraphQLError(err)
+					}
+					return product, nil
+				},
+			},
+			"deleteProduct": &gql.Field{
+				Type: gql.NewNonNull(gql.ID),
+				Args: gql.FieldConfigArgument{
+					"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+				},
+				Resolve: func(p gql.ResolveParams) (interface{}, error) {
+					if err := productService.DeleteProduct(p.Context, p.Args["id"].(string)); err != nil {
+						return nil, toGraphQLError(err)
+					}
+					return p.Args["id"], nil
+				},
+			},
+		},
+	})
+
+	return gql.NewSchema(gql.SchemaConfig{Query: query, Mutation: mutation})
+}
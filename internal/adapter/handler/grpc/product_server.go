@@ -0,0 +1,186 @@
+package grpc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"go-hexagon/internal/adapter/eventstream"
+	"go-hexagon/internal/adapter/handler/grpc/productpb"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/policy"
+	"go-hexagon/internal/core/service"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type ProductServer struct {
+	productpb.UnimplementedProductServiceServer
+
+	Service *service.ProductService
+	Broker  *eventstream.Broker
+}
+
+func NewProductServer(service *service.ProductService, broker *eventstream.Broker) *ProductServer {
+	return &ProductServer{Service: service, Broker: broker}
+}
+
+func (s *ProductServer) CreateProduct(ctx context.Context, req *productpb.CreateProductRequest) (*productpb.Product, error) {
+	product := &entity.Product{Name: req.GetName(), Stock: int(req.GetStock())}
+	if err := s.Service.CreateProduct(ctx, product); err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(product), nil
+}
+
+func (s *ProductServer) GetProduct(ctx context.Context, req *productpb.GetProductRequest) (*productpb.Product, error) {
+	product, err := s.Service.GetProductByID(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(product), nil
+}
+
+func (s *ProductServer) ListProducts(ctx context.Context, req *productpb.ListProductsRequest) (*productpb.ListProductsResponse, error) {
+	offset, err := decodePageToken(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid page token")
+	}
+
+	page, err := s.Service.ListProductsPage(ctx, entity.ProductQuery{Offset: offset, Limit: int(req.GetPageSize())})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &productpb.ListProductsResponse{TotalSize: page.Total}
+	for i := range page.Products {
+		resp.Products = append(resp.Products, toProto(&page.Products[i]))
+	}
+	if page.HasMore() {
+		resp.NextPageToken = encodePageToken(page.Offset + len(page.Products))
+	}
+	return resp, nil
+}
+
+func (s *ProductServer) UpdateProduct(ctx context.Context, req *productpb.UpdateProductRequest) (*productpb.Product, error) {
+	var changes service.ProductChanges
+	if req.Name != nil {
+		changes.Name = req.Name
+	}
+	if req.Stock != nil {
+		stock := int(req.GetStock())
+		changes.Stock = &stock
+	}
+
+	product, err := s.Service.PatchProduct(ctx, req.GetId(), changes)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(product), nil
+}
+
+func (s *ProductServer) DeleteProduct(ctx context.Context, req *productpb.DeleteProductRequest) (*productpb.DeleteProductResponse, error) {
+	if err := s.Service.DeleteProduct(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &productpb.DeleteProductResponse{}, nil
+}
+
+func (s *ProductServer) WatchProducts(req *productpb.WatchProductsRequest, stream productpb.ProductService_WatchProductsServer) error {
+	if err := s.Service.Authorize(stream.Context(), policy.ActionList); err != nil {
+		return toStatus(err)
+	}
+
+	wanted := map[string]bool{}
+	for _, id := range req.GetProductIds() {
+		wanted[id] = true
+	}
+
+	sub := s.Broker.Subscribe(req.GetLastEventId())
+	defer s.Broker.Unsubscribe(sub)
+
+	if sub.Gap {
+		return status.Error(codes.OutOfRange, "last_event_id is no longer buffered, reload the product list")
+	}
+
+	send := func(e eventstream.Event) error {
+		if len(wanted) > 0 && !wanted[e.ProductID] {
+			return nil
+		}
+		return stream.Send(toProtoEvent(e))
+	}
+
+	for _, e := range sub.Backlog {
+		if err := send(e); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e, ok := <-sub.Events:
+			if !ok {
+				return status.Error(codes.Unavailable, "watcher fell behind, resume with last_event_id")
+			}
+			if err := send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, entity.ErrProductNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, entity.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, entity.ErrInvalidProduct), errors.Is(err, entity.ErrInvalidID), errors.Is(err, entity.ErrNegativeStock):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func toProto(product *entity.Product) *productpb.Product {
+	return &productpb.Product{
+		Id:    product.ID,
+		Name:  product.Name,
+		Stock: int32(product.Stock),
+	}
+}
+
+func toProtoEvent(e eventstream.Event) *productpb.ProductEvent {
+	event := &productpb.ProductEvent{
+		Id:         e.ID,
+		Type:       e.Type,
+		Product:    toProto(&e.Change.Product),
+		OccurredAt: timestamppb.New(e.Change.OccurredAt),
+	}
+	if e.Change.Previous != nil {
+		event.Previous = toProto(e.Change.Previous)
+	}
+	return event
+}
+
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodePageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, errors.New("invalid page token")
+	}
+	return offset, nil
+}
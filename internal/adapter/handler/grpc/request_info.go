@@ -0,0 +1,44 @@
+package grpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"go-hexagon/internal/core/domain/entity"
+	"net"
+
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const requestIDKey = "x-request-id"
+
+// requestInfo stores the request ID and the client IP in the context of
+// unary calls, like the REST RequestInfo middleware. The ID comes from the
+// x-request-id metadata when present and is sent back as a response header.
+func requestInfo(ctx context.Context, req interface{}, info *grpcgo.UnaryServerInfo, handler grpcgo.UnaryHandler) (interface{}, error) {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDKey); len(values) > 0 && len(values[0]) <= 128 {
+			id = values[0]
+		}
+	}
+	if id == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			panic(err)
+		}
+		id = hex.EncodeToString(b)
+	}
+	grpcgo.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+
+	var clientIP string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		clientIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(clientIP); err == nil {
+			clientIP = host
+		}
+	}
+	return handler(entity.WithRequestInfo(ctx, entity.RequestInfo{ID: id, ClientIP: clientIP}), req)
+}
@@ -0,0 +1,20 @@
+package grpc
+
+//go:generate buf generate
+
+import (
+	"go-hexagon/internal/adapter/handler/grpc/productpb"
+
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// NewServer registers the product service and reflection. The request info
+// interceptor runs before any interceptor passed in opts.
+func NewServer(productServer *ProductServer, opts ...grpcgo.ServerOption) *grpcgo.Server {
+	opts = append([]grpcgo.ServerOption{grpcgo.ChainUnaryInterceptor(requestInfo)}, opts...)
+	server := grpcgo.NewServer(opts...)
+	productpb.RegisterProductServiceServer(server, productServer)
+	reflection.Register(server)
+	return server
+}
@@ -0,0 +1,39 @@
+package rest
+
+import (
+	"errors"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditHandler struct {
+	Service *service.AuditService
+}
+
+func NewAuditHandler(service *service.AuditService) *AuditHandler {
+	return &AuditHandler{Service: service}
+}
+
+// ListAudit serves GET /audit?entity=product&id=&limit=, newest first.
+func (h *AuditHandler) ListAudit(c *fiber.Ctx) error {
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
+	}
+
+	query := entity.AuditQuery{Entity: c.Query("entity"), EntityID: c.Query("id"), Limit: limit}
+	records, err := h.Service.ListRecords(c.UserContext(), query)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidAuditQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
+		}
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResponse(err.Error()))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(newAuditRecordResponses(records))
+}
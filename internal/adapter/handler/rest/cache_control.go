@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CacheControlConfig maps GET routes to the Cache-Control header of their
+// successful responses. Routes are written as registered in Fiber, with *
+// matching any single segment, e.g. /api/*/products/:id.
+type CacheControlConfig struct {
+	// Default applies to GET routes without an entry; empty sends no header.
+	Default string            `json:"default"`
+	Routes  map[string]string `json:"routes"`
+}
+
+// DefaultCacheControlConfig makes caches revalidate products on every use.
+// Responses depend on the caller's credentials, so they are private.
+func DefaultCacheControlConfig() CacheControlConfig {
+	return CacheControlConfig{
+		Routes: map[string]string{
+			"/api/*/products":     "private, no-cache",
+			"/api/*/products/:id": "private, no-cache",
+		},
+	}
+}
+
+// LoadCacheControlConfig reads a JSON file of the same shape as
+// CacheControlConfig on top of the defaults.
+func LoadCacheControlConfig(path string) (CacheControlConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return CacheControlConfig{}, err
+	}
+	var file CacheControlConfig
+	if err := json.Unmarshal(data, &file); err != nil {
+		return CacheControlConfig{}, fmt.Errorf("parse cache control %s: %w", path, err)
+	}
+
+	config := DefaultCacheControlConfig()
+	config.Default = file.Default
+	for route, value := range file.Routes {
+		config.Routes[route] = value
+	}
+	return config, nil
+}
+
+// CacheControl sets the configured Cache-Control header on 200 and 304
+// responses to GET requests, unless the handler already set one.
+func CacheControl(config CacheControlConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return nil
+		}
+		if status := c.Response().StatusCode(); status != fiber.StatusOK && status != fiber.StatusNotModified {
+			return nil
+		}
+		if len(c.Response().Header.Peek(fiber.HeaderCacheControl)) > 0 {
+			return nil
+		}
+		if value := config.lookup(c.Route().Path); value != "" {
+			c.Set(fiber.HeaderCacheControl, value)
+		}
+		return nil
+	}
+}
+
+// lookup returns the value of the most specific matching route, the one
+// with the fewest wildcards.
+func (config CacheControlConfig) lookup(route string) string {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	value, best := config.Default, -1
+	for pattern, candidate := range config.Routes {
+		wildcards, ok := routeMatches(strings.Split(strings.Trim(pattern, "/"), "/"), segments)
+		if ok && (best < 0 || wildcards < best) {
+			value, best = candidate, wildcards
+		}
+	}
+	return value
+}
+
+func routeMatches(pattern, segments []string) (wildcards int, ok bool) {
+	if len(pattern) != len(segments) {
+		return 0, false
+	}
+	for i, part := range pattern {
+		if part == "*" {
+			wildcards++
+		} else if part != segments[i] {
+			return 0, false
+		}
+	}
+	return wildcards, true
+}
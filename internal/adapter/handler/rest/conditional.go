@@ -0,0 +1,99 @@
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"go-hexagon/internal/core/domain/entity"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// conditionalProduct sets the ETag and Last-Modified headers of a single
+// product and reports whether If-None-Match, or else If-Modified-Since,
+// shows that the client already has it.
+func conditionalProduct(c *fiber.Ctx, product entity.Product) bool {
+	return conditional(c, []entity.Product{product}, true)
+}
+
+// conditionalList does the same for a list of products; extra holds
+// whatever else the response depends on, like the page bounds and total.
+// Deleting a product does not move the newest update time, so lists are
+// only revalidated with their ETag.
+func conditionalList(c *fiber.Ctx, products []entity.Product, extra ...int64) bool {
+	return conditional(c, products, false, extra...)
+}
+
+func conditional(c *fiber.Ctx, products []entity.Product, useModifiedSince bool, extra ...int64) bool {
+	etag := productETag(products, extra)
+	c.Set(fiber.HeaderETag, etag)
+	lastModified, ok := productsLastModified(products)
+	if ok {
+		c.Set(fiber.HeaderLastModified, lastModified.Format(http.TimeFormat))
+	}
+
+	if noneMatch := c.Get(fiber.HeaderIfNoneMatch); noneMatch != "" {
+		return etagMatches(noneMatch, etag)
+	}
+	if !useModifiedSince || !ok {
+		return false
+	}
+	since, err := http.ParseTime(c.Get(fiber.HeaderIfModifiedSince))
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// productETag hashes the fields and update time of every product. The tag is
+// weak since it is not computed from the response bytes.
+func productETag(products []entity.Product, extra []int64) string {
+	hash := sha256.New()
+	var number [8]byte
+	writeNumber := func(value int64) {
+		binary.BigEndian.PutUint64(number[:], uint64(value))
+		hash.Write(number[:])
+	}
+	for _, product := range products {
+		hash.Write([]byte(product.ID))
+		hash.Write([]byte{0})
+		hash.Write([]byte(product.Name))
+		hash.Write([]byte{0})
+		writeNumber(int64(product.Stock))
+		writeNumber(product.UpdatedAt.UnixNano())
+	}
+	for _, value := range extra {
+		writeNumber(value)
+	}
+	return `W/"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// productsLastModified returns the newest update time, or false when a
+// product predates update times being recorded.
+func productsLastModified(products []entity.Product) (time.Time, bool) {
+	var newest time.Time
+	for _, product := range products {
+		if product.UpdatedAt.IsZero() {
+			return time.Time{}, false
+		}
+		if product.UpdatedAt.After(newest) {
+			newest = product.UpdatedAt
+		}
+	}
+	return newest.UTC(), !newest.IsZero()
+}
+
+// etagMatches compares an If-None-Match header with etag using the weak
+// comparison GET requires.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,299 @@
+package rest
+
+import (
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+	"strconv"
+	"time"
+)
+
+type ProductRequest struct {
+	Name  string `json:"name" openapi:"minLength=1,maxLength=255"`
+	Stock int    `json:"stock" openapi:"minimum=1"`
+}
+
+func (r ProductRequest) toEntity() *entity.Product {
+	return &entity.Product{Name: r.Name, Stock: r.Stock}
+}
+
+// ProductUpdateRequest keeps the semantics of a PUT body: an empty name and a
+// zero stock mean "leave unchanged".
+type ProductUpdateRequest struct {
+	Name  string `json:"name,omitempty" openapi:"maxLength=255"`
+	Stock int    `json:"stock,omitempty" openapi:"minimum=0"`
+}
+
+func (r ProductUpdateRequest) changes() service.ProductChanges {
+	var changes service.ProductChanges
+	if r.Name != "" {
+		changes.Name = &r.Name
+	}
+	if r.Stock != 0 {
+		changes.Stock = &r.Stock
+	}
+	return changes
+}
+
+// ProductResponse is the JSON representation of a product. The MySQL routes
+// return a numeric id and the MongoDB routes an ObjectID hex string, as they
+// always have.
+type ProductResponse struct {
+	ID    interface{} `json:"id" openapi:"oneOf=integer|string,description=Numeric for MySQL and ObjectID hex for MongoDB"`
+	Name  string      `json:"name"`
+	Stock int         `json:"stock"`
+}
+
+func newMySQLProductResponse(product entity.Product) ProductResponse {
+	id, _ := strconv.ParseUint(product.ID, 10, 64)
+	return ProductResponse{ID: id, Name: product.Name, Stock: product.Stock}
+}
+
+func newMongoProductResponse(product entity.Product) ProductResponse {
+	return ProductResponse{ID: product.ID, Name: product.Name, Stock: product.Stock}
+}
+
+func newProductResponses(products []entity.Product, convert func(entity.Product) ProductResponse) []ProductResponse {
+	responses := make([]ProductResponse, 0, len(products))
+	for _, product := range products {
+		responses = append(responses, convert(product))
+	}
+	return responses
+}
+
+// ProductPageResponse is the v2 list envelope.
+type ProductPageResponse struct {
+	Items  []ProductResponse `json:"items"`
+	Total  int64             `json:"total"`
+	Offset int               `json:"offset"`
+	Limit  int               `json:"limit"`
+}
+
+func newProductPageResponse(page *entity.ProductPage, convert func(entity.Product) ProductResponse) ProductPageResponse {
+	return ProductPageResponse{
+		Items:  newProductResponses(page.Products, convert),
+		Total:  page.Total,
+		Offset: page.Offset,
+		Limit:  page.Limit,
+	}
+}
+
+// ProductHitResponse is a product found by a search.
+type ProductHitResponse struct {
+	ID    interface{} `json:"id" openapi:"oneOf=integer|string,description=Numeric for MySQL and ObjectID hex for MongoDB"`
+	Name  string      `json:"name"`
+	Stock int         `json:"stock"`
+	Score float64     `json:"score" openapi:"description=Relevance computed by the database; only comparable within one search"`
+}
+
+// ProductSearchResponse is a page of search hits, most relevant first.
+type ProductSearchResponse struct {
+	Items  []ProductHitResponse `json:"items"`
+	Total  int64                `json:"total"`
+	Offset int                  `json:"offset"`
+	Limit  int                  `json:"limit"`
+}
+
+func newProductSearchResponse(result *entity.ProductSearchResult, convert func(entity.Product) ProductResponse) ProductSearchResponse {
+	items := make([]ProductHitResponse, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		product := convert(hit.Product)
+		items = append(items, ProductHitResponse{ID: product.ID, Name: product.Name, Stock: product.Stock, Score: hit.Score})
+	}
+	return ProductSearchResponse{Items: items, Total: result.Total, Offset: result.Offset, Limit: result.Limit}
+}
+
+type WebhookRequest struct {
+	URL        string   `json:"url" openapi:"format=uri"`
+	Events     []string `json:"events,omitempty" openapi:"enum=*|product.created|product.updated|product.deleted|product.stock_changed"`
+	ProductIDs []string `json:"product_ids,omitempty"`
+	Secret     string   `json:"secret,omitempty"`
+}
+
+func (r WebhookRequest) toEntity() *entity.Webhook {
+	return &entity.Webhook{
+		URL:        r.URL,
+		Events:     r.Events,
+		ProductIDs: r.ProductIDs,
+		Secret:     r.Secret,
+		Active:     true,
+	}
+}
+
+// WebhookUpdateRequest only changes the fields that are present in the body.
+type WebhookUpdateRequest struct {
+	URL        *string   `json:"url,omitempty" openapi:"format=uri"`
+	Events     *[]string `json:"events,omitempty" openapi:"enum=*|product.created|product.updated|product.deleted|product.stock_changed"`
+	ProductIDs *[]string `json:"product_ids,omitempty"`
+	Secret     *string   `json:"secret,omitempty"`
+	Active     *bool     `json:"active,omitempty"`
+}
+
+func (r WebhookUpdateRequest) apply(webhook *entity.Webhook) {
+	if r.URL != nil {
+		webhook.URL = *r.URL
+	}
+	if r.Events != nil {
+		webhook.Events = *r.Events
+	}
+	if r.ProductIDs != nil {
+		webhook.ProductIDs = *r.ProductIDs
+	}
+	if r.Secret != nil && *r.Secret != "" {
+		webhook.Secret = *r.Secret
+	}
+	if r.Active != nil {
+		webhook.Active = *r.Active
+	}
+}
+
+type WebhookResponse struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	Events     []string  `json:"events"`
+	ProductIDs []string  `json:"product_ids,omitempty"`
+	Secret     string    `json:"secret,omitempty" openapi:"description=Only returned when the webhook is created"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// newWebhookResponse leaves the secret out unless withSecret is set; it is
+// only shown once, when the subscription is created.
+func newWebhookResponse(webhook entity.Webhook, withSecret bool) WebhookResponse {
+	response := WebhookResponse{
+		ID:         webhook.ID,
+		URL:        webhook.URL,
+		Events:     webhook.Events,
+		ProductIDs: webhook.ProductIDs,
+		Active:     webhook.Active,
+		CreatedAt:  webhook.CreatedAt,
+		UpdatedAt:  webhook.UpdatedAt,
+	}
+	if withSecret {
+		response.Secret = webhook.Secret
+	}
+	return response
+}
+
+type WebhookDeliveryResponse struct {
+	ID            string     `json:"id"`
+	WebhookID     string     `json:"webhook_id"`
+	Event         string     `json:"event"`
+	Payload       string     `json:"payload"`
+	Status        string     `json:"status" openapi:"enum=pending|succeeded|dead"`
+	Attempts      int        `json:"attempts"`
+	StatusCode    int        `json:"status_code,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+}
+
+func newWebhookDeliveryResponse(delivery entity.WebhookDelivery) WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{
+		ID:            delivery.ID,
+		WebhookID:     delivery.WebhookID,
+		Event:         delivery.Event,
+		Payload:       delivery.Payload,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		StatusCode:    delivery.StatusCode,
+		LastError:     delivery.LastError,
+		NextAttemptAt: delivery.NextAttemptAt,
+		CreatedAt:     delivery.CreatedAt,
+		DeliveredAt:   delivery.DeliveredAt,
+	}
+}
+
+func newWebhookDeliveryResponses(deliveries []entity.WebhookDelivery) []WebhookDeliveryResponse {
+	responses := make([]WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		responses = append(responses, newWebhookDeliveryResponse(delivery))
+	}
+	return responses
+}
+
+// AuditRecordResponse is one entry of the audit log. Product IDs are given as
+// strings whatever the backend.
+type AuditRecordResponse struct {
+	ID         string                `json:"id"`
+	Entity     string                `json:"entity" openapi:"enum=product"`
+	EntityID   string                `json:"entity_id"`
+	Action     string                `json:"action" openapi:"enum=create|update|delete"`
+	Principal  AuditPrincipal        `json:"principal"`
+	Before     *AuditProductState    `json:"before,omitempty"`
+	After      *AuditProductState    `json:"after,omitempty"`
+	Changes    []AuditChangeResponse `json:"changes"`
+	RequestID  string                `json:"request_id,omitempty"`
+	ClientIP   string                `json:"client_ip,omitempty"`
+	OccurredAt time.Time             `json:"occurred_at"`
+}
+
+type AuditPrincipal struct {
+	Subject string   `json:"subject,omitempty" openapi:"description=Empty when authentication is disabled"`
+	Roles   []string `json:"roles,omitempty"`
+	Method  string   `json:"method,omitempty" openapi:"enum=jwt|api_key"`
+}
+
+type AuditProductState struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Stock int    `json:"stock"`
+}
+
+func newAuditProductState(product *entity.Product) *AuditProductState {
+	if product == nil {
+		return nil
+	}
+	return &AuditProductState{ID: product.ID, Name: product.Name, Stock: product.Stock}
+}
+
+type AuditChangeResponse struct {
+	Field  string      `json:"field" openapi:"enum=name|stock"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+func newAuditRecordResponses(records []entity.AuditRecord) []AuditRecordResponse {
+	responses := make([]AuditRecordResponse, 0, len(records))
+	for _, record := range records {
+		response := AuditRecordResponse{
+			ID:         record.ID,
+			Entity:     record.Entity,
+			EntityID:   record.EntityID,
+			Action:     record.Action,
+			Principal:  AuditPrincipal(record.Principal),
+			Before:     newAuditProductState(record.Before),
+			After:      newAuditProductState(record.After),
+			Changes:    make([]AuditChangeResponse, 0, len(record.Changes)),
+			RequestID:  record.RequestID,
+			ClientIP:   record.ClientIP,
+			OccurredAt: record.OccurredAt,
+		}
+		for _, change := range record.Changes {
+			response.Changes = append(response.Changes, AuditChangeResponse(change))
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+type ErrorResponse struct {
+	Error      string      `json:"error"`
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// Violation describes one way a request failed to match the API document.
+type Violation struct {
+	Location string `json:"location"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+}
+
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+func errorResponse(message string) ErrorResponse {
+	return ErrorResponse{Error: message}
+}
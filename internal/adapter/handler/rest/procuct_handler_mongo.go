@@ -1,13 +1,11 @@
 package rest
 
 import (
+	"errors"
 	"go-hexagon/internal/core/domain/entity"
 	"go-hexagon/internal/core/service"
 
-	"strconv"
-
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type ProductHandlerMongo struct {
@@ -19,135 +17,144 @@ func NewProductHandlerMongo(service *service.ProductService) *ProductHandlerMong
 }
 
 func (h *ProductHandlerMongo) CreateProduct(c *fiber.Ctx) error {
-	product := new(entity.Product)
-	if err := c.BodyParser(product); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	if product.Name == "" || product.Stock == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Name and Stock fields are required"})
+	var input ProductRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
 	}
 
-	if err := h.Service.CreateProduct(product); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	product := input.toEntity()
+	if err := h.Service.CreateProduct(c.UserContext(), product); err != nil {
+		if errors.Is(err, entity.ErrInvalidProduct) {
+			return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
+		}
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResponse(err.Error()))
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"id":    product.MongoID,
-		"name":  product.Name,
-		"stock": product.Stock,
-	})
+	return c.Status(fiber.StatusOK).JSON(newMongoProductResponse(*product))
 }
 
 func (h *ProductHandlerMongo) UpdateProduct(c *fiber.Ctx) error {
-	id := c.Params("id")
-
-	if !primitive.IsValidObjectID(id) {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid product ID"})
+	var input ProductUpdateRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorResponse("Invalid input format"))
 	}
 
-	objectID, err := primitive.ObjectIDFromHex(id)
+	existingProduct, err := h.Service.PatchProduct(c.UserContext(), c.Params("id"), input.changes())
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid product ID"})
+		if errors.Is(err, entity.ErrProductNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResponse("Product not found"))
+		}
+		if errors.Is(err, entity.ErrInvalidID) || errors.Is(err, entity.ErrNegativeStock) {
+			return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
+		}
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResponse(err.Error()))
 	}
 
-	existingProduct, err := h.Service.GetProductByID(objectID)
-	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
-	}
+	return c.Status(fiber.StatusOK).JSON(newMongoProductResponse(*existingProduct))
+}
 
-	var updatedProduct entity.Product
-	if err := c.BodyParser(&updatedProduct); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input format"})
+func (h *ProductHandlerMongo) GetProductByID(c *fiber.Ctx) error {
+	product, err := h.Service.GetProductByID(c.UserContext(), c.Params("id"))
+	if errors.Is(err, entity.ErrProductNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(errorResponse("Product not found"))
 	}
-
-	if updatedProduct.Name != "" {
-		existingProduct.Name = updatedProduct.Name
+	if errors.Is(err, entity.ErrInvalidID) {
+		return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
 	}
-	if updatedProduct.Stock != 0 {
-		existingProduct.Stock = updatedProduct.Stock
+	if err != nil {
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResponse(err.Error()))
 	}
 
-	if err := h.Service.UpdateProduct(existingProduct); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	if conditionalProduct(c, *product) {
+		return c.SendStatus(fiber.StatusNotModified)
 	}
-
-	return c.Status(fiber.StatusOK).JSON(existingProduct)
+	return c.Status(fiber.StatusOK).JSON(newMongoProductResponse(*product))
 }
 
-func (h *ProductHandlerMongo) GetProductByID(c *fiber.Ctx) error {
-	id := c.Params("id")
-
-	var product *entity.Product
-	var err error
-
-	if primitive.IsValidObjectID(id) {
-		objectID, _ := primitive.ObjectIDFromHex(id)
-		product, err = h.Service.GetProductByID(objectID)
-	} else {
-		uintID, err := strconv.ParseUint(id, 10, 32)
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid product ID"})
-		}
-		product, err = h.Service.GetProductByID(uint(uintID)) // Service MySQL
-	}
-
+func (h *ProductHandlerMongo) ListProducts(c *fiber.Ctx) error {
+	products, err := h.Service.ListProducts(c.UserContext())
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResponse(err.Error()))
 	}
 
-	if product == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
+	if conditionalList(c, products) {
+		return c.SendStatus(fiber.StatusNotModified)
 	}
-
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"id":    product.MongoID,
-		"name":  product.Name,
-		"stock": product.Stock,
-	})
+	return c.Status(fiber.StatusOK).JSON(newProductResponses(products, newMongoProductResponse))
 }
 
-func (h *ProductHandlerMongo) ListProducts(c *fiber.Ctx) error {
-	products, err := h.Service.ListProducts()
+// ListProductsPage serves the v2 list: one page of products in an envelope.
+func (h *ProductHandlerMongo) ListProductsPage(c *fiber.Ctx) error {
+	query, err := productQuery(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
 	}
 
-	if len(products) == 0 {
-		return c.Status(fiber.StatusOK).JSON([]fiber.Map{})
+	page, err := h.Service.ListProductsPage(c.UserContext(), query)
+	if err != nil {
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResponse(err.Error()))
 	}
 
-	var productResponses []fiber.Map
-	for _, product := range products {
-		productResponses = append(productResponses, fiber.Map{
-			"id":    product.MongoID,
-			"name":  product.Name,
-			"stock": product.Stock,
-		})
+	if conditionalList(c, page.Products, page.Total, int64(page.Offset), int64(page.Limit)) {
+		return c.SendStatus(fiber.StatusNotModified)
 	}
-
-	return c.Status(fiber.StatusOK).JSON(productResponses)
+	return c.Status(fiber.StatusOK).JSON(newProductPageResponse(page, newMongoProductResponse))
 }
 
-func (h *ProductHandlerMongo) DeleteProduct(c *fiber.Ctx) error {
-	id := c.Params("id")
-
-	if !primitive.IsValidObjectID(id) {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid product ID for MongoDB"})
+// SearchProducts serves a page of the products matching ?q=, most relevant
+// first.
+func (h *ProductHandlerMongo) SearchProducts(c *fiber.Ctx) error {
+	search, err := searchQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
 	}
 
-	objectID, err := primitive.ObjectIDFromHex(id)
+	result, err := h.Service.SearchProducts(c.UserContext(), search)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid product ID"})
+		if errors.Is(err, entity.ErrInvalidSearch) {
+			return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
+		}
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorResponse(err.Error()))
+		}
+		if errors.Is(err, entity.ErrNoSearch) {
+			return c.Status(fiber.StatusNotImplemented).JSON(errorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResponse(err.Error()))
 	}
 
-	err = h.Service.DeleteProduct(objectID)
+	return c.Status(fiber.StatusOK).JSON(newProductSearchResponse(result, newMongoProductResponse))
+}
+
+func (h *ProductHandlerMongo) DeleteProduct(c *fiber.Ctx) error {
+	err := h.Service.DeleteProduct(c.UserContext(), c.Params("id"))
 	if err != nil {
-		if err.Error() == "ID Not Found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ID not found"})
+		if errors.Is(err, entity.ErrProductNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResponse("ID not found"))
+		}
+		if errors.Is(err, entity.ErrInvalidID) {
+			return c.Status(fiber.StatusBadRequest).JSON(errorResponse("Invalid product ID for MongoDB"))
+		}
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorResponse(err.Error()))
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResponse(err.Error()))
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Product deleted successfully"})
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Product deleted successfully"})
 }
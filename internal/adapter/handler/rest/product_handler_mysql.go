@@ -1,9 +1,9 @@
 package rest
 
 import (
+	"errors"
 	"go-hexagon/internal/core/domain/entity"
 	"go-hexagon/internal/core/service"
-	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -17,122 +17,143 @@ func NewProductHandlerMySQL(service *service.ProductService) *ProductHandlerMySQ
 }
 
 func (h *ProductHandlerMySQL) CreateProduct(c *fiber.Ctx) error {
-	product := new(entity.Product)
-	if err := c.BodyParser(product); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	var input ProductRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
 	}
 
-	if product.Name == "" || product.Stock == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Name and Stock fields are required"})
-	}
-
-	if err := h.Service.CreateProduct(product); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	product := input.toEntity()
+	if err := h.Service.CreateProduct(c.UserContext(), product); err != nil {
+		if errors.Is(err, entity.ErrInvalidProduct) {
+			return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
+		}
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResponse(err.Error()))
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"id":    product.MySQLID,
-		"name":  product.Name,
-		"stock": product.Stock,
-	})
+	return c.Status(fiber.StatusCreated).JSON(newMySQLProductResponse(*product))
 }
 
 func (h *ProductHandlerMySQL) UpdateProduct(c *fiber.Ctx) error {
-	id, err := c.ParamsInt("id")
+	var input ProductUpdateRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorResponse("Invalid input format"))
+	}
+
+	existingProduct, err := h.Service.PatchProduct(c.UserContext(), c.Params("id"), input.changes())
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid product ID"})
+		if errors.Is(err, entity.ErrProductNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResponse("ID Not Found"))
+		}
+		if errors.Is(err, entity.ErrInvalidID) || errors.Is(err, entity.ErrNegativeStock) {
+			return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
+		}
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResponse(err.Error()))
 	}
 
-	productID := uint(id)
+	return c.Status(fiber.StatusOK).JSON(newMySQLProductResponse(*existingProduct))
+}
 
-	existingProduct, err := h.Service.GetProductByID(productID)
+func (h *ProductHandlerMySQL) GetProductByID(c *fiber.Ctx) error {
+	product, err := h.Service.GetProductByID(c.UserContext(), c.Params("id"))
 	if err != nil {
-		if err.Error() == "ID Not Found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ID Not Found"})
+		if errors.Is(err, entity.ErrProductNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResponse("ID Not Found"))
+		}
+		if errors.Is(err, entity.ErrInvalidID) {
+			return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
+		}
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorResponse(err.Error()))
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResponse(err.Error()))
 	}
 
-	var updatedProduct entity.Product
-	if err := c.BodyParser(&updatedProduct); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid input format"})
+	if conditionalProduct(c, *product) {
+		return c.SendStatus(fiber.StatusNotModified)
 	}
+	return c.Status(fiber.StatusOK).JSON(newMySQLProductResponse(*product))
+}
 
-	if updatedProduct.Name != "" {
-		existingProduct.Name = updatedProduct.Name
-	}
-	if updatedProduct.Stock != 0 {
-		existingProduct.Stock = updatedProduct.Stock
+func (h *ProductHandlerMySQL) ListProducts(c *fiber.Ctx) error {
+	products, err := h.Service.ListProducts(c.UserContext())
+	if err != nil {
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResponse(err.Error()))
 	}
 
-	if err := h.Service.UpdateProduct(existingProduct); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	if conditionalList(c, products) {
+		return c.SendStatus(fiber.StatusNotModified)
 	}
-
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"id":    existingProduct.MySQLID,
-		"name":  existingProduct.Name,
-		"stock": existingProduct.Stock,
-	})
+	return c.Status(fiber.StatusOK).JSON(newProductResponses(products, newMySQLProductResponse))
 }
 
-func (h *ProductHandlerMySQL) GetProductByID(c *fiber.Ctx) error {
-	id, err := c.ParamsInt("id")
+// ListProductsPage serves the v2 list: one page of products in an envelope.
+func (h *ProductHandlerMySQL) ListProductsPage(c *fiber.Ctx) error {
+	query, err := productQuery(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid product ID"})
+		return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
 	}
 
-	productID := uint(id)
-
-	product, err := h.Service.GetProductByID(productID)
+	page, err := h.Service.ListProductsPage(c.UserContext(), query)
 	if err != nil {
-		if err.Error() == "ID not found" || err.Error() == "record not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ID Not Found"})
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorResponse(err.Error()))
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResponse(err.Error()))
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"id":    product.MySQLID,
-		"name":  product.Name,
-		"stock": product.Stock,
-	})
+	if conditionalList(c, page.Products, page.Total, int64(page.Offset), int64(page.Limit)) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	return c.Status(fiber.StatusOK).JSON(newProductPageResponse(page, newMySQLProductResponse))
 }
 
-func (h *ProductHandlerMySQL) ListProducts(c *fiber.Ctx) error {
-	products, err := h.Service.ListProducts()
+// SearchProducts serves a page of the products matching ?q=, most relevant
+// first.
+func (h *ProductHandlerMySQL) SearchProducts(c *fiber.Ctx) error {
+	search, err := searchQuery(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-	}
-
-	if len(products) == 0 {
-		return c.Status(fiber.StatusOK).JSON([]fiber.Map{})
+		return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
 	}
 
-	var productResponses []fiber.Map
-	for _, product := range products {
-		productResponses = append(productResponses, fiber.Map{
-			"id":    product.MySQLID,
-			"name":  product.Name,
-			"stock": product.Stock,
-		})
+	result, err := h.Service.SearchProducts(c.UserContext(), search)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidSearch) {
+			return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
+		}
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorResponse(err.Error()))
+		}
+		if errors.Is(err, entity.ErrNoSearch) {
+			return c.Status(fiber.StatusNotImplemented).JSON(errorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResponse(err.Error()))
 	}
 
-	return c.Status(fiber.StatusOK).JSON(productResponses)
+	return c.Status(fiber.StatusOK).JSON(newProductSearchResponse(result, newMySQLProductResponse))
 }
 
 func (h *ProductHandlerMySQL) DeleteProduct(c *fiber.Ctx) error {
-	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid product ID"})
-	}
-
-	if err := h.Service.DeleteProduct(uint(id)); err != nil {
-		if err.Error() == "ID not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "ID Not Found"})
+	if err := h.Service.DeleteProduct(c.UserContext(), c.Params("id")); err != nil {
+		if errors.Is(err, entity.ErrProductNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResponse("ID Not Found"))
+		}
+		if errors.Is(err, entity.ErrInvalidID) {
+			return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
+		}
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorResponse(err.Error()))
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResponse(err.Error()))
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Product deleted successfully"})
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Product deleted successfully"})
 }
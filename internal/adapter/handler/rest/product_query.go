@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"errors"
+	"go-hexagon/internal/core/domain/entity"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// productQuery reads ?offset=&limit=&name=&min_stock=&max_stock= from the
+// request. Offset and limit are normalised by the service.
+func productQuery(c *fiber.Ctx) (entity.ProductQuery, error) {
+	query := entity.ProductQuery{NameContains: c.Query("name")}
+
+	var err error
+	if query.Offset, err = queryInt(c, "offset"); err != nil {
+		return query, err
+	}
+	if query.Limit, err = queryInt(c, "limit"); err != nil {
+		return query, err
+	}
+	if c.Query("min_stock") != "" {
+		minStock, err := queryInt(c, "min_stock")
+		if err != nil {
+			return query, err
+		}
+		query.MinStock = &minStock
+	}
+	if c.Query("max_stock") != "" {
+		maxStock, err := queryInt(c, "max_stock")
+		if err != nil {
+			return query, err
+		}
+		query.MaxStock = &maxStock
+	}
+	return query, nil
+}
+
+// searchQuery reads ?q=&offset=&limit= from the request.
+func searchQuery(c *fiber.Ctx) (entity.ProductSearch, error) {
+	search := entity.ProductSearch{Text: c.Query("q")}
+
+	var err error
+	if search.Offset, err = queryInt(c, "offset"); err != nil {
+		return search, err
+	}
+	if search.Limit, err = queryInt(c, "limit"); err != nil {
+		return search, err
+	}
+	return search, nil
+}
+
+func queryInt(c *fiber.Ctx, key string) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, errors.New("Invalid " + key)
+	}
+	return value, nil
+}
@@ -0,0 +1,112 @@
+package rest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go-hexagon/internal/adapter/eventstream"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/policy"
+	"go-hexagon/internal/core/service"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProductStreamHandler struct {
+	Service   *service.ProductService
+	Broker    *eventstream.Broker
+	KeepAlive time.Duration
+}
+
+func NewProductStreamHandler(productService *service.ProductService, broker *eventstream.Broker) *ProductStreamHandler {
+	return &ProductStreamHandler{Service: productService, Broker: broker, KeepAlive: 15 * time.Second}
+}
+
+// Stream serves product changes as Server-Sent Events. The optional "id"
+// query parameter (comma separated) limits the stream to those products, and
+// Last-Event-ID (header or last_event_id query) resumes from the buffer.
+func (h *ProductStreamHandler) Stream(c *fiber.Ctx) error {
+	// The broker bypasses the service, so the stream checks the action itself.
+	if err := h.Service.Authorize(c.UserContext(), policy.ActionList); err != nil {
+		if errors.Is(err, entity.ErrForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(errorResponse(err.Error()))
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResponse(err.Error()))
+	}
+
+	lastEventID := c.Get("Last-Event-ID", c.Query("last_event_id"))
+	var resumeFrom uint64
+	if lastEventID != "" {
+		id, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(errorResponse("Invalid Last-Event-ID"))
+		}
+		resumeFrom = id
+	}
+
+	productIDs := map[string]bool{}
+	for _, id := range strings.Split(c.Query("id"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			productIDs[id] = true
+		}
+	}
+	wanted := func(e eventstream.Event) bool {
+		return len(productIDs) == 0 || productIDs[e.ProductID]
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	sub := h.Broker.Subscribe(resumeFrom)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer h.Broker.Unsubscribe(sub)
+
+		fmt.Fprint(w, "retry: 3000\n\n")
+		if sub.Gap {
+			// Some events were evicted from the buffer, so the client should
+			// reload the full list before applying further changes. The
+			// reload covers the backlog, and the live events follow the id.
+			fmt.Fprintf(w, "id: %d\nevent: reset\ndata: {}\n\n", sub.LastID)
+		} else {
+			for _, e := range sub.Backlog {
+				if wanted(e) {
+					writeEvent(w, e)
+				}
+			}
+		}
+		if w.Flush() != nil {
+			return
+		}
+
+		ticker := time.NewTicker(h.KeepAlive)
+		defer ticker.Stop()
+		for {
+			select {
+			case e, ok := <-sub.Events:
+				if !ok {
+					return
+				}
+				if !wanted(e) {
+					continue
+				}
+				writeEvent(w, e)
+			case <-ticker.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+			}
+			if w.Flush() != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func writeEvent(w *bufio.Writer, e eventstream.Event) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, e.Data)
+}
@@ -0,0 +1,22 @@
+package rest
+
+import (
+	"go-hexagon/internal/core/domain/entity"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HeaderReadYourWrites asks for reads from the primary database, e.g. on the
+// first GET after a write, which a lagging replica may not have yet.
+const HeaderReadYourWrites = "X-Read-Your-Writes"
+
+// ReadYourWrites pins the reads of a request to the primary once it writes,
+// or from the start if it sends X-Read-Your-Writes: true.
+func ReadYourWrites() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		primary := strings.EqualFold(c.Get(HeaderReadYourWrites), "true")
+		c.SetUserContext(entity.WithReadYourWrites(c.UserContext(), primary))
+		return c.Next()
+	}
+}
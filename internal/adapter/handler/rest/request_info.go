@@ -0,0 +1,59 @@
+package rest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"go-hexagon/internal/core/domain/entity"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// maxRequestIDLength bounds the X-Request-ID accepted from clients, since it
+// ends up in the audit log.
+const maxRequestIDLength = 128
+
+// RequestInfo stores the request ID and client IP in the request's user
+// context. The ID is taken from X-Request-ID when the client sends one and is
+// echoed in the response.
+func RequestInfo() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Fiber reuses the request buffers, so the values are copied before
+		// they are kept beyond the handler.
+		id := utils.CopyString(c.Get(fiber.HeaderXRequestID))
+		if id == "" || len(id) > maxRequestIDLength {
+			id = newRequestID()
+		}
+		c.Set(fiber.HeaderXRequestID, id)
+
+		info := entity.RequestInfo{ID: id, ClientIP: utils.CopyString(c.IP())}
+		c.SetUserContext(entity.WithRequestInfo(c.UserContext(), info))
+		return c.Next()
+	}
+}
+
+// ProxyConfig returns the Fiber settings under which c.IP() is the client
+// address a trusted proxy sent in header rather than the proxy's own address.
+// The header of any other peer is ignored, so clients cannot pick the IP
+// they are rate limited and audited under. Without trusted proxies the
+// header is never read.
+func ProxyConfig(trustedProxies []string, header string) fiber.Config {
+	if len(trustedProxies) == 0 {
+		return fiber.Config{}
+	}
+	return fiber.Config{
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          trustedProxies,
+		ProxyHeader:             header,
+		// X-Forwarded-For may hold a list; c.IP() returns its first valid IP.
+		EnableIPValidation: true,
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,119 @@
+package rest
+
+import (
+	"errors"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WebhookHandler struct {
+	Service *service.WebhookService
+}
+
+func NewWebhookHandler(service *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{Service: service}
+}
+
+func (h *WebhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	var input WebhookRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
+	}
+
+	webhook := input.toEntity()
+	if err := h.Service.CreateWebhook(webhook); err != nil {
+		return webhookError(c, err)
+	}
+
+	// The secret is only returned once, when the subscription is created.
+	return c.Status(fiber.StatusCreated).JSON(newWebhookResponse(*webhook, true))
+}
+
+func (h *WebhookHandler) UpdateWebhook(c *fiber.Ctx) error {
+	existing, err := h.Service.GetWebhookByID(c.Params("id"))
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	var input WebhookUpdateRequest
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(errorResponse("Invalid input format"))
+	}
+	input.apply(existing)
+
+	if err := h.Service.UpdateWebhook(existing); err != nil {
+		return webhookError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(newWebhookResponse(*existing, false))
+}
+
+func (h *WebhookHandler) GetWebhookByID(c *fiber.Ctx) error {
+	webhook, err := h.Service.GetWebhookByID(c.Params("id"))
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(newWebhookResponse(*webhook, false))
+}
+
+func (h *WebhookHandler) ListWebhooks(c *fiber.Ctx) error {
+	webhooks, err := h.Service.ListWebhooks()
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	responses := make([]WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		responses = append(responses, newWebhookResponse(webhook, false))
+	}
+	return c.Status(fiber.StatusOK).JSON(responses)
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *fiber.Ctx) error {
+	if err := h.Service.DeleteWebhook(c.Params("id")); err != nil {
+		return webhookError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{Message: "Webhook deleted successfully"})
+}
+
+func (h *WebhookHandler) ListDeliveries(c *fiber.Ctx) error {
+	deliveries, err := h.Service.ListDeliveries(c.Params("id"))
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(newWebhookDeliveryResponses(deliveries))
+}
+
+func (h *WebhookHandler) ListDeadLetters(c *fiber.Ctx) error {
+	deliveries, err := h.Service.ListDeadLetters()
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(newWebhookDeliveryResponses(deliveries))
+}
+
+func (h *WebhookHandler) RetryDelivery(c *fiber.Ctx) error {
+	delivery, err := h.Service.RetryDelivery(c.Params("id"))
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(newWebhookDeliveryResponse(*delivery))
+}
+
+func webhookError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidWebhook):
+		return c.Status(fiber.StatusBadRequest).JSON(errorResponse(err.Error()))
+	case errors.Is(err, entity.ErrWebhookNotFound), errors.Is(err, entity.ErrDeliveryNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(errorResponse(err.Error()))
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResponse(err.Error()))
+	}
+}
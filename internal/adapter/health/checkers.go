@@ -0,0 +1,48 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"go-hexagon/internal/core/port"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// SQL pings db, opening a connection if the pool has none.
+func SQL(db *sql.DB) Checker {
+	return CheckerFunc(db.PingContext)
+}
+
+// Mongo pings the primary, which serves the writes.
+func Mongo(client *mongo.Client) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		return client.Ping(ctx, readpref.Primary())
+	})
+}
+
+// Cache writes a value to cache and reads it back.
+func Cache(cache port.Cache) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		const key = "health:probe"
+		value := []byte(strconv.FormatInt(time.Now().UnixNano(), 10))
+		cache.Set(key, value, time.Minute)
+		defer cache.Delete(key)
+		if got, ok := cache.Get(key); !ok || !bytes.Equal(got, value) {
+			return errors.New("cache did not return the value just stored")
+		}
+		return nil
+	})
+}
+
+// Redis pings the shared Redis used by the rate limiter.
+func Redis(client *redis.Client) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	})
+}
@@ -0,0 +1,163 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusFailing  = "failing"
+	StatusDraining = "draining"
+)
+
+// DefaultTimeout bounds each check, so one hanging dependency cannot hold
+// up the probe.
+const DefaultTimeout = 2 * time.Second
+
+// Checker reports whether a dependency is usable; a nil error means it is.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Health serves the liveness and readiness probes. Liveness only checks
+// what restarting the process would fix; readiness checks every dependency
+// a request needs.
+type Health struct {
+	Timeout time.Duration
+
+	mu        sync.RWMutex
+	liveness  map[string]Checker
+	readiness map[string]Checker
+	draining  atomic.Bool
+}
+
+type Report struct {
+	Status     string               `json:"status"`
+	Components map[string]Component `json:"components"`
+}
+
+type Component struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+func New() *Health {
+	return &Health{Timeout: DefaultTimeout, liveness: map[string]Checker{}, readiness: map[string]Checker{}}
+}
+
+func (h *Health) AddLiveness(name string, checker Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.liveness[name] = checker
+}
+
+func (h *Health) AddReadiness(name string, checker Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.readiness[name] = checker
+}
+
+// Drain makes readiness fail from now on, so load balancers stop sending
+// new requests while the process shuts down.
+func (h *Health) Drain() {
+	h.draining.Store(true)
+}
+
+// Liveness serves /healthz.
+func (h *Health) Liveness(c *fiber.Ctx) error {
+	h.mu.RLock()
+	report := h.run(c.UserContext(), h.liveness)
+	h.mu.RUnlock()
+	return respond(c, report)
+}
+
+// Readiness serves /readyz. While draining it still runs the checks, so the
+// report shows the state of each component.
+func (h *Health) Readiness(c *fiber.Ctx) error {
+	h.mu.RLock()
+	report := h.run(c.UserContext(), h.readiness)
+	h.mu.RUnlock()
+	if h.draining.Load() {
+		report.Status = StatusDraining
+	}
+	return respond(c, report)
+}
+
+// Component serves the readiness check registered as name on its own, for
+// probes that predate /readyz. It answers 404 when there is no such check.
+func (h *Health) Component(name string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		h.mu.RLock()
+		checker, ok := h.readiness[name]
+		h.mu.RUnlock()
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": name + " is not used by this server"})
+		}
+		return respond(c, h.run(c.UserContext(), map[string]Checker{name: checker}))
+	}
+}
+
+// run checks all components concurrently.
+func (h *Health) run(ctx context.Context, checkers map[string]Checker) Report {
+	report := Report{Status: StatusOK, Components: make(map[string]Component, len(checkers))}
+	names := make([]string, 0, len(checkers))
+	for name := range checkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]Component, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			results[i] = h.check(ctx, checker)
+		}(i, checkers[name])
+	}
+	wg.Wait()
+
+	for i, name := range names {
+		report.Components[name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFailing
+		}
+	}
+	return report
+}
+
+func (h *Health) check(ctx context.Context, checker Checker) Component {
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := checker.Check(ctx)
+	component := Component{Status: StatusOK, LatencyMS: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		component.Status = StatusFailing
+		component.Error = err.Error()
+	}
+	return component
+}
+
+func respond(c *fiber.Ctx, report Report) error {
+	status := fiber.StatusOK
+	if report.Status != StatusOK {
+		status = fiber.StatusServiceUnavailable
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(status).JSON(report)
+}
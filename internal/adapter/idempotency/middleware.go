@@ -0,0 +1,150 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/port"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+
+	DefaultTTL = 24 * time.Hour
+
+	maxKeyLength = 255
+	// purgeInterval is how often expired records are deleted.
+	purgeInterval = 10 * time.Minute
+)
+
+// Idempotency replays the stored response of a POST request whose
+// Idempotency-Key was seen before, instead of running it again.
+type Idempotency struct {
+	store port.IdempotencyStore
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	lastPurge time.Time
+}
+
+func New(store port.IdempotencyStore, ttl time.Duration) *Idempotency {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Idempotency{store: store, ttl: ttl, lastPurge: time.Now()}
+}
+
+// Middleware handles POST requests that carry an Idempotency-Key. Keys are
+// scoped to the principal, so it has to run after authentication. The first
+// response is stored unless it is a server error, which leaves the key free
+// for a retry. A key reused for a different method, path or body is rejected
+// with 422 and a key whose first request is still running with 409.
+func (i *Idempotency) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderKey)
+		if c.Method() != fiber.MethodPost || key == "" {
+			return c.Next()
+		}
+		if len(key) > maxKeyLength {
+			return c.Status(fiber.StatusBadRequest).JSON(rest.ErrorResponse{Error: "Idempotency-Key is longer than 255 characters"})
+		}
+
+		now := time.Now().UTC()
+		record := &entity.IdempotencyRecord{
+			Key:         scope(c, key),
+			RequestHash: requestHash(c),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(i.ttl),
+		}
+		existing, reserved, err := i.store.Reserve(record)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(rest.ErrorResponse{Error: err.Error()})
+		}
+		i.purge(now)
+
+		if !reserved {
+			return replay(c, existing, record.RequestHash)
+		}
+
+		if err := c.Next(); err != nil {
+			i.release(c.UserContext(), record.Key)
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			i.release(c.UserContext(), record.Key)
+			return nil
+		}
+		record.Completed = true
+		record.StatusCode = status
+		record.ContentType = string(c.Response().Header.ContentType())
+		record.Body = append([]byte(nil), c.Response().Body()...)
+		if err := i.store.Complete(record); err != nil {
+			slog.ErrorContext(c.UserContext(), "Failed to store response for idempotency key",
+				slog.String("idempotency_key", key), slog.String("error", err.Error()))
+		}
+		return nil
+	}
+}
+
+func replay(c *fiber.Ctx, existing *entity.IdempotencyRecord, requestHash string) error {
+	if existing.RequestHash != requestHash {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(rest.ErrorResponse{Error: "Idempotency-Key was already used for a different request"})
+	}
+	if !existing.Completed {
+		return c.Status(fiber.StatusConflict).JSON(rest.ErrorResponse{Error: "A request with this Idempotency-Key is still being processed"})
+	}
+	c.Set(HeaderReplayed, "true")
+	if existing.ContentType != "" {
+		c.Set(fiber.HeaderContentType, existing.ContentType)
+	}
+	return c.Status(existing.StatusCode).Send(existing.Body)
+}
+
+func (i *Idempotency) release(ctx context.Context, key string) {
+	if err := i.store.Release(key); err != nil {
+		slog.ErrorContext(ctx, "Failed to release idempotency key", slog.String("error", err.Error()))
+	}
+}
+
+// purge deletes expired records in the background, at most once per
+// purgeInterval.
+func (i *Idempotency) purge(now time.Time) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if now.Sub(i.lastPurge) < purgeInterval {
+		return
+	}
+	i.lastPurge = now
+	go func() {
+		if err := i.store.DeleteExpired(now); err != nil {
+			slog.Error("Failed to delete expired idempotency keys", slog.String("error", err.Error()))
+		}
+	}()
+}
+
+// scope prefixes the key with the principal so clients cannot read each
+// other's responses by guessing keys.
+func scope(c *fiber.Ctx, key string) string {
+	principal, ok := entity.PrincipalFrom(c.UserContext())
+	if !ok {
+		return "anonymous:" + key
+	}
+	return principal.Method + ":" + principal.Subject + ":" + key
+}
+
+func requestHash(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method() + " " + c.Path() + "\n"))
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
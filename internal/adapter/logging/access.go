@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"errors"
+	"go-hexagon/internal/core/domain/entity"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AccessLog logs one record per request once it has been handled. It reads
+// the principal from the user context after the handlers ran, so it may be
+// registered before the authentication middleware.
+func AccessLog(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Method()),
+			slog.String("route", c.Route().Path),
+			slog.String("path", c.Path()),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.IP()),
+		}
+		if principal, ok := entity.PrincipalFrom(c.UserContext()); ok {
+			attrs = append(attrs, slog.String("principal", principal.Subject), slog.String("auth_method", principal.Method))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		logger.LogAttrs(c.UserContext(), level, "request", attrs...)
+		return err
+	}
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/port"
+	"log/slog"
+)
+
+// productRepository logs the failed calls of the wrapped repository. It is
+// meant for backends whose driver does not log by itself; GORM logs through
+// GormLogger instead.
+type productRepository struct {
+	next    port.ProductRepository
+	logger  *slog.Logger
+	backend string
+}
+
+func ProductRepository(repo port.ProductRepository, logger *slog.Logger, backend string) port.ProductRepository {
+	return &productRepository{next: repo, logger: logger, backend: backend}
+}
+
+func (r *productRepository) log(ctx context.Context, method string, err error) {
+	// Unknown and malformed IDs are answers, not failures of the database.
+	if err == nil || errors.Is(err, entity.ErrProductNotFound) || errors.Is(err, entity.ErrInvalidID) {
+		return
+	}
+	r.logger.ErrorContext(ctx, "Repository call failed",
+		slog.String("backend", r.backend),
+		slog.String("method", method),
+		slog.String("error", err.Error()),
+	)
+}
+
+func (r *productRepository) Create(ctx context.Context, product *entity.Product) error {
+	err := r.next.Create(ctx, product)
+	r.log(ctx, "Create", err)
+	return err
+}
+
+func (r *productRepository) Update(ctx context.Context, product *entity.Product) error {
+	err := r.next.Update(ctx, product)
+	r.log(ctx, "Update", err)
+	return err
+}
+
+func (r *productRepository) GetByID(ctx context.Context, id string) (*entity.Product, error) {
+	product, err := r.next.GetByID(ctx, id)
+	r.log(ctx, "GetByID", err)
+	return product, err
+}
+
+func (r *productRepository) GetByIDs(ctx context.Context, ids []string) ([]entity.Product, error) {
+	products, err := r.next.GetByIDs(ctx, ids)
+	r.log(ctx, "GetByIDs", err)
+	return products, err
+}
+
+func (r *productRepository) List(ctx context.Context) ([]entity.Product, error) {
+	products, err := r.next.List(ctx)
+	r.log(ctx, "List", err)
+	return products, err
+}
+
+func (r *productRepository) ListPage(ctx context.Context, query entity.ProductQuery) (*entity.ProductPage, error) {
+	page, err := r.next.ListPage(ctx, query)
+	r.log(ctx, "ListPage", err)
+	return page, err
+}
+
+func (r *productRepository) Delete(ctx context.Context, id string) error {
+	err := r.next.Delete(ctx, id)
+	r.log(ctx, "Delete", err)
+	return err
+}
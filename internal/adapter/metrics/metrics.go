@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"go-hexagon/internal/core/port"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "go_hexagon"
+
+// Metrics owns the Prometheus registry of the application and the
+// collectors the adapters report to.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	httpRequests   *prometheus.CounterVec
+	httpDuration   *prometheus.HistogramVec
+	repoDuration   *prometheus.HistogramVec
+	repoErrors     *prometheus.CounterVec
+	mongoPool      *prometheus.GaugeVec
+	mongoPoolFails prometheus.Counter
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "http_requests_total",
+			Help: "HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "http_request_duration_seconds",
+			Help:    "HTTP request latency by method, route and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		repoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "repository_call_duration_seconds",
+			Help:    "Product repository call latency by backend and method.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"backend", "method"}),
+		repoErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "repository_errors_total",
+			Help: "Product repository calls that failed, not counting unknown or invalid IDs.",
+		}, []string{"backend", "method"}),
+		mongoPool: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "mongo_pool_connections",
+			Help: "MongoDB driver connections by state (open or in_use).",
+		}, []string{"state"}),
+		mongoPoolFails: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "mongo_pool_checkout_failures_total",
+			Help: "Failed attempts to check a connection out of the MongoDB pool.",
+		}),
+	}
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpDuration, m.repoDuration, m.repoErrors,
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus text format.
+func (m *Metrics) Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware records every request under the route it matched, e.g.
+// /api/v1/products/:id, so IDs do not end up in label values. Requests no
+// route matched are recorded under the path of the last middleware.
+func (m *Metrics) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			// The error handler has not written the response yet.
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+		labels := prometheus.Labels{
+			"method": c.Method(),
+			"route":  c.Route().Path,
+			"status": strconv.Itoa(status),
+		}
+		m.httpRequests.With(labels).Inc()
+		m.httpDuration.With(labels).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// RegisterSQLPool exports sql.DB.Stats() of db as the go_sql_* metrics
+// labelled with name.
+func (m *Metrics) RegisterSQLPool(db *sql.DB, name string) {
+	m.Registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterProductCache exports the hit and miss counts of the product cache.
+func (m *Metrics) RegisterProductCache(cache port.CacheStatsReporter) {
+	m.Registry.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace, Name: "product_cache_hits_total",
+			Help: "Product lookups served from the cache.",
+		}, func() float64 { return float64(cache.Stats().Hits) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace, Name: "product_cache_misses_total",
+			Help: "Product lookups the cache passed to the database.",
+		}, func() float64 { return float64(cache.Stats().Misses) }),
+	)
+}
+
+// DualWriteDivergences returns a function that counts a divergence between
+// the product stores by kind and operation, to alert on while migrating
+// between backends.
+func (m *Metrics) DualWriteDivergences() func(kind, operation string) {
+	divergences := register(m.Registry, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Name: "dual_write_divergences_total",
+		Help: "Differences between the primary and secondary product store by kind and operation.",
+	}, []string{"kind", "operation"}))
+	return func(kind, operation string) {
+		divergences.WithLabelValues(kind, operation).Inc()
+	}
+}
+
+// register adds collector to registry, or returns the equal collector that
+// is already registered so it can be called more than once.
+func register[C prometheus.Collector](registry *prometheus.Registry, collector C) C {
+	err := registry.Register(collector)
+	if err == nil {
+		return collector
+	}
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		if existing, ok := registered.ExistingCollector.(C); ok {
+			return existing
+		}
+	}
+	panic(err)
+}
@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// MongoPoolMonitor returns a pool monitor for the MongoDB client options
+// that keeps the mongo_pool_* metrics up to date. It registers the metrics
+// on first use, so they only appear with a MongoDB client; the monitors of
+// every client report to the same metrics.
+func (m *Metrics) MongoPoolMonitor() *event.PoolMonitor {
+	pool := register(m.Registry, m.mongoPool)
+	fails := register(m.Registry, m.mongoPoolFails)
+	open := pool.WithLabelValues("open")
+	inUse := pool.WithLabelValues("in_use")
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				open.Inc()
+			case event.ConnectionClosed:
+				open.Dec()
+			case event.GetSucceeded:
+				inUse.Inc()
+			case event.ConnectionReturned:
+				inUse.Dec()
+			case event.GetFailed:
+				fails.Inc()
+			}
+		},
+	}
+}
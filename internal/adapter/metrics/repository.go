@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/port"
+	"time"
+)
+
+// productRepository times every call to the wrapped repository.
+type productRepository struct {
+	next    port.ProductRepository
+	backend string
+	metrics *Metrics
+}
+
+// ProductRepository wraps repo so its calls are recorded under backend,
+// e.g. mysql or mongodb.
+func (m *Metrics) ProductRepository(repo port.ProductRepository, backend string) port.ProductRepository {
+	return &productRepository{next: repo, backend: backend, metrics: m}
+}
+
+func (r *productRepository) observe(method string, start time.Time, err error) {
+	r.metrics.observeRepository(r.backend, method, start, err)
+}
+
+func (m *Metrics) observeRepository(backend, method string, start time.Time, err error) {
+	m.repoDuration.WithLabelValues(backend, method).Observe(time.Since(start).Seconds())
+	// Unknown and malformed IDs are answers, not failures of the database.
+	if err != nil && !errors.Is(err, entity.ErrProductNotFound) && !errors.Is(err, entity.ErrInvalidID) {
+		m.repoErrors.WithLabelValues(backend, method).Inc()
+	}
+}
+
+func (r *productRepository) Create(ctx context.Context, product *entity.Product) error {
+	start := time.Now()
+	err := r.next.Create(ctx, product)
+	r.observe("Create", start, err)
+	return err
+}
+
+func (r *productRepository) Update(ctx context.Context, product *entity.Product) error {
+	start := time.Now()
+	err := r.next.Update(ctx, product)
+	r.observe("Update", start, err)
+	return err
+}
+
+func (r *productRepository) GetByID(ctx context.Context, id string) (*entity.Product, error) {
+	start := time.Now()
+	product, err := r.next.GetByID(ctx, id)
+	r.observe("GetByID", start, err)
+	return product, err
+}
+
+func (r *productRepository) GetByIDs(ctx context.Context, ids []string) ([]entity.Product, error) {
+	start := time.Now()
+	products, err := r.next.GetByIDs(ctx, ids)
+	r.observe("GetByIDs", start, err)
+	return products, err
+}
+
+func (r *productRepository) List(ctx context.Context) ([]entity.Product, error) {
+	start := time.Now()
+	products, err := r.next.List(ctx)
+	r.observe("List", start, err)
+	return products, err
+}
+
+func (r *productRepository) ListPage(ctx context.Context, query entity.ProductQuery) (*entity.ProductPage, error) {
+	start := time.Now()
+	page, err := r.next.ListPage(ctx, query)
+	r.observe("ListPage", start, err)
+	return page, err
+}
+
+func (r *productRepository) Delete(ctx context.Context, id string) error {
+	start := time.Now()
+	err := r.next.Delete(ctx, id)
+	r.observe("Delete", start, err)
+	return err
+}
+
+// productSearcher times searches alongside the repository calls.
+type productSearcher struct {
+	next    port.ProductSearcher
+	backend string
+	metrics *Metrics
+}
+
+// ProductSearcher wraps searcher so its searches are recorded under backend
+// as the Search method.
+func (m *Metrics) ProductSearcher(searcher port.ProductSearcher, backend string) port.ProductSearcher {
+	return &productSearcher{next: searcher, backend: backend, metrics: m}
+}
+
+func (s *productSearcher) Search(ctx context.Context, search entity.ProductSearch) (*entity.ProductSearchResult, error) {
+	start := time.Now()
+	result, err := s.next.Search(ctx, search)
+	s.metrics.observeRepository(s.backend, "Search", start, err)
+	return result, err
+}
@@ -0,0 +1,33 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+type Handler struct {
+	document []byte
+}
+
+func NewHandler() (*Handler, error) {
+	document, err := json.Marshal(Document())
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{document: document}, nil
+}
+
+func (h *Handler) Spec(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Status(fiber.StatusOK).Send(h.document)
+}
+
+func (h *Handler) Docs(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Status(fiber.StatusOK).Send(docsPage)
+}
@@ -0,0 +1,136 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Schema map[string]interface{}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// SchemaOf derives a JSON schema from a Go type using its json tags. Extra
+// constraints come from the openapi tag, e.g. `openapi:"minLength=1,maxLength=255"`.
+// Fields without omitempty are required.
+func SchemaOf(v interface{}) Schema {
+	return schemaFor(reflect.TypeOf(v))
+}
+
+func schemaFor(t reflect.Type) Schema {
+	if t.Kind() == reflect.Ptr {
+		schema := schemaFor(t.Elem())
+		schema["nullable"] = true
+		return schema
+	}
+	if t == timeType {
+		return Schema{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Schema{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return Schema{}
+	}
+}
+
+func structSchema(t reflect.Type) Schema {
+	properties := Schema{}
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, omitempty, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		schema := schemaFor(field.Type)
+		applyConstraints(schema, field.Tag.Get("openapi"))
+		if schema["readOnly"] == true {
+			omitempty = true
+		}
+		properties[name] = schema
+		if !omitempty {
+			required = append(required, name)
+		}
+	}
+
+	schema := Schema{"type": "object", "properties": properties, "additionalProperties": false}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func jsonName(field reflect.StructField) (name string, omitempty bool, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+func applyConstraints(schema Schema, tag string) {
+	if tag == "" {
+		return
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "minLength", "maxLength", "minItems", "maxItems":
+			n, _ := strconv.Atoi(value)
+			schema[key] = n
+		case "minimum", "maximum":
+			n, _ := strconv.ParseFloat(value, 64)
+			schema[key] = n
+		case "enum":
+			values := strings.Split(value, "|")
+			if items, ok := schema["items"].(Schema); ok {
+				items["enum"] = values
+			} else {
+				schema["enum"] = values
+			}
+		case "format", "pattern", "description":
+			schema[key] = value
+		case "readOnly", "nullable":
+			schema[key] = true
+		case "oneOf":
+			delete(schema, "type")
+			var variants []Schema
+			for _, typ := range strings.Split(value, "|") {
+				variants = append(variants, Schema{"type": typ})
+			}
+			schema["oneOf"] = variants
+		}
+	}
+}
@@ -0,0 +1,501 @@
+package openapi
+
+import (
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/adapter/health"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+type param struct {
+	Name        string
+	In          string
+	Description string
+	Schema      Schema
+	Required    bool
+}
+
+type response struct {
+	Description string
+	ContentType string
+	Schema      Schema
+}
+
+type operation struct {
+	Method  string
+	Path    string
+	ID      string
+	Summary string
+	Tag     string
+	Params  []param
+	Body    string
+	// BodySchema is used instead of Body when the request body is not a
+	// single component.
+	BodySchema Schema
+	Responses  map[int]response
+	Deprecated bool
+	// Public operations are served without credentials.
+	Public bool
+}
+
+var (
+	productID = param{Name: "id", In: "path", Required: true, Description: "Product ID", Schema: Schema{"type": "string", "pattern": "^([0-9]+|[0-9a-fA-F]{24})$"}}
+	webhookID = param{Name: "id", In: "path", Required: true, Description: "Webhook ID", Schema: Schema{"type": "string", "pattern": "^[0-9a-f]{32}$"}}
+	// idempotencyKey is honoured by every POST; it is documented where a
+	// retry would otherwise create a duplicate.
+	idempotencyKey = param{Name: "Idempotency-Key", In: "header", Description: "Retries with the same key and body replay the first response", Schema: Schema{"type": "string", "maxLength": 255}}
+	// ifNoneMatch and ifModifiedSince revalidate product reads; lists only
+	// honour If-None-Match.
+	ifNoneMatch     = param{Name: "If-None-Match", In: "header", Description: "ETag of a cached copy; answered with 304 when it is still current", Schema: Schema{"type": "string"}}
+	ifModifiedSince = param{Name: "If-Modified-Since", In: "header", Description: "Last-Modified of a cached copy; answered with 304 when the product has not changed since", Schema: Schema{"type": "string"}}
+	notModified     = response{Description: "The cached copy is still current"}
+	deliveryID      = param{Name: "id", In: "path", Required: true, Description: "Delivery ID", Schema: Schema{"type": "string", "pattern": "^[0-9a-f]{32}$"}}
+)
+
+func ref(name string) Schema {
+	return Schema{"$ref": "#/components/schemas/" + name}
+}
+
+func jsonResponse(description string, schema Schema) response {
+	return response{Description: description, ContentType: "application/json", Schema: schema}
+}
+
+func errorResponse(description string) response {
+	return jsonResponse(description, ref("ErrorResponse"))
+}
+
+func arrayOf(name string) Schema {
+	return Schema{"type": "array", "items": ref(name)}
+}
+
+// apiVersion describes one of the versions mounted under /api/<name>.
+type apiVersion struct {
+	Name       string
+	Deprecated bool
+}
+
+var apiVersions = []apiVersion{
+	{Name: "v1", Deprecated: true},
+	{Name: "v2"},
+}
+
+func listProducts(version string) operation {
+	if version == "v1" {
+		return operation{
+			Method: http.MethodGet, Path: "/products", ID: "listProducts", Summary: "List products", Tag: "products",
+			Params: []param{ifNoneMatch},
+			Responses: map[int]response{
+				200: jsonResponse("Products", arrayOf("Product")),
+				304: notModified,
+				500: errorResponse("Repository error"),
+			},
+		}
+	}
+	return operation{
+		Method: http.MethodGet, Path: "/products", ID: "listProducts", Summary: "List a page of products", Tag: "products",
+		Params: []param{
+			{Name: "offset", In: "query", Schema: Schema{"type": "integer", "minimum": 0}},
+			{Name: "limit", In: "query", Description: "Page size, at most 100", Schema: Schema{"type": "integer", "minimum": 1, "maximum": 100}},
+			{Name: "name", In: "query", Description: "Case insensitive substring of the name", Schema: Schema{"type": "string"}},
+			{Name: "min_stock", In: "query", Schema: Schema{"type": "integer"}},
+			{Name: "max_stock", In: "query", Schema: Schema{"type": "integer"}},
+			ifNoneMatch,
+		},
+		Responses: map[int]response{
+			200: jsonResponse("Products", ref("ProductPage")),
+			304: notModified,
+			400: errorResponse("Invalid query"),
+			500: errorResponse("Repository error"),
+		},
+	}
+}
+
+// apiOperations are the routes mounted under /api/<version>; paths are
+// relative to that prefix.
+func apiOperations(version string) []operation {
+	return []operation{
+		listProducts(version),
+		{
+			Method: http.MethodPost, Path: "/products", ID: "createProduct", Summary: "Create a product", Tag: "products",
+			Params: []param{idempotencyKey},
+			Body:   "ProductInput",
+			Responses: map[int]response{
+				200: jsonResponse("Product created (MongoDB)", ref("Product")),
+				201: jsonResponse("Product created (MySQL)", ref("Product")),
+				400: errorResponse("Invalid input"),
+				409: errorResponse("A request with the same Idempotency-Key is still being processed"),
+				422: errorResponse("The Idempotency-Key was used for a different request"),
+				500: errorResponse("Repository error"),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/products/stream", ID: "streamProducts", Summary: "Stream product changes as Server-Sent Events", Tag: "products",
+			Params: []param{
+				{Name: "id", In: "query", Description: "Comma separated product IDs to follow", Schema: Schema{"type": "string"}},
+				{Name: "last_event_id", In: "query", Description: "Resume after this event ID (same as the Last-Event-ID header)", Schema: Schema{"type": "integer", "minimum": 0}},
+				{Name: "Last-Event-ID", In: "header", Description: "Resume after this event ID", Schema: Schema{"type": "integer", "minimum": 0}},
+			},
+			Responses: map[int]response{
+				200: {Description: "Event stream", ContentType: "text/event-stream", Schema: Schema{"type": "string"}},
+				400: errorResponse("Invalid Last-Event-ID"),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/products/search", ID: "searchProducts", Summary: "Search products by relevance", Tag: "products",
+			Params: []param{
+				{Name: "q", In: "query", Required: true, Description: "Words to look for in the name; products matching any of them are returned", Schema: Schema{"type": "string", "minLength": 1}},
+				{Name: "offset", In: "query", Schema: Schema{"type": "integer", "minimum": 0}},
+				{Name: "limit", In: "query", Description: "Page size, at most 100", Schema: Schema{"type": "integer", "minimum": 1, "maximum": 100}},
+			},
+			Responses: map[int]response{
+				200: jsonResponse("Matching products, most relevant first", ref("ProductSearch")),
+				400: errorResponse("Invalid query"),
+				501: errorResponse("Search is not available"),
+				500: errorResponse("Repository error"),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/products/:id", ID: "getProduct", Summary: "Get a product", Tag: "products",
+			Params: []param{productID, ifNoneMatch, ifModifiedSince},
+			Responses: map[int]response{
+				200: jsonResponse("Product", ref("Product")),
+				304: notModified,
+				400: errorResponse("Invalid product ID"),
+				404: errorResponse("Product not found"),
+				500: errorResponse("Repository error"),
+			},
+		},
+		{
+			Method: http.MethodPut, Path: "/products/:id", ID: "updateProduct", Summary: "Update a product", Tag: "products",
+			Params: []param{productID}, Body: "ProductUpdate",
+			Responses: map[int]response{
+				200: jsonResponse("Updated product", ref("Product")),
+				400: errorResponse("Invalid input"),
+				404: errorResponse("Product not found"),
+				500: errorResponse("Repository error"),
+			},
+		},
+		{
+			Method: http.MethodDelete, Path: "/products/:id", ID: "deleteProduct", Summary: "Delete a product", Tag: "products",
+			Params: []param{productID},
+			Responses: map[int]response{
+				200: jsonResponse("Product deleted", ref("MessageResponse")),
+				400: errorResponse("Invalid product ID"),
+				404: errorResponse("Product not found"),
+				500: errorResponse("Repository error"),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/webhooks", ID: "listWebhooks", Summary: "List webhook subscriptions", Tag: "webhooks",
+			Responses: map[int]response{
+				200: jsonResponse("Webhooks", arrayOf("Webhook")),
+				500: errorResponse("Repository error"),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/webhooks", ID: "createWebhook", Summary: "Register a webhook", Tag: "webhooks",
+			Body: "WebhookInput",
+			Responses: map[int]response{
+				201: jsonResponse("Webhook created, including its secret", ref("Webhook")),
+				400: errorResponse("Invalid webhook"),
+				500: errorResponse("Repository error"),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/webhooks/dead-letters", ID: "listDeadLetters", Summary: "List deliveries that exhausted their retries", Tag: "webhooks",
+			Responses: map[int]response{
+				200: jsonResponse("Dead deliveries", arrayOf("WebhookDelivery")),
+				500: errorResponse("Repository error"),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/webhooks/deliveries/:id/retry", ID: "retryDelivery", Summary: "Retry a dead delivery", Tag: "webhooks",
+			Params: []param{deliveryID},
+			Responses: map[int]response{
+				202: jsonResponse("Delivery queued", ref("WebhookDelivery")),
+				400: errorResponse("Delivery is not dead"),
+				404: errorResponse("Delivery not found"),
+				500: errorResponse("Repository error"),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/webhooks/:id", ID: "getWebhook", Summary: "Get a webhook", Tag: "webhooks",
+			Params: []param{webhookID},
+			Responses: map[int]response{
+				200: jsonResponse("Webhook", ref("Webhook")),
+				404: errorResponse("Webhook not found"),
+				500: errorResponse("Repository error"),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/webhooks/:id/deliveries", ID: "listDeliveries", Summary: "Delivery log of a webhook", Tag: "webhooks",
+			Params: []param{webhookID},
+			Responses: map[int]response{
+				200: jsonResponse("Deliveries", arrayOf("WebhookDelivery")),
+				404: errorResponse("Webhook not found"),
+				500: errorResponse("Repository error"),
+			},
+		},
+		{
+			Method: http.MethodPut, Path: "/webhooks/:id", ID: "updateWebhook", Summary: "Update a webhook", Tag: "webhooks",
+			Params: []param{webhookID}, Body: "WebhookUpdate",
+			Responses: map[int]response{
+				200: jsonResponse("Updated webhook", ref("Webhook")),
+				400: errorResponse("Invalid webhook"),
+				404: errorResponse("Webhook not found"),
+				500: errorResponse("Repository error"),
+			},
+		},
+		{
+			Method: http.MethodDelete, Path: "/webhooks/:id", ID: "deleteWebhook", Summary: "Delete a webhook", Tag: "webhooks",
+			Params: []param{webhookID},
+			Responses: map[int]response{
+				200: jsonResponse("Webhook deleted", ref("MessageResponse")),
+				404: errorResponse("Webhook not found"),
+				500: errorResponse("Repository error"),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/audit", ID: "listAudit", Summary: "Audit log of product changes, newest first", Tag: "audit",
+			Params: []param{
+				{Name: "entity", In: "query", Required: true, Schema: Schema{"type": "string", "enum": []string{"product"}}},
+				{Name: "id", In: "query", Description: "Only records of this entity ID", Schema: Schema{"type": "string"}},
+				{Name: "limit", In: "query", Description: "At most 1000, defaults to 100", Schema: Schema{"type": "integer", "minimum": 1, "maximum": 1000}},
+			},
+			Responses: map[int]response{
+				200: jsonResponse("Audit records", arrayOf("AuditRecord")),
+				500: errorResponse("Repository error"),
+			},
+		},
+	}
+}
+
+// rootOperations are not versioned.
+func rootOperations() []operation {
+	return []operation{
+		{
+			Method: http.MethodGet, Path: "/graphql", ID: "graphqlQuery", Summary: "Run a GraphQL query", Tag: "graphql",
+			Params: []param{
+				{Name: "query", In: "query", Required: true, Schema: Schema{"type": "string"}},
+				{Name: "operationName", In: "query", Schema: Schema{"type": "string"}},
+				{Name: "variables", In: "query", Description: "JSON encoded variables", Schema: Schema{"type": "string"}},
+			},
+			Responses: map[int]response{
+				200: jsonResponse("GraphQL result", Schema{"type": "object"}),
+				400: errorResponse("Invalid variables"),
+				405: errorResponse("Operation is not a query"),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/graphql", ID: "graphqlExecute", Summary: "Run a GraphQL query or mutation, or a batch of them", Tag: "graphql",
+			BodySchema: Schema{"oneOf": []Schema{ref("GraphQLRequest"), arrayOf("GraphQLRequest")}},
+			Responses: map[int]response{
+				200: jsonResponse("GraphQL result", Schema{"type": "object"}),
+				400: errorResponse("Invalid request body"),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/openapi.json", ID: "openapi", Summary: "This document", Tag: "docs", Public: true,
+			Responses: map[int]response{
+				200: jsonResponse("OpenAPI document", Schema{"type": "object"}),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/docs", ID: "docs", Summary: "Interactive API documentation", Tag: "docs", Public: true,
+			Responses: map[int]response{
+				200: {Description: "HTML page", ContentType: "text/html", Schema: Schema{"type": "string"}},
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/healthz", ID: "liveness", Summary: "Liveness probe", Tag: "health", Public: true,
+			Responses: map[int]response{
+				200: jsonResponse("The process is healthy", ref("HealthReport")),
+				503: jsonResponse("A liveness check failed; restart the process", ref("HealthReport")),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/readyz", ID: "readiness", Summary: "Readiness probe, checking every dependency", Tag: "health", Public: true,
+			Responses: map[int]response{
+				200: jsonResponse("Ready to serve requests", ref("HealthReport")),
+				503: jsonResponse("A dependency is failing or the server is draining", ref("HealthReport")),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/check-mysql", ID: "checkMySQL", Summary: "MySQL check of /readyz on its own; use /readyz instead", Tag: "health", Public: true, Deprecated: true,
+			Responses: map[int]response{
+				200: jsonResponse("MySQL is reachable", ref("HealthReport")),
+				404: errorResponse("The server does not use MySQL"),
+				503: jsonResponse("MySQL is not reachable", ref("HealthReport")),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/check-mongo", ID: "checkMongo", Summary: "MongoDB check of /readyz on its own; use /readyz instead", Tag: "health", Public: true, Deprecated: true,
+			Responses: map[int]response{
+				200: jsonResponse("MongoDB is reachable", ref("HealthReport")),
+				404: errorResponse("The server does not use MongoDB"),
+				503: jsonResponse("MongoDB is not reachable", ref("HealthReport")),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/metrics", ID: "metrics", Summary: "Prometheus metrics", Tag: "metrics", Public: true,
+			Responses: map[int]response{
+				200: {Description: "Metrics in the Prometheus text format", ContentType: "text/plain", Schema: Schema{"type": "string"}},
+			},
+		},
+	}
+}
+
+func components() Schema {
+	return Schema{
+		"ProductInput":    SchemaOf(rest.ProductRequest{}),
+		"ProductUpdate":   SchemaOf(rest.ProductUpdateRequest{}),
+		"Product":         SchemaOf(rest.ProductResponse{}),
+		"ProductPage":     SchemaOf(rest.ProductPageResponse{}),
+		"ProductSearch":   SchemaOf(rest.ProductSearchResponse{}),
+		"WebhookInput":    SchemaOf(rest.WebhookRequest{}),
+		"WebhookUpdate":   SchemaOf(rest.WebhookUpdateRequest{}),
+		"Webhook":         SchemaOf(rest.WebhookResponse{}),
+		"WebhookDelivery": SchemaOf(rest.WebhookDeliveryResponse{}),
+		"AuditRecord":     SchemaOf(rest.AuditRecordResponse{}),
+		"GraphQLRequest":  SchemaOf(GraphQLRequest{}),
+		"ErrorResponse":   SchemaOf(rest.ErrorResponse{}),
+		"MessageResponse": SchemaOf(rest.MessageResponse{}),
+		"Violation":       SchemaOf(rest.Violation{}),
+		"HealthReport":    SchemaOf(health.Report{}),
+	}
+}
+
+func operations() []operation {
+	var ops []operation
+	for _, version := range apiVersions {
+		for _, op := range apiOperations(version.Name) {
+			op.Path = "/api/" + version.Name + op.Path
+			op.ID += strings.ToUpper(version.Name)
+			op.Deprecated = version.Deprecated
+			ops = append(ops, op)
+		}
+	}
+	return append(ops, rootOperations()...)
+}
+
+// Document builds the OpenAPI 3 description of the REST API.
+func Document() Schema {
+	paths := Schema{}
+	for _, op := range operations() {
+		path := toOpenAPIPath(op.Path)
+		item, ok := paths[path].(Schema)
+		if !ok {
+			item = Schema{}
+			paths[path] = item
+		}
+		item[strings.ToLower(op.Method)] = op.build()
+	}
+
+	return Schema{
+		"openapi": "3.0.3",
+		"info": Schema{
+			"title":       "Go Hexagon Product API",
+			"version":     "2.0.0",
+			"description": "Product catalogue served by the hexagonal Go service. Errors are returned as {\"error\": \"message\"}; requests that do not match this document are rejected with 400 and a list of violations. REST routes live under /api/v1 (deprecated) and /api/v2; the unversioned paths are served by v1 unless the Accept header asks for application/vnd.go-hexagon.v2+json. Send a JWT as a bearer token or an API key in X-API-Key; viewers may read products, editors also create and update them, and only admins delete products or manage webhooks. Each client is rate limited separately for reads and writes, see the X-RateLimit-* response headers.",
+		},
+		"servers":  []Schema{{"url": "/"}},
+		"paths":    paths,
+		"security": []Schema{{"bearerAuth": []string{}}, {"apiKeyAuth": []string{}}},
+		"components": Schema{
+			"schemas": components(),
+			"securitySchemes": Schema{
+				"bearerAuth": Schema{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				"apiKeyAuth": Schema{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+			},
+		},
+	}
+}
+
+func (op operation) build() Schema {
+	result := Schema{
+		"operationId": op.ID,
+		"summary":     op.Summary,
+		"tags":        []string{op.Tag},
+	}
+	if op.Deprecated {
+		result["deprecated"] = true
+	}
+	if op.Public {
+		result["security"] = []Schema{}
+	}
+
+	if len(op.Params) > 0 {
+		var params []Schema
+		for _, p := range op.Params {
+			param := Schema{"name": p.Name, "in": p.In, "required": p.Required, "schema": p.Schema}
+			if p.Description != "" {
+				param["description"] = p.Description
+			}
+			params = append(params, param)
+		}
+		result["parameters"] = params
+	}
+
+	body := op.BodySchema
+	if op.Body != "" {
+		body = ref(op.Body)
+	}
+	if body != nil {
+		result["requestBody"] = Schema{
+			"required": true,
+			"content":  Schema{"application/json": Schema{"schema": body}},
+		}
+	}
+
+	responses := Schema{}
+	if _, ok := op.Responses[400]; !ok && (len(op.Params) > 0 || body != nil) {
+		responses["400"] = Schema{
+			"description": "Request validation failed",
+			"content":     Schema{"application/json": Schema{"schema": ref("ErrorResponse")}},
+		}
+	}
+	if !op.Public {
+		responses["401"] = Schema{
+			"description": "Missing or invalid credentials",
+			"content":     Schema{"application/json": Schema{"schema": ref("ErrorResponse")}},
+		}
+		// GraphQL reports denials as errors with the FORBIDDEN code instead.
+		if op.Tag != "graphql" {
+			responses["403"] = Schema{
+				"description": "The caller's roles do not allow this operation",
+				"content":     Schema{"application/json": Schema{"schema": ref("ErrorResponse")}},
+			}
+		}
+	}
+	responses["429"] = Schema{
+		"description": "Rate limit exceeded; retry after the number of seconds in Retry-After",
+		"content":     Schema{"application/json": Schema{"schema": ref("ErrorResponse")}},
+	}
+	for status, resp := range op.Responses {
+		built := Schema{"description": resp.Description}
+		if resp.ContentType != "" {
+			built["content"] = Schema{resp.ContentType: Schema{"schema": resp.Schema}}
+		}
+		responses[strconv.Itoa(status)] = built
+	}
+	result["responses"] = responses
+	return result
+}
+
+// toOpenAPIPath turns Fiber's /products/:id into /products/{id}.
+func toOpenAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,429 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-hexagon/internal/adapter/handler/rest"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ValidatorConfig struct {
+	MaxBodyBytes int
+}
+
+func DefaultValidatorConfig() ValidatorConfig {
+	return ValidatorConfig{MaxBodyBytes: 64 << 10}
+}
+
+type compiledRoute struct {
+	method   string
+	segments []string
+	literals int
+	op       map[string]interface{}
+}
+
+// Validator checks requests against the operations of an OpenAPI document
+// before they reach the handlers. Requests for paths that are not described
+// pass through untouched. Paths are matched case-insensitively, like Fiber's
+// router, so a request cannot skip validation by changing the case of a path
+// that still reaches the handler.
+type Validator struct {
+	config  ValidatorConfig
+	routes  []compiledRoute
+	schemas map[string]interface{}
+
+	mu       sync.Mutex
+	patterns map[string]*regexp.Regexp
+}
+
+func NewValidator(document Schema, config ValidatorConfig) (*Validator, error) {
+	raw, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	v := &Validator{
+		config:   config,
+		patterns: make(map[string]*regexp.Regexp),
+	}
+	if components, ok := doc["components"].(map[string]interface{}); ok {
+		v.schemas, _ = components["schemas"].(map[string]interface{})
+	}
+
+	paths, _ := doc["paths"].(map[string]interface{})
+	for path, item := range paths {
+		segments := strings.Split(strings.Trim(path, "/"), "/")
+		literals := 0
+		for _, segment := range segments {
+			if !strings.HasPrefix(segment, "{") {
+				literals++
+			}
+		}
+		for method, op := range item.(map[string]interface{}) {
+			v.routes = append(v.routes, compiledRoute{
+				method:   strings.ToUpper(method),
+				segments: segments,
+				literals: literals,
+				op:       op.(map[string]interface{}),
+			})
+		}
+	}
+	// Literal segments win over parameters, so /products/stream is matched
+	// before /products/{id}.
+	sort.SliceStable(v.routes, func(i, j int) bool {
+		return v.routes[i].literals > v.routes[j].literals
+	})
+	return v, nil
+}
+
+func (v *Validator) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		route, params := v.match(c.Method(), c.Path())
+		if route == nil {
+			return c.Next()
+		}
+
+		violations := v.validate(route, params, c)
+		if len(violations) > 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(rest.ErrorResponse{
+				Error:      "Request validation failed",
+				Violations: violations,
+			})
+		}
+		return c.Next()
+	}
+}
+
+func (v *Validator) match(method, path string) (*compiledRoute, map[string]string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := range v.routes {
+		route := &v.routes[i]
+		if route.method != method || len(route.segments) != len(segments) {
+			continue
+		}
+		params := map[string]string{}
+		matched := true
+		for j, segment := range route.segments {
+			if strings.HasPrefix(segment, "{") {
+				value, err := url.PathUnescape(segments[j])
+				if err != nil {
+					value = segments[j]
+				}
+				params[strings.Trim(segment, "{}")] = value
+			} else if !strings.EqualFold(segment, segments[j]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return route, params
+		}
+	}
+	return nil, nil
+}
+
+func (v *Validator) validate(route *compiledRoute, params map[string]string, c *fiber.Ctx) []rest.Violation {
+	var violations []rest.Violation
+
+	parameters, _ := route.op["parameters"].([]interface{})
+	for _, p := range parameters {
+		param := p.(map[string]interface{})
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		required, _ := param["required"].(bool)
+		schema, _ := param["schema"].(map[string]interface{})
+
+		var value string
+		var present bool
+		switch in {
+		case "path":
+			value, present = params[name]
+		case "query":
+			raw := c.Context().QueryArgs().Peek(name)
+			value, present = string(raw), raw != nil
+		case "header":
+			value = c.Get(name)
+			present = value != ""
+		}
+
+		if !present || (in != "path" && value == "") {
+			if required {
+				violations = append(violations, rest.Violation{Location: in, Field: name, Message: "is required"})
+			}
+			continue
+		}
+		v.validateValue(schema, coerce(schema, value), in, name, &violations)
+	}
+
+	if body, ok := route.op["requestBody"].(map[string]interface{}); ok {
+		v.validateBody(body, c, &violations)
+	}
+	return violations
+}
+
+func (v *Validator) validateBody(requestBody map[string]interface{}, c *fiber.Ctx, violations *[]rest.Violation) {
+	body := c.Body()
+	if v.config.MaxBodyBytes > 0 && len(body) > v.config.MaxBodyBytes {
+		*violations = append(*violations, rest.Violation{Location: "body", Message: fmt.Sprintf("must not exceed %d bytes", v.config.MaxBodyBytes)})
+		return
+	}
+
+	required, _ := requestBody["required"].(bool)
+	if len(bytes.TrimSpace(body)) == 0 {
+		if required {
+			*violations = append(*violations, rest.Violation{Location: "body", Message: "is required"})
+		}
+		return
+	}
+
+	content, _ := requestBody["content"].(map[string]interface{})
+	media, ok := content["application/json"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	if contentType := c.Get(fiber.HeaderContentType); !strings.HasPrefix(contentType, fiber.MIMEApplicationJSON) {
+		*violations = append(*violations, rest.Violation{Location: "header", Field: fiber.HeaderContentType, Message: "must be application/json"})
+		return
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		*violations = append(*violations, rest.Violation{Location: "body", Message: "must be valid JSON: " + err.Error()})
+		return
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		*violations = append(*violations, rest.Violation{Location: "body", Message: "must contain a single JSON value"})
+		return
+	}
+
+	schema, _ := media["schema"].(map[string]interface{})
+	v.validateValue(schema, value, "body", "", violations)
+}
+
+func (v *Validator) resolve(schema map[string]interface{}) map[string]interface{} {
+	for {
+		ref, ok := schema["$ref"].(string)
+		if !ok {
+			return schema
+		}
+		resolved, _ := v.schemas[strings.TrimPrefix(ref, "#/components/schemas/")].(map[string]interface{})
+		if resolved == nil {
+			return map[string]interface{}{}
+		}
+		schema = resolved
+	}
+}
+
+func (v *Validator) validateValue(schema map[string]interface{}, value interface{}, location, field string, violations *[]rest.Violation) {
+	schema = v.resolve(schema)
+	add := func(format string, args ...interface{}) {
+		*violations = append(*violations, rest.Violation{Location: location, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); !nullable && len(schema) > 0 {
+			add("must not be null")
+		}
+		return
+	}
+
+	if variants, ok := schema["oneOf"].([]interface{}); ok {
+		matches := 0
+		for _, variant := range variants {
+			var probe []rest.Violation
+			v.validateValue(variant.(map[string]interface{}), value, location, field, &probe)
+			if len(probe) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			add("must match exactly one of the allowed schemas")
+		}
+		return
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			add("must be an object")
+			return
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, present := object[name.(string)]; !present {
+					*violations = append(*violations, rest.Violation{Location: location, Field: join(field, name.(string)), Message: "is required"})
+				}
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			propertySchema, known := properties[name].(map[string]interface{})
+			if !known {
+				switch additional := schema["additionalProperties"].(type) {
+				case bool:
+					if !additional {
+						*violations = append(*violations, rest.Violation{Location: location, Field: join(field, name), Message: "is not a known field"})
+					}
+				case map[string]interface{}:
+					v.validateValue(additional, object[name], location, join(field, name), violations)
+				}
+				continue
+			}
+			if readOnly, _ := propertySchema["readOnly"].(bool); readOnly {
+				*violations = append(*violations, rest.Violation{Location: location, Field: join(field, name), Message: "is read-only"})
+				continue
+			}
+			v.validateValue(propertySchema, object[name], location, join(field, name), violations)
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			add("must be an array")
+			return
+		}
+		if min, ok := number(schema["minItems"]); ok && float64(len(items)) < min {
+			add("must contain at least %v items", min)
+		}
+		if max, ok := number(schema["maxItems"]); ok && float64(len(items)) > max {
+			add("must contain at most %v items", max)
+		}
+		itemSchema, _ := schema["items"].(map[string]interface{})
+		for i, item := range items {
+			v.validateValue(itemSchema, item, location, field+"["+strconv.Itoa(i)+"]", violations)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			add("must be a string")
+			return
+		}
+		length := float64(len([]rune(s)))
+		if min, ok := number(schema["minLength"]); ok && length < min {
+			add("must be at least %v characters", min)
+		}
+		if max, ok := number(schema["maxLength"]); ok && length > max {
+			add("must be at most %v characters", max)
+		}
+		if pattern, ok := schema["pattern"].(string); ok && !v.pattern(pattern).MatchString(s) {
+			add("must match %s", pattern)
+		}
+		if format, _ := schema["format"].(string); format == "uri" {
+			if u, err := url.Parse(s); err != nil || u.Scheme == "" || u.Host == "" {
+				add("must be an absolute URI")
+			}
+		}
+	case "integer", "number":
+		n, ok := numeric(value)
+		if !ok {
+			add("must be %s", article(schema["type"].(string)))
+			return
+		}
+		if schema["type"] == "integer" && n != float64(int64(n)) {
+			add("must be an integer")
+			return
+		}
+		if min, ok := number(schema["minimum"]); ok && n < min {
+			add("must be greater than or equal to %v", min)
+		}
+		if max, ok := number(schema["maximum"]); ok && n > max {
+			add("must be less than or equal to %v", max)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			add("must be a boolean")
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		for _, allowed := range enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				return
+			}
+		}
+		add("must be one of %v", enum)
+	}
+}
+
+func (v *Validator) pattern(expr string) *regexp.Regexp {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	re, ok := v.patterns[expr]
+	if !ok {
+		re = regexp.MustCompile(expr)
+		v.patterns[expr] = re
+	}
+	return re
+}
+
+// coerce converts a path, query or header value to the type its schema
+// expects, leaving it as a string when it does not parse.
+func coerce(schema map[string]interface{}, value string) interface{} {
+	switch schema["type"] {
+	case "integer":
+		// Handlers read these with strconv.Atoi, so 1e3 or 10.0 are no integers.
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return float64(n)
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func numeric(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func number(value interface{}) (float64, bool) {
+	n, ok := value.(float64)
+	return n, ok
+}
+
+func join(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func article(typ string) string {
+	if typ == "integer" {
+		return "an integer"
+	}
+	return "a " + typ
+}
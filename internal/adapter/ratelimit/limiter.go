@@ -0,0 +1,194 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/core/domain/entity"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	HeaderLimit     = "X-RateLimit-Limit"
+	HeaderRemaining = "X-RateLimit-Remaining"
+	HeaderReset     = "X-RateLimit-Reset"
+)
+
+// Limit is a token bucket: it holds up to Burst requests and refills at Rate
+// requests per second. A zero rate disables the limit.
+type Limit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// Result is the state of a bucket after a request took a token from it.
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is how long until the next token, when not allowed.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// result turns the tokens left in a bucket into a Result.
+func (l Limit) result(tokens float64, allowed bool) Result {
+	result := Result{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(l.Burst) - tokens) / l.Rate),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / l.Rate)
+	}
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Store keeps the buckets. Implementations must take the token atomically,
+// since several requests of a client may be served at once.
+type Store interface {
+	// Take removes a token from the bucket at key, creating a full bucket the
+	// first time the key is seen.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// ClientLimits overrides the read or write limit of one client.
+type ClientLimits struct {
+	Read  *Limit `json:"read,omitempty"`
+	Write *Limit `json:"write,omitempty"`
+}
+
+type Config struct {
+	// IP applies to all requests of an address, before authentication.
+	IP Limit `json:"ip"`
+	// Read applies to GET, HEAD and OPTIONS requests and Write to all others.
+	Read  Limit `json:"read"`
+	Write Limit `json:"write"`
+	// Clients is keyed by the principal's subject: the API key name or the
+	// JWT subject.
+	Clients map[string]ClientLimits `json:"clients"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		IP:    Limit{Rate: 100, Burst: 200},
+		Read:  Limit{Rate: 50, Burst: 100},
+		Write: Limit{Rate: 5, Burst: 20},
+	}
+}
+
+// LoadConfig reads a JSON file of the same shape as Config on top of
+// DefaultConfig.
+func LoadConfig(path string) (Config, error) {
+	config := DefaultConfig()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("parse rate limits %s: %w", path, err)
+	}
+
+	limits := map[string]*Limit{"ip": &config.IP, "read": &config.Read, "write": &config.Write}
+	for client, override := range config.Clients {
+		limits[client+" read"] = override.Read
+		limits[client+" write"] = override.Write
+	}
+	for name, limit := range limits {
+		if limit != nil && limit.Rate > 0 && limit.Burst < 1 {
+			return config, fmt.Errorf("rate limit %s: burst must be at least 1", name)
+		}
+	}
+	return config, nil
+}
+
+type Limiter struct {
+	config Config
+	store  Store
+}
+
+func New(config Config, store Store) *Limiter {
+	return &Limiter{config: config, store: store}
+}
+
+// Middleware limits each client separately for reads and writes. Clients are
+// identified by their principal, so it has to run after authentication, or
+// by IP address when the request is anonymous. If the store fails, requests
+// are let through.
+func (l *Limiter) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		client, subject := clientKey(c)
+		class, limit := l.limit(c.Method(), subject)
+		return l.take(c, client+":"+class, limit)
+	}
+}
+
+// IPMiddleware limits all requests of an IP address with the IP limit. It
+// runs before authentication, so requests rejected with 401 or 403 use up
+// the address's tokens too.
+func (l *Limiter) IPMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return l.take(c, "ip:"+c.IP()+":all", l.config.IP)
+	}
+}
+
+func (l *Limiter) take(c *fiber.Ctx, key string, limit Limit) error {
+	if limit.Rate <= 0 {
+		return c.Next()
+	}
+
+	result, err := l.store.Take(c.UserContext(), key, limit)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Rate limit store failed, allowing request", slog.String("error", err.Error()))
+		return c.Next()
+	}
+
+	c.Set(HeaderLimit, strconv.Itoa(limit.Burst))
+	c.Set(HeaderRemaining, strconv.Itoa(result.Remaining))
+	c.Set(HeaderReset, strconv.Itoa(ceilSeconds(result.Reset)))
+	if !result.Allowed {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		return c.Status(fiber.StatusTooManyRequests).JSON(rest.ErrorResponse{Error: "Rate limit exceeded"})
+	}
+	return c.Next()
+}
+
+func (l *Limiter) limit(method, subject string) (string, Limit) {
+	override := l.config.Clients[subject]
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		if override.Read != nil {
+			return "read", *override.Read
+		}
+		return "read", l.config.Read
+	default:
+		if override.Write != nil {
+			return "write", *override.Write
+		}
+		return "write", l.config.Write
+	}
+}
+
+// clientKey names the bucket owner and returns the subject used to look up
+// client overrides.
+func clientKey(c *fiber.Ctx) (string, string) {
+	if principal, ok := entity.PrincipalFrom(c.UserContext()); ok {
+		return principal.Method + ":" + principal.Subject, principal.Subject
+	}
+	return "ip:" + c.IP(), ""
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
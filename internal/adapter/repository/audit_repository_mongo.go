@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/port"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// auditDocument is the shape of documents in the audit_records collection.
+type auditDocument struct {
+	ID         string         `bson:"_id"`
+	Entity     string         `bson:"entity"`
+	EntityID   string         `bson:"entity_id"`
+	Action     string         `bson:"action"`
+	Principal  auditPrincipal `bson:"principal"`
+	Before     *auditProduct  `bson:"before,omitempty"`
+	After      *auditProduct  `bson:"after,omitempty"`
+	Changes    []auditChange  `bson:"changes"`
+	RequestID  string         `bson:"request_id,omitempty"`
+	ClientIP   string         `bson:"client_ip,omitempty"`
+	OccurredAt time.Time      `bson:"occurred_at"`
+}
+
+type auditPrincipal struct {
+	Subject string   `bson:"subject,omitempty"`
+	Roles   []string `bson:"roles,omitempty"`
+	Method  string   `bson:"method,omitempty"`
+}
+
+type AuditRepositoryMongo struct {
+	DB *mongo.Collection
+}
+
+func NewAuditRepositoryMongo(db *mongo.Database) port.AuditRepository {
+	return &AuditRepositoryMongo{DB: db.Collection("audit_records")}
+}
+
+func (r *AuditRepositoryMongo) Save(ctx context.Context, record *entity.AuditRecord) error {
+	document := auditDocument{
+		ID:         record.ID,
+		Entity:     record.Entity,
+		EntityID:   record.EntityID,
+		Action:     record.Action,
+		Principal:  auditPrincipal(record.Principal),
+		Before:     newAuditProduct(record.Before),
+		After:      newAuditProduct(record.After),
+		Changes:    newAuditChanges(record.Changes),
+		RequestID:  record.RequestID,
+		ClientIP:   record.ClientIP,
+		OccurredAt: record.OccurredAt,
+	}
+	_, err := r.DB.InsertOne(ctx, document)
+	return err
+}
+
+func (r *AuditRepositoryMongo) List(query entity.AuditQuery) ([]entity.AuditRecord, error) {
+	filter := bson.M{"entity": query.Entity}
+	if query.EntityID != "" {
+		filter["entity_id"] = query.EntityID
+	}
+	opts := options.Find().SetSort(bson.M{"occurred_at": -1}).SetLimit(int64(query.Limit))
+	cursor, err := r.DB.Find(context.Background(), filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var documents []auditDocument
+	if err := cursor.All(context.Background(), &documents); err != nil {
+		return nil, err
+	}
+
+	records := make([]entity.AuditRecord, 0, len(documents))
+	for _, document := range documents {
+		records = append(records, entity.AuditRecord{
+			ID:         document.ID,
+			Entity:     document.Entity,
+			EntityID:   document.EntityID,
+			Action:     document.Action,
+			Principal:  entity.Principal(document.Principal),
+			Before:     document.Before.toEntity(),
+			After:      document.After.toEntity(),
+			Changes:    toFieldChanges(document.Changes),
+			RequestID:  document.RequestID,
+			ClientIP:   document.ClientIP,
+			OccurredAt: document.OccurredAt,
+		})
+	}
+	return records, nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/port"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// auditModel is the row layout of the audit_records table. The principal's
+// roles, both product states and the diff are stored as JSON.
+type auditModel struct {
+	ID              string        `gorm:"primaryKey;column:id;size:32"`
+	Entity          string        `gorm:"column:entity;size:32;index:idx_audit_entity"`
+	EntityID        string        `gorm:"column:entity_id;size:64;index:idx_audit_entity"`
+	Action          string        `gorm:"column:action;size:16"`
+	PrincipalSub    string        `gorm:"column:principal_subject"`
+	PrincipalMethod string        `gorm:"column:principal_method;size:16"`
+	PrincipalRoles  []string      `gorm:"column:principal_roles;type:text;serializer:json"`
+	Before          *auditProduct `gorm:"column:before_state;type:text;serializer:json"`
+	After           *auditProduct `gorm:"column:after_state;type:text;serializer:json"`
+	Changes         []auditChange `gorm:"column:changes;type:text;serializer:json"`
+	RequestID       string        `gorm:"column:request_id;size:128"`
+	ClientIP        string        `gorm:"column:client_ip;size:64"`
+	OccurredAt      time.Time     `gorm:"column:occurred_at;index"`
+}
+
+// auditProduct and auditChange are the stored shapes of a product state and a
+// changed field, shared by the MySQL and MongoDB audit repositories.
+type auditProduct struct {
+	ID    string `json:"id" bson:"id"`
+	Name  string `json:"name" bson:"name"`
+	Stock int    `json:"stock" bson:"stock"`
+}
+
+type auditChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
+
+func newAuditProduct(product *entity.Product) *auditProduct {
+	if product == nil {
+		return nil
+	}
+	return &auditProduct{ID: product.ID, Name: product.Name, Stock: product.Stock}
+}
+
+func (p *auditProduct) toEntity() *entity.Product {
+	if p == nil {
+		return nil
+	}
+	return &entity.Product{ID: p.ID, Name: p.Name, Stock: p.Stock}
+}
+
+func newAuditChanges(changes []entity.FieldChange) []auditChange {
+	stored := make([]auditChange, 0, len(changes))
+	for _, change := range changes {
+		stored = append(stored, auditChange(change))
+	}
+	return stored
+}
+
+func toFieldChanges(stored []auditChange) []entity.FieldChange {
+	changes := make([]entity.FieldChange, 0, len(stored))
+	for _, change := range stored {
+		changes = append(changes, entity.FieldChange(change))
+	}
+	return changes
+}
+
+func (auditModel) TableName() string {
+	return "audit_records"
+}
+
+// MigrateAudit creates or updates the audit_records table.
+func MigrateAudit(db *gorm.DB) error {
+	return db.AutoMigrate(&auditModel{})
+}
+
+func newAuditModel(record *entity.AuditRecord) *auditModel {
+	return &auditModel{
+		ID:              record.ID,
+		Entity:          record.Entity,
+		EntityID:        record.EntityID,
+		Action:          record.Action,
+		PrincipalSub:    record.Principal.Subject,
+		PrincipalMethod: record.Principal.Method,
+		PrincipalRoles:  record.Principal.Roles,
+		Before:          newAuditProduct(record.Before),
+		After:           newAuditProduct(record.After),
+		Changes:         newAuditChanges(record.Changes),
+		RequestID:       record.RequestID,
+		ClientIP:        record.ClientIP,
+		OccurredAt:      record.OccurredAt,
+	}
+}
+
+func (m auditModel) toEntity() entity.AuditRecord {
+	return entity.AuditRecord{
+		ID:       m.ID,
+		Entity:   m.Entity,
+		EntityID: m.EntityID,
+		Action:   m.Action,
+		Principal: entity.Principal{
+			Subject: m.PrincipalSub,
+			Method:  m.PrincipalMethod,
+			Roles:   m.PrincipalRoles,
+		},
+		Before:     m.Before.toEntity(),
+		After:      m.After.toEntity(),
+		Changes:    toFieldChanges(m.Changes),
+		RequestID:  m.RequestID,
+		ClientIP:   m.ClientIP,
+		OccurredAt: m.OccurredAt,
+	}
+}
+
+type AuditRepositoryMySQL struct {
+	DB *gorm.DB
+}
+
+func NewAuditRepositoryMySQL(db *gorm.DB) port.AuditRepository {
+	return &AuditRepositoryMySQL{DB: db}
+}
+
+func (r *AuditRepositoryMySQL) Save(ctx context.Context, record *entity.AuditRecord) error {
+	return r.DB.WithContext(ctx).Create(newAuditModel(record)).Error
+}
+
+func (r *AuditRepositoryMySQL) List(query entity.AuditQuery) ([]entity.AuditRecord, error) {
+	db := r.DB.Where("entity = ?", query.Entity)
+	if query.EntityID != "" {
+		db = db.Where("entity_id = ?", query.EntityID)
+	}
+	var models []auditModel
+	if err := db.Order("occurred_at DESC").Limit(query.Limit).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	records := make([]entity.AuditRecord, 0, len(models))
+	for _, model := range models {
+		records = append(records, model.toEntity())
+	}
+	return records, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/port"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// idempotencyDocument is the shape of documents in the idempotency_keys
+// collection; the key is the document ID.
+type idempotencyDocument struct {
+	Key         string    `bson:"_id"`
+	RequestHash string    `bson:"request_hash"`
+	Completed   bool      `bson:"completed"`
+	StatusCode  int       `bson:"status_code,omitempty"`
+	ContentType string    `bson:"content_type,omitempty"`
+	Body        []byte    `bson:"body,omitempty"`
+	CreatedAt   time.Time `bson:"created_at"`
+	ExpiresAt   time.Time `bson:"expires_at"`
+}
+
+type IdempotencyStoreMongo struct {
+	DB *mongo.Collection
+}
+
+func NewIdempotencyStoreMongo(db *mongo.Database) port.IdempotencyStore {
+	return &IdempotencyStoreMongo{DB: db.Collection("idempotency_keys")}
+}
+
+func (s *IdempotencyStoreMongo) Reserve(record *entity.IdempotencyRecord) (*entity.IdempotencyRecord, bool, error) {
+	ctx := context.Background()
+	_, err := s.DB.InsertOne(ctx, idempotencyDocument(*record))
+	if err == nil {
+		return nil, true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, false, err
+	}
+
+	// The key is taken; an expired record may be replaced, atomically with
+	// the expiry check so two retries cannot both win.
+	result, err := s.DB.ReplaceOne(ctx,
+		bson.M{"_id": record.Key, "expires_at": bson.M{"$lte": time.Now()}},
+		idempotencyDocument(*record))
+	if err != nil {
+		return nil, false, err
+	}
+	if result.MatchedCount == 1 {
+		return nil, true, nil
+	}
+
+	var document idempotencyDocument
+	if err := s.DB.FindOne(ctx, bson.M{"_id": record.Key}).Decode(&document); err != nil {
+		return nil, false, err
+	}
+	existing := entity.IdempotencyRecord(document)
+	return &existing, false, nil
+}
+
+func (s *IdempotencyStoreMongo) Complete(record *entity.IdempotencyRecord) error {
+	_, err := s.DB.UpdateOne(context.Background(), bson.M{"_id": record.Key}, bson.M{"$set": bson.M{
+		"completed":    true,
+		"status_code":  record.StatusCode,
+		"content_type": record.ContentType,
+		"body":         record.Body,
+	}})
+	return err
+}
+
+func (s *IdempotencyStoreMongo) Release(key string) error {
+	_, err := s.DB.DeleteOne(context.Background(), bson.M{"_id": key, "completed": false})
+	return err
+}
+
+func (s *IdempotencyStoreMongo) DeleteExpired(now time.Time) error {
+	_, err := s.DB.DeleteMany(context.Background(), bson.M{"expires_at": bson.M{"$lt": now}})
+	return err
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"errors"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/port"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// idempotencyModel is the row layout of the idempotency_keys table. The size
+// of Body maps to MEDIUMBLOB on MySQL and to BYTEA on Postgres.
+type idempotencyModel struct {
+	Key         string    `gorm:"primaryKey;column:idempotency_key;size:512"`
+	RequestHash string    `gorm:"column:request_hash;size:64"`
+	Completed   bool      `gorm:"column:completed"`
+	StatusCode  int       `gorm:"column:status_code"`
+	ContentType string    `gorm:"column:content_type"`
+	Body        []byte    `gorm:"column:body;size:16777215"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+	ExpiresAt   time.Time `gorm:"column:expires_at;index"`
+}
+
+func (idempotencyModel) TableName() string {
+	return "idempotency_keys"
+}
+
+// MigrateIdempotencyKeys creates or updates the idempotency_keys table.
+func MigrateIdempotencyKeys(db *gorm.DB) error {
+	return db.AutoMigrate(&idempotencyModel{})
+}
+
+type IdempotencyStoreMySQL struct {
+	DB *gorm.DB
+}
+
+func NewIdempotencyStoreMySQL(db *gorm.DB) port.IdempotencyStore {
+	return &IdempotencyStoreMySQL{DB: db}
+}
+
+func (s *IdempotencyStoreMySQL) Reserve(record *entity.IdempotencyRecord) (*entity.IdempotencyRecord, bool, error) {
+	var existing *entity.IdempotencyRecord
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var model idempotencyModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("idempotency_key = ?", record.Key).First(&model).Error
+		switch {
+		case err == nil && model.ExpiresAt.After(time.Now()):
+			stored := entity.IdempotencyRecord(model)
+			existing = &stored
+			return nil
+		case err == nil:
+			return tx.Save((*idempotencyModel)(record)).Error
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return tx.Create((*idempotencyModel)(record)).Error
+		default:
+			return err
+		}
+	})
+	if err != nil {
+		// Another request may have inserted the key since the lookup.
+		var model idempotencyModel
+		if s.DB.Where("idempotency_key = ?", record.Key).First(&model).Error == nil {
+			stored := entity.IdempotencyRecord(model)
+			return &stored, false, nil
+		}
+		return nil, false, err
+	}
+	return existing, existing == nil, nil
+}
+
+func (s *IdempotencyStoreMySQL) Complete(record *entity.IdempotencyRecord) error {
+	return s.DB.Model(&idempotencyModel{}).Where("idempotency_key = ?", record.Key).Updates(map[string]interface{}{
+		"completed":    true,
+		"status_code":  record.StatusCode,
+		"content_type": record.ContentType,
+		"body":         record.Body,
+	}).Error
+}
+
+func (s *IdempotencyStoreMySQL) Release(key string) error {
+	return s.DB.Where("idempotency_key = ? AND completed = ?", key, false).Delete(&idempotencyModel{}).Error
+}
+
+func (s *IdempotencyStoreMySQL) DeleteExpired(now time.Time) error {
+	return s.DB.Where("expires_at < ?", now).Delete(&idempotencyModel{}).Error
+}
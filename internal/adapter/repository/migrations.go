@@ -0,0 +1,21 @@
+package repository
+
+import "gorm.io/gorm"
+
+// MigrateSQL runs the migration of every table the SQL adapters use, on
+// MySQL and Postgres alike. Each table's migration lives next to its model.
+func MigrateSQL(db *gorm.DB) error {
+	migrations := []func(*gorm.DB) error{
+		MigrateWebhooks,
+		MigrateAudit,
+		MigrateIdempotencyKeys,
+		MigrateProductIDMap,
+		MigrateProducts,
+	}
+	for _, migrate := range migrations {
+		if err := migrate(db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"sync"
+)
+
+// ProductIDMapMemory keeps the mapping in memory, for tests and one-off
+// tools whose mapping does not need to outlive them.
+type ProductIDMapMemory struct {
+	mu      sync.RWMutex
+	ids     map[string]string
+	reverse map[string]string
+}
+
+func NewProductIDMapMemory() *ProductIDMapMemory {
+	return &ProductIDMapMemory{ids: map[string]string{}, reverse: map[string]string{}}
+}
+
+func (m *ProductIDMapMemory) Save(ctx context.Context, ids map[string]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, otherID := range ids {
+		if previous, ok := m.ids[id]; ok {
+			delete(m.reverse, previous)
+		}
+		m.ids[id] = otherID
+		m.reverse[otherID] = id
+	}
+	return nil
+}
+
+func (m *ProductIDMapMemory) Lookup(ctx context.Context, ids []string) (map[string]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return pick(m.ids, ids), nil
+}
+
+func (m *ProductIDMapMemory) Reverse(ctx context.Context, otherIDs []string) (map[string]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return pick(m.reverse, otherIDs), nil
+}
+
+func (m *ProductIDMapMemory) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if otherID, ok := m.ids[id]; ok {
+		delete(m.reverse, otherID)
+		delete(m.ids, id)
+	}
+	return nil
+}
+
+func pick(values map[string]string, keys []string) map[string]string {
+	picked := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if value, ok := values[key]; ok {
+			picked[key] = value
+		}
+	}
+	return picked
+}
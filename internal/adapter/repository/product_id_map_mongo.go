@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"go-hexagon/internal/core/port"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// productIDMapDocument is the shape of documents in the product_id_map
+// collection; the ID is the document ID.
+type productIDMapDocument struct {
+	ID      string `bson:"_id"`
+	OtherID string `bson:"other_id"`
+}
+
+type ProductIDMapMongo struct {
+	DB *mongo.Collection
+}
+
+func NewProductIDMapMongo(db *mongo.Database) port.ProductIDMap {
+	return &ProductIDMapMongo{DB: db.Collection("product_id_map")}
+}
+
+// EnsureIndexes indexes the other IDs, which Reverse looks up.
+func (m *ProductIDMapMongo) EnsureIndexes(ctx context.Context) error {
+	_, err := m.DB.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "other_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+func (m *ProductIDMapMongo) Save(ctx context.Context, ids map[string]string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	models := make([]mongo.WriteModel, 0, len(ids))
+	for id, otherID := range ids {
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": id}).
+			SetReplacement(productIDMapDocument{ID: id, OtherID: otherID}).
+			SetUpsert(true))
+	}
+	_, err := m.DB.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func (m *ProductIDMapMongo) Lookup(ctx context.Context, ids []string) (map[string]string, error) {
+	// $in needs an array, which a nil slice is not encoded as.
+	if len(ids) == 0 {
+		return map[string]string{}, nil
+	}
+	documents, err := m.find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	mapped := make(map[string]string, len(documents))
+	for _, document := range documents {
+		mapped[document.ID] = document.OtherID
+	}
+	return mapped, nil
+}
+
+func (m *ProductIDMapMongo) Reverse(ctx context.Context, otherIDs []string) (map[string]string, error) {
+	if len(otherIDs) == 0 {
+		return map[string]string{}, nil
+	}
+	documents, err := m.find(ctx, bson.M{"other_id": bson.M{"$in": otherIDs}})
+	if err != nil {
+		return nil, err
+	}
+	mapped := make(map[string]string, len(documents))
+	for _, document := range documents {
+		mapped[document.OtherID] = document.ID
+	}
+	return mapped, nil
+}
+
+func (m *ProductIDMapMongo) Delete(ctx context.Context, id string) error {
+	_, err := m.DB.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (m *ProductIDMapMongo) find(ctx context.Context, filter bson.M) ([]productIDMapDocument, error) {
+	var documents []productIDMapDocument
+	cursor, err := m.DB.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
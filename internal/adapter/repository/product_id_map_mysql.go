@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"go-hexagon/internal/core/port"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// productIDMapModel is the row layout of the product_id_map table.
+type productIDMapModel struct {
+	ID      string `gorm:"primaryKey;column:id;size:64"`
+	OtherID string `gorm:"column:other_id;size:64;uniqueIndex"`
+}
+
+func (productIDMapModel) TableName() string {
+	return "product_id_map"
+}
+
+// MigrateProductIDMap creates or updates the product_id_map table.
+func MigrateProductIDMap(db *gorm.DB) error {
+	return db.AutoMigrate(&productIDMapModel{})
+}
+
+type ProductIDMapMySQL struct {
+	DB *gorm.DB
+}
+
+func NewProductIDMapMySQL(db *gorm.DB) port.ProductIDMap {
+	return &ProductIDMapMySQL{DB: db}
+}
+
+func (m *ProductIDMapMySQL) Save(ctx context.Context, ids map[string]string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	models := make([]productIDMapModel, 0, len(ids))
+	for id, otherID := range ids {
+		models = append(models, productIDMapModel{ID: id, OtherID: otherID})
+	}
+	return m.DB.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&models).Error
+}
+
+func (m *ProductIDMapMySQL) Lookup(ctx context.Context, ids []string) (map[string]string, error) {
+	var models []productIDMapModel
+	if len(ids) > 0 {
+		if err := m.DB.WithContext(ctx).Where("id IN ?", ids).Find(&models).Error; err != nil {
+			return nil, err
+		}
+	}
+	mapped := make(map[string]string, len(models))
+	for _, model := range models {
+		mapped[model.ID] = model.OtherID
+	}
+	return mapped, nil
+}
+
+func (m *ProductIDMapMySQL) Reverse(ctx context.Context, otherIDs []string) (map[string]string, error) {
+	var models []productIDMapModel
+	if len(otherIDs) > 0 {
+		if err := m.DB.WithContext(ctx).Where("other_id IN ?", otherIDs).Find(&models).Error; err != nil {
+			return nil, err
+		}
+	}
+	mapped := make(map[string]string, len(models))
+	for _, model := range models {
+		mapped[model.OtherID] = model.ID
+	}
+	return mapped, nil
+}
+
+func (m *ProductIDMapMySQL) Delete(ctx context.Context, id string) error {
+	return m.DB.WithContext(ctx).Delete(&productIDMapModel{ID: id}).Error
+}
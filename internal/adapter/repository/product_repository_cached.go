@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/port"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	cacheKeyProduct  = "product:"
+	cacheKeyProducts = "products:"
+)
+
+// CacheConfig sets how long lookups stay cached. Lists are invalidated as a
+// whole on every write, so they usually get a shorter TTL than single
+// products.
+type CacheConfig struct {
+	TTL     time.Duration
+	ListTTL time.Duration
+	// FillDelay is how long after a write lookups are not cached, so a read
+	// replica that has not applied the write yet cannot put the old row back.
+	FillDelay time.Duration
+}
+
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{TTL: time.Minute, ListTTL: 10 * time.Second}
+}
+
+// CachedProductRepository is a read-through cache in front of another
+// product repository. Products are stored encoded, so callers may modify
+// what they get back without touching the cache. Reads pinned to the primary
+// (see entity.ReadFromPrimary) bypass the cache in both directions.
+type CachedProductRepository struct {
+	Next   port.ProductRepository
+	Cache  port.Cache
+	Config CacheConfig
+
+	// mu orders filling the cache against invalidation: a lookup that raced
+	// with a write must not store what it read before the write.
+	mu         sync.Mutex
+	generation uint64
+	// fillAfter is when lookups may be cached again after the last write.
+	fillAfter time.Time
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func NewCachedProductRepository(next port.ProductRepository, cache port.Cache, config CacheConfig) *CachedProductRepository {
+	return &CachedProductRepository{Next: next, Cache: cache, Config: config}
+}
+
+func (r *CachedProductRepository) Stats() port.CacheStats {
+	return port.CacheStats{Hits: r.hits.Load(), Misses: r.misses.Load()}
+}
+
+func (r *CachedProductRepository) Create(ctx context.Context, product *entity.Product) error {
+	defer r.invalidate()
+	return r.Next.Create(ctx, product)
+}
+
+func (r *CachedProductRepository) Update(ctx context.Context, product *entity.Product) error {
+	defer r.invalidate(cacheKeyProduct + product.ID)
+	return r.Next.Update(ctx, product)
+}
+
+func (r *CachedProductRepository) Delete(ctx context.Context, id string) error {
+	defer r.invalidate(cacheKeyProduct + id)
+	return r.Next.Delete(ctx, id)
+}
+
+func (r *CachedProductRepository) GetByID(ctx context.Context, id string) (*entity.Product, error) {
+	if entity.ReadFromPrimary(ctx) {
+		return r.Next.GetByID(ctx, id)
+	}
+	var product entity.Product
+	if r.get(cacheKeyProduct+id, &product) {
+		return &product, nil
+	}
+
+	generation := r.currentGeneration()
+	found, err := r.Next.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.set(generation, r.Config.TTL, map[string]interface{}{cacheKeyProduct + id: found})
+	return found, nil
+}
+
+// GetByIDs serves the products it has cached and asks the next repository
+// for the rest only.
+func (r *CachedProductRepository) GetByIDs(ctx context.Context, ids []string) ([]entity.Product, error) {
+	if entity.ReadFromPrimary(ctx) {
+		return r.Next.GetByIDs(ctx, ids)
+	}
+	products := make([]entity.Product, 0, len(ids))
+	var missing []string
+	for _, id := range ids {
+		var product entity.Product
+		if r.get(cacheKeyProduct+id, &product) {
+			products = append(products, product)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return products, nil
+	}
+
+	generation := r.currentGeneration()
+	found, err := r.Next.GetByIDs(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{}, len(found))
+	for i := range found {
+		values[cacheKeyProduct+found[i].ID] = &found[i]
+	}
+	r.set(generation, r.Config.TTL, values)
+	return append(products, found...), nil
+}
+
+func (r *CachedProductRepository) List(ctx context.Context) ([]entity.Product, error) {
+	if entity.ReadFromPrimary(ctx) {
+		return r.Next.List(ctx)
+	}
+	key := cacheKeyProducts + "all"
+	var products []entity.Product
+	if r.get(key, &products) {
+		return products, nil
+	}
+
+	generation := r.currentGeneration()
+	products, err := r.Next.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.set(generation, r.Config.ListTTL, map[string]interface{}{key: products})
+	return products, nil
+}
+
+func (r *CachedProductRepository) ListPage(ctx context.Context, query entity.ProductQuery) (*entity.ProductPage, error) {
+	if entity.ReadFromPrimary(ctx) {
+		return r.Next.ListPage(ctx, query)
+	}
+	key := cacheKeyProducts + pageCacheKey(query)
+	var page entity.ProductPage
+	if r.get(key, &page) {
+		return &page, nil
+	}
+
+	generation := r.currentGeneration()
+	found, err := r.Next.ListPage(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	r.set(generation, r.Config.ListTTL, map[string]interface{}{key: found})
+	return found, nil
+}
+
+func (r *CachedProductRepository) get(key string, value interface{}) bool {
+	data, ok := r.Cache.Get(key)
+	if ok && json.Unmarshal(data, value) == nil {
+		r.hits.Add(1)
+		return true
+	}
+	r.misses.Add(1)
+	return false
+}
+
+func (r *CachedProductRepository) currentGeneration() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.generation
+}
+
+// set stores values unless a write happened since generation was read or
+// within the last FillDelay.
+func (r *CachedProductRepository) set(generation uint64, ttl time.Duration, values map[string]interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if generation != r.generation || time.Now().Before(r.fillAfter) {
+		return
+	}
+	for key, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		r.Cache.Set(key, data, ttl)
+	}
+}
+
+// invalidate drops the given keys and every cached list. It runs whether or
+// not the write succeeded, since a failed write may still have applied.
+func (r *CachedProductRepository) invalidate(keys ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.generation++
+	r.fillAfter = time.Now().Add(r.Config.FillDelay)
+	r.Cache.Delete(keys...)
+	r.Cache.DeletePrefix(cacheKeyProducts)
+}
+
+func pageCacheKey(query entity.ProductQuery) string {
+	bound := func(value *int) string {
+		if value == nil {
+			return "-"
+		}
+		return fmt.Sprint(*value)
+	}
+	return fmt.Sprintf("page:%d:%d:%s:%s:%q",
+		query.Offset, query.Limit, bound(query.MinStock), bound(query.MaxStock), query.NameContains)
+}
@@ -0,0 +1,340 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/port"
+	"log/slog"
+)
+
+// Sides of a DualWriteProductRepository reads can be served from.
+const (
+	SidePrimary   = "primary"
+	SideSecondary = "secondary"
+)
+
+// Kinds of ProductDivergence.
+const (
+	// DivergenceWriteFailed is a write that reached the primary but not the
+	// secondary, or whose ID mapping could not be saved.
+	DivergenceWriteFailed = "write_failed"
+	// DivergenceMissing is a product that exists on the primary only.
+	DivergenceMissing = "missing"
+	// DivergenceMismatch is a product whose fields differ between the sides.
+	DivergenceMismatch = "mismatch"
+	// DivergenceUnmapped is a product on the secondary without a primary ID.
+	DivergenceUnmapped = "unmapped"
+)
+
+// ProductDivergence describes a way the secondary store no longer mirrors
+// the primary. ID is the primary ID of the product.
+type ProductDivergence struct {
+	Kind        string
+	Operation   string
+	ID          string
+	SecondaryID string
+	Detail      string
+}
+
+type DualWriteConfig struct {
+	// ReadFrom is SidePrimary or SideSecondary.
+	ReadFrom string
+	// CompareReads also reads GetByID from the other side and reports any
+	// difference, at the cost of a second query.
+	CompareReads bool
+}
+
+// DualWriteProductRepository mirrors the writes to Primary on Secondary, to
+// move between backends without downtime. The primary is the source of
+// truth: its IDs are the ones clients see and only its errors are returned.
+// Failed writes to the secondary are reported as divergences instead, and
+// updates recreate products missing on the secondary.
+type DualWriteProductRepository struct {
+	Primary   port.ProductRepository
+	Secondary port.ProductRepository
+	// IDs maps primary IDs to secondary IDs.
+	IDs    port.ProductIDMap
+	Config DualWriteConfig
+	Logger *slog.Logger
+	// OnDivergence, when set, is called for every divergence after it has
+	// been logged, e.g. to count it for alerting.
+	OnDivergence func(ctx context.Context, divergence ProductDivergence)
+}
+
+func NewDualWriteProductRepository(primary, secondary port.ProductRepository, ids port.ProductIDMap, config DualWriteConfig) *DualWriteProductRepository {
+	return &DualWriteProductRepository{Primary: primary, Secondary: secondary, IDs: ids, Config: config, Logger: slog.Default()}
+}
+
+func (r *DualWriteProductRepository) Create(ctx context.Context, product *entity.Product) error {
+	if err := r.Primary.Create(ctx, product); err != nil {
+		return err
+	}
+	r.createSecondary(ctx, "Create", *product)
+	return nil
+}
+
+func (r *DualWriteProductRepository) Update(ctx context.Context, product *entity.Product) error {
+	if err := r.Primary.Update(ctx, product); err != nil {
+		return err
+	}
+
+	secondaryID, err := r.secondaryID(ctx, product.ID)
+	if err != nil {
+		r.diverged(ctx, ProductDivergence{Kind: DivergenceWriteFailed, Operation: "Update", ID: product.ID, Detail: err.Error()})
+		return nil
+	}
+	if secondaryID == "" {
+		r.diverged(ctx, ProductDivergence{Kind: DivergenceMissing, Operation: "Update", ID: product.ID, Detail: "not mapped to the secondary, copying it"})
+		r.createSecondary(ctx, "Update", *product)
+		return nil
+	}
+
+	mirror := *product
+	mirror.ID = secondaryID
+	err = r.Secondary.Update(ctx, &mirror)
+	switch {
+	case errors.Is(err, entity.ErrProductNotFound):
+		r.diverged(ctx, ProductDivergence{Kind: DivergenceMissing, Operation: "Update", ID: product.ID, SecondaryID: secondaryID, Detail: "not found on the secondary, copying it"})
+		r.createSecondary(ctx, "Update", *product)
+	case err != nil:
+		r.diverged(ctx, ProductDivergence{Kind: DivergenceWriteFailed, Operation: "Update", ID: product.ID, SecondaryID: secondaryID, Detail: err.Error()})
+	}
+	return nil
+}
+
+func (r *DualWriteProductRepository) Delete(ctx context.Context, id string) error {
+	if err := r.Primary.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	secondaryID, err := r.secondaryID(ctx, id)
+	if err != nil {
+		r.diverged(ctx, ProductDivergence{Kind: DivergenceWriteFailed, Operation: "Delete", ID: id, Detail: err.Error()})
+		return nil
+	}
+	if secondaryID == "" {
+		r.diverged(ctx, ProductDivergence{Kind: DivergenceMissing, Operation: "Delete", ID: id, Detail: "not mapped to the secondary"})
+		return nil
+	}
+	err = r.Secondary.Delete(ctx, secondaryID)
+	if err != nil && !errors.Is(err, entity.ErrProductNotFound) {
+		// The mapping is kept so the product can still be found and removed.
+		r.diverged(ctx, ProductDivergence{Kind: DivergenceWriteFailed, Operation: "Delete", ID: id, SecondaryID: secondaryID, Detail: err.Error()})
+		return nil
+	}
+	if err := r.IDs.Delete(ctx, id); err != nil {
+		r.diverged(ctx, ProductDivergence{Kind: DivergenceWriteFailed, Operation: "Delete", ID: id, SecondaryID: secondaryID, Detail: "delete ID mapping: " + err.Error()})
+	}
+	return nil
+}
+
+func (r *DualWriteProductRepository) GetByID(ctx context.Context, id string) (*entity.Product, error) {
+	if r.Config.ReadFrom != SideSecondary {
+		product, err := r.Primary.GetByID(ctx, id)
+		if err == nil && r.Config.CompareReads {
+			r.compareSecondary(ctx, product)
+		}
+		return product, err
+	}
+
+	// Without the mapping the product is read from the primary.
+	secondaryID, err := r.secondaryID(ctx, id)
+	if err != nil {
+		return r.Primary.GetByID(ctx, id)
+	}
+	if secondaryID != "" {
+		product, err := r.Secondary.GetByID(ctx, secondaryID)
+		if err == nil {
+			product.ID = id
+			if r.Config.CompareReads {
+				r.comparePrimary(ctx, product, secondaryID)
+			}
+			return product, nil
+		}
+		if !errors.Is(err, entity.ErrProductNotFound) {
+			return nil, err
+		}
+	}
+
+	// Products the secondary lacks are read from the primary, which also
+	// tells whether the product exists at all.
+	product, err := r.Primary.GetByID(ctx, id)
+	if err == nil {
+		r.diverged(ctx, ProductDivergence{Kind: DivergenceMissing, Operation: "GetByID", ID: id, SecondaryID: secondaryID, Detail: "read from the primary instead"})
+	}
+	return product, err
+}
+
+func (r *DualWriteProductRepository) GetByIDs(ctx context.Context, ids []string) ([]entity.Product, error) {
+	if r.Config.ReadFrom != SideSecondary {
+		return r.Primary.GetByIDs(ctx, ids)
+	}
+
+	mapped, err := r.IDs.Lookup(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	secondaryIDs := make([]string, 0, len(mapped))
+	primaryIDs := make(map[string]string, len(mapped))
+	for id, secondaryID := range mapped {
+		secondaryIDs = append(secondaryIDs, secondaryID)
+		primaryIDs[secondaryID] = id
+	}
+	products, err := r.Secondary.GetByIDs(ctx, secondaryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(products))
+	for i := range products {
+		products[i].ID = primaryIDs[products[i].ID]
+		found[products[i].ID] = true
+	}
+	var missing []string
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return products, nil
+	}
+
+	fallback, err := r.Primary.GetByIDs(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range fallback {
+		r.diverged(ctx, ProductDivergence{Kind: DivergenceMissing, Operation: "GetByIDs", ID: product.ID, Detail: "read from the primary instead"})
+	}
+	return append(products, fallback...), nil
+}
+
+func (r *DualWriteProductRepository) List(ctx context.Context) ([]entity.Product, error) {
+	if r.Config.ReadFrom != SideSecondary {
+		return r.Primary.List(ctx)
+	}
+	products, err := r.Secondary.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.toPrimaryIDs(ctx, "List", products)
+}
+
+// ListPage drops products the secondary has without a primary ID from the
+// page, so such a page is shorter than the limit and the total too high.
+func (r *DualWriteProductRepository) ListPage(ctx context.Context, query entity.ProductQuery) (*entity.ProductPage, error) {
+	if r.Config.ReadFrom != SideSecondary {
+		return r.Primary.ListPage(ctx, query)
+	}
+	page, err := r.Secondary.ListPage(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if page.Products, err = r.toPrimaryIDs(ctx, "ListPage", page.Products); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// createSecondary copies product, which has its primary ID, to the secondary
+// and maps the IDs.
+func (r *DualWriteProductRepository) createSecondary(ctx context.Context, operation string, product entity.Product) {
+	id := product.ID
+	product.ID = ""
+	if err := r.Secondary.Create(ctx, &product); err != nil {
+		r.diverged(ctx, ProductDivergence{Kind: DivergenceWriteFailed, Operation: operation, ID: id, Detail: err.Error()})
+		return
+	}
+	if err := r.IDs.Save(ctx, map[string]string{id: product.ID}); err != nil {
+		r.diverged(ctx, ProductDivergence{Kind: DivergenceWriteFailed, Operation: operation, ID: id, SecondaryID: product.ID, Detail: "save ID mapping: " + err.Error()})
+	}
+}
+
+// secondaryID returns the secondary ID of id, or "" if it has none.
+func (r *DualWriteProductRepository) secondaryID(ctx context.Context, id string) (string, error) {
+	mapped, err := r.IDs.Lookup(ctx, []string{id})
+	if err != nil {
+		return "", fmt.Errorf("look up ID mapping: %w", err)
+	}
+	return mapped[id], nil
+}
+
+func (r *DualWriteProductRepository) toPrimaryIDs(ctx context.Context, operation string, products []entity.Product) ([]entity.Product, error) {
+	secondaryIDs := make([]string, 0, len(products))
+	for _, product := range products {
+		secondaryIDs = append(secondaryIDs, product.ID)
+	}
+	mapped, err := r.IDs.Reverse(ctx, secondaryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	translated := make([]entity.Product, 0, len(products))
+	for _, product := range products {
+		id, ok := mapped[product.ID]
+		if !ok {
+			r.diverged(ctx, ProductDivergence{Kind: DivergenceUnmapped, Operation: operation, SecondaryID: product.ID, Detail: "left out of the result"})
+			continue
+		}
+		product.ID = id
+		translated = append(translated, product)
+	}
+	return translated, nil
+}
+
+func (r *DualWriteProductRepository) compareSecondary(ctx context.Context, primary *entity.Product) {
+	secondaryID, err := r.secondaryID(ctx, primary.ID)
+	if err != nil {
+		return
+	}
+	if secondaryID == "" {
+		r.diverged(ctx, ProductDivergence{Kind: DivergenceMissing, Operation: "GetByID", ID: primary.ID, Detail: "not mapped to the secondary"})
+		return
+	}
+	secondary, err := r.Secondary.GetByID(ctx, secondaryID)
+	if err != nil {
+		r.diverged(ctx, ProductDivergence{Kind: DivergenceMissing, Operation: "GetByID", ID: primary.ID, SecondaryID: secondaryID, Detail: err.Error()})
+		return
+	}
+	r.compare(ctx, primary, secondary, secondaryID)
+}
+
+func (r *DualWriteProductRepository) comparePrimary(ctx context.Context, secondary *entity.Product, secondaryID string) {
+	primary, err := r.Primary.GetByID(ctx, secondary.ID)
+	if err != nil {
+		r.diverged(ctx, ProductDivergence{Kind: DivergenceUnmapped, Operation: "GetByID", ID: secondary.ID, SecondaryID: secondaryID, Detail: "primary: " + err.Error()})
+		return
+	}
+	r.compare(ctx, primary, secondary, secondaryID)
+}
+
+// compare reports differing fields. UpdatedAt is set by each backend on its
+// own and is not compared.
+func (r *DualWriteProductRepository) compare(ctx context.Context, primary, secondary *entity.Product, secondaryID string) {
+	var detail string
+	switch {
+	case primary.Name != secondary.Name:
+		detail = fmt.Sprintf("name %q != %q", primary.Name, secondary.Name)
+	case primary.Stock != secondary.Stock:
+		detail = fmt.Sprintf("stock %d != %d", primary.Stock, secondary.Stock)
+	default:
+		return
+	}
+	r.diverged(ctx, ProductDivergence{Kind: DivergenceMismatch, Operation: "GetByID", ID: primary.ID, SecondaryID: secondaryID, Detail: detail})
+}
+
+func (r *DualWriteProductRepository) diverged(ctx context.Context, divergence ProductDivergence) {
+	r.Logger.ErrorContext(ctx, "Product stores diverged",
+		slog.String("kind", divergence.Kind),
+		slog.String("operation", divergence.Operation),
+		slog.String("id", divergence.ID),
+		slog.String("secondary_id", divergence.SecondaryID),
+		slog.String("detail", divergence.Detail),
+	)
+	if r.OnDivergence != nil {
+		r.OnDivergence(ctx, divergence)
+	}
+}
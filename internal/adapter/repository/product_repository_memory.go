@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"go-hexagon/internal/core/domain/entity"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ProductRepositoryMemory keeps products in memory, for tests and tools.
+// IDs are decimal numbers like those of the SQL backends, and lists are
+// ordered by ID. Names are indexed for Search as they are written.
+type ProductRepositoryMemory struct {
+	mu       sync.RWMutex
+	nextID   uint64
+	products map[uint64]entity.Product
+	index    *searchIndex
+}
+
+func NewProductRepositoryMemory() *ProductRepositoryMemory {
+	return &ProductRepositoryMemory{products: map[uint64]entity.Product{}, index: newSearchIndex()}
+}
+
+func (r *ProductRepositoryMemory) Create(ctx context.Context, product *entity.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.create(product)
+	return nil
+}
+
+func (r *ProductRepositoryMemory) CreateMany(ctx context.Context, products []entity.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range products {
+		r.create(&products[i])
+	}
+	return nil
+}
+
+func (r *ProductRepositoryMemory) create(product *entity.Product) {
+	r.nextID++
+	product.ID = strconv.FormatUint(r.nextID, 10)
+	product.UpdatedAt = productTimestamp()
+	r.products[r.nextID] = *product
+	r.index.add(r.nextID, *product)
+}
+
+func (r *ProductRepositoryMemory) Update(ctx context.Context, product *entity.Product) error {
+	key, err := parseMemoryID(product.ID)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	previous, ok := r.products[key]
+	if !ok {
+		return entity.ErrProductNotFound
+	}
+	product.UpdatedAt = productTimestamp()
+	r.products[key] = *product
+	r.index.remove(key, previous)
+	r.index.add(key, *product)
+	return nil
+}
+
+func (r *ProductRepositoryMemory) GetByID(ctx context.Context, id string) (*entity.Product, error) {
+	key, err := parseMemoryID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	product, ok := r.products[key]
+	if !ok {
+		return nil, entity.ErrProductNotFound
+	}
+	return &product, nil
+}
+
+func (r *ProductRepositoryMemory) GetByIDs(ctx context.Context, ids []string) ([]entity.Product, error) {
+	keys := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		key, err := parseMemoryID(id)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var products []entity.Product
+	for _, key := range keys {
+		if product, ok := r.products[key]; ok {
+			products = append(products, product)
+		}
+	}
+	return products, nil
+}
+
+func (r *ProductRepositoryMemory) List(ctx context.Context) ([]entity.Product, error) {
+	return r.filter(entity.ProductQuery{}), nil
+}
+
+func (r *ProductRepositoryMemory) ListPage(ctx context.Context, query entity.ProductQuery) (*entity.ProductPage, error) {
+	products := r.filter(query)
+	page := &entity.ProductPage{Offset: query.Offset, Limit: query.Limit, Total: int64(len(products))}
+	if query.Offset < len(products) {
+		page.Products = products[query.Offset:min(query.Offset+query.Limit, len(products))]
+	} else {
+		page.Products = []entity.Product{}
+	}
+	return page, nil
+}
+
+func (r *ProductRepositoryMemory) filter(query entity.ProductQuery) []entity.Product {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := make([]uint64, 0, len(r.products))
+	for key := range r.products {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	name := strings.ToLower(query.NameContains)
+	products := make([]entity.Product, 0, len(keys))
+	for _, key := range keys {
+		product := r.products[key]
+		if name != "" && !strings.Contains(strings.ToLower(product.Name), name) {
+			continue
+		}
+		if query.MinStock != nil && product.Stock < *query.MinStock {
+			continue
+		}
+		if query.MaxStock != nil && product.Stock > *query.MaxStock {
+			continue
+		}
+		products = append(products, product)
+	}
+	return products
+}
+
+func (r *ProductRepositoryMemory) Delete(ctx context.Context, id string) error {
+	key, err := parseMemoryID(id)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	product, ok := r.products[key]
+	if !ok {
+		return entity.ErrProductNotFound
+	}
+	delete(r.products, key)
+	r.index.remove(key, product)
+	return nil
+}
+
+func (r *ProductRepositoryMemory) Search(ctx context.Context, search entity.ProductSearch) (*entity.ProductSearchResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	matches := r.index.search(entity.SearchTerms(search.Text))
+	result := &entity.ProductSearchResult{Hits: []entity.ProductHit{}, Total: int64(len(matches)), Offset: search.Offset, Limit: search.Limit}
+	if search.Offset < len(matches) {
+		for _, match := range matches[search.Offset:min(search.Offset+search.Limit, len(matches))] {
+			result.Hits = append(result.Hits, entity.ProductHit{Product: r.products[match.key], Score: match.score})
+		}
+	}
+	return result, nil
+}
+
+func parseMemoryID(id string) (uint64, error) {
+	value, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, entity.ErrInvalidID
+	}
+	return value, nil
+}
@@ -2,15 +2,29 @@ package repository
 
 import (
 	"context"
-	"fmt"
 	"go-hexagon/internal/core/domain/entity"
 	"go-hexagon/internal/core/port"
+	"regexp"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// productDocument is the shape of a document in the products collection.
+type productDocument struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Name      string             `bson:"name"`
+	Stock     int                `bson:"stock"`
+	UpdatedAt time.Time          `bson:"updated_at,omitempty"`
+}
+
+func (d productDocument) toEntity() entity.Product {
+	return entity.Product{ID: d.ID.Hex(), Name: d.Name, Stock: d.Stock, UpdatedAt: d.UpdatedAt}
+}
+
 type ProductRepositoryMongo struct {
 	DB *mongo.Collection
 }
@@ -19,62 +33,161 @@ func NewProductRepositoryMongo(db *mongo.Database) port.ProductRepository {
 	return &ProductRepositoryMongo{DB: db.Collection("products")}
 }
 
-func (r *ProductRepositoryMongo) Create(product *entity.Product) error {
-	product.MongoID = primitive.NewObjectID()
-	_, err := r.DB.InsertOne(context.Background(), product)
-	return err
+func (r *ProductRepositoryMongo) Create(ctx context.Context, product *entity.Product) error {
+	document := productDocument{ID: primitive.NewObjectID(), Name: product.Name, Stock: product.Stock, UpdatedAt: productTimestamp()}
+	if _, err := r.DB.InsertOne(ctx, document); err != nil {
+		return err
+	}
+	*product = document.toEntity()
+	return nil
 }
 
-func (r *ProductRepositoryMongo) Update(product *entity.Product) error {
-	filter := bson.M{"_id": product.MongoID}
-	update := bson.M{"$set": product}
-	result, err := r.DB.UpdateOne(context.Background(), filter, update)
+func (r *ProductRepositoryMongo) CreateMany(ctx context.Context, products []entity.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	updatedAt := productTimestamp()
+	documents := make([]interface{}, 0, len(products))
+	for _, product := range products {
+		documents = append(documents, productDocument{ID: primitive.NewObjectID(), Name: product.Name, Stock: product.Stock, UpdatedAt: updatedAt})
+	}
+	if _, err := r.DB.InsertMany(ctx, documents); err != nil {
+		return err
+	}
+	for i, document := range documents {
+		products[i] = document.(productDocument).toEntity()
+	}
+	return nil
+}
+
+func (r *ProductRepositoryMongo) Update(ctx context.Context, product *entity.Product) error {
+	objectID, err := parseMongoID(product.ID)
+	if err != nil {
+		return err
+	}
+
+	updatedAt := productTimestamp()
+	filter := bson.M{"_id": objectID}
+	update := bson.M{"$set": bson.M{"name": product.Name, "stock": product.Stock, "updated_at": updatedAt}}
+	result, err := r.DB.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
 	if result.MatchedCount == 0 {
-		return fmt.Errorf("ID Not Found")
+		return entity.ErrProductNotFound
 	}
+	product.UpdatedAt = updatedAt
 	return nil
 }
 
-func (r *ProductRepositoryMongo) GetByID(id interface{}) (*entity.Product, error) {
-	var product entity.Product
-	if idObj, ok := id.(primitive.ObjectID); ok {
-		filter := bson.M{"_id": idObj}
-		err := r.DB.FindOne(context.Background(), filter).Decode(&product)
-		return &product, err
+func (r *ProductRepositoryMongo) GetByID(ctx context.Context, id string) (*entity.Product, error) {
+	objectID, err := parseMongoID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var document productDocument
+	if err := r.DB.FindOne(ctx, bson.M{"_id": objectID}).Decode(&document); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, entity.ErrProductNotFound
+		}
+		return nil, err
+	}
+	product := document.toEntity()
+	return &product, nil
+}
+
+func (r *ProductRepositoryMongo) GetByIDs(ctx context.Context, ids []string) ([]entity.Product, error) {
+	keys := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := parseMongoID(id)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, objectID)
+	}
+
+	if len(keys) == 0 {
+		return nil, nil
 	}
-	return nil, fmt.Errorf("Invalid ID type for MongoDB")
+	return r.find(ctx, bson.M{"_id": bson.M{"$in": keys}})
 }
 
-func (r *ProductRepositoryMongo) List() ([]entity.Product, error) {
-	var products []entity.Product
-	cursor, err := r.DB.Find(context.Background(), bson.D{})
+func (r *ProductRepositoryMongo) List(ctx context.Context) ([]entity.Product, error) {
+	return r.find(ctx, bson.D{})
+}
+
+func (r *ProductRepositoryMongo) ListPage(ctx context.Context, query entity.ProductQuery) (*entity.ProductPage, error) {
+	page := &entity.ProductPage{Offset: query.Offset, Limit: query.Limit}
+	filter := bson.M{}
+	if query.NameContains != "" {
+		filter["name"] = bson.M{"$regex": regexp.QuoteMeta(query.NameContains), "$options": "i"}
+	}
+	stock := bson.M{}
+	if query.MinStock != nil {
+		stock["$gte"] = *query.MinStock
+	}
+	if query.MaxStock != nil {
+		stock["$lte"] = *query.MaxStock
+	}
+	if len(stock) > 0 {
+		filter["stock"] = stock
+	}
+
+	total, err := r.DB.CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
-	if err := cursor.All(context.Background(), &products); err != nil {
+	page.Total = total
+
+	opts := options.Find().SetSort(bson.M{"_id": 1}).SetSkip(int64(query.Offset)).SetLimit(int64(query.Limit))
+	products, err := r.find(ctx, filter, opts)
+	if err != nil {
 		return nil, err
 	}
-	return products, nil
+	page.Products = products
+	return page, nil
 }
 
-func (r *ProductRepositoryMongo) Delete(id interface{}) error {
-	objectID, ok := id.(primitive.ObjectID)
-	if !ok {
-		return fmt.Errorf("Invalid ID type for MongoDB")
+func (r *ProductRepositoryMongo) Delete(ctx context.Context, id string) error {
+	objectID, err := parseMongoID(id)
+	if err != nil {
+		return err
 	}
 
 	filter := bson.M{"_id": objectID}
-	res := r.DB.FindOne(context.Background(), filter)
+	res := r.DB.FindOne(ctx, filter)
 	if res.Err() != nil {
 		if res.Err() == mongo.ErrNoDocuments {
-			return fmt.Errorf("ID Not Found")
+			return entity.ErrProductNotFound
 		}
 		return res.Err()
 	}
 
-	_, err := r.DB.DeleteOne(context.Background(), filter)
+	_, err = r.DB.DeleteOne(ctx, filter)
 	return err
 }
+
+func (r *ProductRepositoryMongo) find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) ([]entity.Product, error) {
+	cursor, err := r.DB.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var documents []productDocument
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+	products := make([]entity.Product, 0, len(documents))
+	for _, document := range documents {
+		products = append(products, document.toEntity())
+	}
+	return products, nil
+}
+
+func parseMongoID(id string) (primitive.ObjectID, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, entity.ErrInvalidID
+	}
+	return objectID, nil
+}
@@ -1,68 +1,285 @@
 package repository
 
 import (
-	"fmt"
+	"context"
+	"go-hexagon/internal/adapter/database"
 	"go-hexagon/internal/core/domain/entity"
 	"go-hexagon/internal/core/port"
+	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// productModel is the row layout of the products table.
+type productModel struct {
+	ID    uint   `gorm:"primaryKey;autoIncrement;column:id"`
+	Name  string `gorm:"column:name"`
+	Stock int    `gorm:"column:stock"`
+	// UpdatedAt is set by the repository rather than GORM, so the entity
+	// gets the exact value that is stored. It is NULL for older rows.
+	UpdatedAt *time.Time `gorm:"column:updated_at;autoUpdateTime:false"`
+}
+
+func (productModel) TableName() string {
+	return "products"
+}
+
+// MigrateProducts adds the columns the repository writes to a products table
+// created before them. The table itself is managed outside the application.
+func MigrateProducts(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&productModel{}) || migrator.HasColumn(&productModel{}, "UpdatedAt") {
+		return nil
+	}
+	return migrator.AddColumn(&productModel{}, "UpdatedAt")
+}
+
+// CreateProducts creates the products table, or adds its missing columns, in
+// a database whose products the application owns, such as the secondary of
+// a dual write.
+func CreateProducts(db *gorm.DB) error {
+	return db.AutoMigrate(&productModel{})
+}
+
+func newProductModel(product *entity.Product) (*productModel, error) {
+	updatedAt := productTimestamp()
+	model := &productModel{Name: product.Name, Stock: product.Stock, UpdatedAt: &updatedAt}
+	if product.ID != "" {
+		id, err := parseMySQLID(product.ID)
+		if err != nil {
+			return nil, err
+		}
+		model.ID = id
+	}
+	return model, nil
+}
+
+func (m productModel) toEntity() entity.Product {
+	product := entity.Product{
+		ID:    strconv.FormatUint(uint64(m.ID), 10),
+		Name:  m.Name,
+		Stock: m.Stock,
+	}
+	if m.UpdatedAt != nil {
+		product.UpdatedAt = *m.UpdatedAt
+	}
+	return product
+}
+
+// ProductRepositoryMySQL stores products with GORM, so it serves Postgres as
+// well.
 type ProductRepositoryMySQL struct {
 	DB *gorm.DB
+	// Replicas, when set, serves the reads and DB is its primary.
+	Replicas *database.ReplicaSet
 }
 
 func NewProductRepositoryMySQL(db *gorm.DB) port.ProductRepository {
 	return &ProductRepositoryMySQL{DB: db}
 }
 
-func (r *ProductRepositoryMySQL) Create(product *entity.Product) error {
-	return r.DB.Table("products").Create(&product).Error
+// NewProductRepositoryReplicated reads from the replicas of replicas and
+// writes to its primary.
+func NewProductRepositoryReplicated(replicas *database.ReplicaSet) port.ProductRepository {
+	return &ProductRepositoryMySQL{DB: replicas.Primary, Replicas: replicas}
+}
+
+func (r *ProductRepositoryMySQL) reader(ctx context.Context) *gorm.DB {
+	if r.Replicas == nil {
+		return r.DB.WithContext(ctx)
+	}
+	return r.Replicas.Reader(ctx)
+}
+
+// writer is used for the reads of a write as well, since a replica may not
+// have the row yet.
+func (r *ProductRepositoryMySQL) writer(ctx context.Context) *gorm.DB {
+	if r.Replicas == nil {
+		return r.DB.WithContext(ctx)
+	}
+	return r.Replicas.Writer(ctx)
+}
+
+func (r *ProductRepositoryMySQL) Create(ctx context.Context, product *entity.Product) error {
+	model, err := newProductModel(product)
+	if err != nil {
+		return err
+	}
+	if err := r.writer(ctx).Create(model).Error; err != nil {
+		return err
+	}
+	*product = model.toEntity()
+	return nil
+}
+
+// CreateMany inserts products with one statement per 500 rows.
+func (r *ProductRepositoryMySQL) CreateMany(ctx context.Context, products []entity.Product) error {
+	models := make([]productModel, 0, len(products))
+	for i := range products {
+		model, err := newProductModel(&products[i])
+		if err != nil {
+			return err
+		}
+		models = append(models, *model)
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	if err := r.writer(ctx).CreateInBatches(models, 500).Error; err != nil {
+		return err
+	}
+	for i, model := range models {
+		products[i] = model.toEntity()
+	}
+	return nil
 }
 
-func (r *ProductRepositoryMySQL) Update(product *entity.Product) error {
-	var existingProduct entity.Product
-	if err := r.DB.Table("products").First(&existingProduct, product.MySQLID).Error; err != nil {
+func (r *ProductRepositoryMySQL) Update(ctx context.Context, product *entity.Product) error {
+	model, err := newProductModel(product)
+	if err != nil {
+		return err
+	}
+
+	var existing productModel
+	if err := r.writer(ctx).First(&existing, model.ID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return fmt.Errorf("ID Not Found")
+			return entity.ErrProductNotFound
 		}
 		return err
 	}
 
-	return r.DB.Table("products").Save(product).Error
+	// Only the changed columns are written, so a concurrent change to another
+	// column is kept.
+	columns := map[string]interface{}{"updated_at": model.UpdatedAt}
+	if model.Name != existing.Name {
+		columns["name"] = model.Name
+	}
+	if model.Stock != existing.Stock {
+		columns["stock"] = model.Stock
+	}
+	if err := r.writer(ctx).Model(&existing).Updates(columns).Error; err != nil {
+		return err
+	}
+	product.UpdatedAt = *model.UpdatedAt
+	return nil
 }
 
-func (r *ProductRepositoryMySQL) GetByID(id interface{}) (*entity.Product, error) {
-	var product entity.Product
+func (r *ProductRepositoryMySQL) GetByID(ctx context.Context, id string) (*entity.Product, error) {
+	key, err := parseMySQLID(id)
+	if err != nil {
+		return nil, err
+	}
 
-	idUint, ok := id.(uint)
-	if !ok {
-		return nil, fmt.Errorf("Invalid ID type for MySQL")
+	var model productModel
+	if err := r.reader(ctx).First(&model, key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, entity.ErrProductNotFound
+		}
+		return nil, err
 	}
+	product := model.toEntity()
+	return &product, nil
+}
 
-	err := r.DB.Table("products").First(&product, idUint).Error
-	return &product, err
+func (r *ProductRepositoryMySQL) GetByIDs(ctx context.Context, ids []string) ([]entity.Product, error) {
+	keys := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		key, err := parseMySQLID(id)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	var models []productModel
+	if err := r.reader(ctx).Where("id IN ?", keys).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return toProducts(models), nil
 }
 
-func (r *ProductRepositoryMySQL) List() ([]entity.Product, error) {
-	var products []entity.Product
-	err := r.DB.Table("products").Find(&products).Error
-	return products, err
+func (r *ProductRepositoryMySQL) List(ctx context.Context) ([]entity.Product, error) {
+	var models []productModel
+	if err := r.reader(ctx).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return toProducts(models), nil
 }
 
-func (r *ProductRepositoryMySQL) Delete(id interface{}) error {
-	idUint, ok := id.(uint)
-	if !ok {
-		return fmt.Errorf("Invalid ID type for MySQL")
+func (r *ProductRepositoryMySQL) ListPage(ctx context.Context, query entity.ProductQuery) (*entity.ProductPage, error) {
+	page := &entity.ProductPage{Offset: query.Offset, Limit: query.Limit}
+	// Both statements go to the same replica, so the total matches the page.
+	db := r.reader(ctx)
+	if err := filterProducts(db, query).Count(&page.Total).Error; err != nil {
+		return nil, err
 	}
+	var models []productModel
+	if err := filterProducts(db, query).Order("id").Offset(query.Offset).Limit(query.Limit).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	page.Products = toProducts(models)
+	return page, nil
+}
 
-	var product entity.Product
-	if err := r.DB.Table("products").First(&product, idUint).Error; err != nil {
+func filterProducts(db *gorm.DB, query entity.ProductQuery) *gorm.DB {
+	db = db.Model(&productModel{})
+	if query.NameContains != "" {
+		db = db.Where("name LIKE ?", "%"+escapeLike(query.NameContains)+"%")
+	}
+	if query.MinStock != nil {
+		db = db.Where("stock >= ?", *query.MinStock)
+	}
+	if query.MaxStock != nil {
+		db = db.Where("stock <= ?", *query.MaxStock)
+	}
+	return db
+}
+
+func (r *ProductRepositoryMySQL) Delete(ctx context.Context, id string) error {
+	key, err := parseMySQLID(id)
+	if err != nil {
+		return err
+	}
+
+	var model productModel
+	if err := r.writer(ctx).First(&model, key).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return fmt.Errorf("ID Not Found")
+			return entity.ErrProductNotFound
 		}
 		return err
 	}
 
-	return r.DB.Table("products").Delete(&product).Error
+	return r.writer(ctx).Delete(&model).Error
+}
+
+func parseMySQLID(id string) (uint, error) {
+	value, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return 0, entity.ErrInvalidID
+	}
+	return uint(value), nil
+}
+
+// productTimestamp returns the current time at the millisecond precision
+// both backends store.
+func productTimestamp() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+func toProducts(models []productModel) []entity.Product {
+	products := make([]entity.Product, 0, len(models))
+	for _, model := range models {
+		products = append(products, model.toEntity())
+	}
+	return products
+}
+
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return replacer.Replace(value)
 }
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"go-hexagon/internal/core/domain/entity"
+	"math"
+	"sort"
+)
+
+// BM25 parameters, with the usual defaults.
+const (
+	searchK1 = 1.2
+	searchB  = 0.75
+)
+
+// searchIndex is an inverted index over product names for the in-memory
+// repository. Hits are ranked with BM25, counting every term of a name once
+// since names are short.
+type searchIndex struct {
+	postings map[string]map[uint64]struct{}
+	lengths  map[uint64]int
+	total    int
+}
+
+type searchMatch struct {
+	key   uint64
+	score float64
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{postings: map[string]map[uint64]struct{}{}, lengths: map[uint64]int{}}
+}
+
+func (i *searchIndex) add(key uint64, product entity.Product) {
+	terms := entity.SearchTerms(product.Name)
+	for _, term := range terms {
+		keys, ok := i.postings[term]
+		if !ok {
+			keys = map[uint64]struct{}{}
+			i.postings[term] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	i.lengths[key] = len(terms)
+	i.total += len(terms)
+}
+
+func (i *searchIndex) remove(key uint64, product entity.Product) {
+	for _, term := range entity.SearchTerms(product.Name) {
+		delete(i.postings[term], key)
+		if len(i.postings[term]) == 0 {
+			delete(i.postings, term)
+		}
+	}
+	i.total -= i.lengths[key]
+	delete(i.lengths, key)
+}
+
+// search returns the products matching any of terms, best first and by key
+// among equal scores.
+func (i *searchIndex) search(terms []string) []searchMatch {
+	if len(i.lengths) == 0 {
+		return nil
+	}
+	count := float64(len(i.lengths))
+	averageLength := float64(i.total) / count
+
+	scores := map[uint64]float64{}
+	for _, term := range terms {
+		keys := i.postings[term]
+		if len(keys) == 0 {
+			continue
+		}
+		matches := float64(len(keys))
+		idf := math.Log(1 + (count-matches+0.5)/(matches+0.5))
+		for key := range keys {
+			norm := 1 - searchB + searchB*float64(i.lengths[key])/averageLength
+			scores[key] += idf * (searchK1 + 1) / (1 + searchK1*norm)
+		}
+	}
+
+	results := make([]searchMatch, 0, len(scores))
+	for key, score := range scores {
+		results = append(results, searchMatch{key: key, score: score})
+	}
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].score != results[b].score {
+			return results[a].score > results[b].score
+		}
+		return results[a].key < results[b].key
+	})
+	return results
+}
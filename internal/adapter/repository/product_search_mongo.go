@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"go-hexagon/internal/core/domain/entity"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type productSearchDocument struct {
+	productDocument `bson:",inline"`
+	Score           float64 `bson:"score"`
+}
+
+// EnsureSearchIndex creates the text index Search needs. A collection has at
+// most one, so fields such as a description or SKU are added to it, with a
+// new name, once products have them. The "none" language neither stems nor
+// drops stop words, like the other backends.
+func (r *ProductRepositoryMongo) EnsureSearchIndex(ctx context.Context) error {
+	_, err := r.DB.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: "text"}},
+		Options: options.Index().SetName("products_search").SetDefaultLanguage("none"),
+	})
+	return err
+}
+
+// Search ranks by text score; $search matches documents with any of the
+// terms.
+func (r *ProductRepositoryMongo) Search(ctx context.Context, search entity.ProductSearch) (*entity.ProductSearchResult, error) {
+	result := &entity.ProductSearchResult{Hits: []entity.ProductHit{}, Offset: search.Offset, Limit: search.Limit}
+	terms := entity.SearchTerms(search.Text)
+	if len(terms) == 0 {
+		return result, nil
+	}
+
+	filter := bson.M{"$text": bson.M{"$search": strings.Join(terms, " ")}}
+	total, err := r.DB.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	result.Total = total
+
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}}).
+		SetSkip(int64(search.Offset)).
+		SetLimit(int64(search.Limit))
+	cursor, err := r.DB.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var documents []productSearchDocument
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+	for _, document := range documents {
+		result.Hits = append(result.Hits, entity.ProductHit{Product: document.toEntity(), Score: document.Score})
+	}
+	return result, nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"go-hexagon/internal/adapter/database"
+	"go-hexagon/internal/core/domain/entity"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// productSearchIndex is the full-text index over the searchable columns of
+// the products table. Columns such as a description or SKU join it once
+// products have them.
+const productSearchIndex = "idx_products_search"
+
+// postgresSearchVector is the document Postgres searches. The index is
+// built on this exact expression, so queries must repeat it to use it. The
+// simple configuration does not stem, since names are not all English.
+const postgresSearchVector = "to_tsvector('simple', name)"
+
+type productSearchRow struct {
+	productModel
+	Score float64 `gorm:"column:score"`
+}
+
+// EnsureSearchIndex creates the index Search needs on the products table,
+// a FULLTEXT index on MySQL and a GIN index on Postgres, unless it exists.
+// The MySQL index is built without stopwords, which InnoDB reads when the
+// index is created, so like the other backends it keeps every word.
+func (r *ProductRepositoryMySQL) EnsureSearchIndex(ctx context.Context) error {
+	db := r.DB.WithContext(ctx)
+	if db.Dialector.Name() == database.BackendPostgres {
+		return db.Exec("CREATE INDEX IF NOT EXISTS " + productSearchIndex + " ON products USING GIN (" + postgresSearchVector + ")").Error
+	}
+	if db.Migrator().HasIndex(&productModel{}, productSearchIndex) {
+		return nil
+	}
+	// The session variable only holds on the connection that creates the index.
+	return db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SET SESSION innodb_ft_enable_stopword = OFF").Error; err != nil {
+			return err
+		}
+		return conn.Exec("CREATE FULLTEXT INDEX " + productSearchIndex + " ON products (name)").Error
+	})
+}
+
+// Search ranks with MATCH ... AGAINST in boolean mode on MySQL and ts_rank
+// on Postgres. Either matches products with any of the terms. Boolean mode,
+// unlike natural language mode, also finds words that are in more than half
+// of the rows. MySQL still does not index words shorter than
+// innodb_ft_min_token_size (3 by default), which the other backends find.
+func (r *ProductRepositoryMySQL) Search(ctx context.Context, search entity.ProductSearch) (*entity.ProductSearchResult, error) {
+	result := &entity.ProductSearchResult{Hits: []entity.ProductHit{}, Offset: search.Offset, Limit: search.Limit}
+	terms := entity.SearchTerms(search.Text)
+	if len(terms) == 0 {
+		return result, nil
+	}
+
+	var query, match, score string
+	// Both statements go to the same replica, so the total matches the page.
+	db := r.reader(ctx)
+	if db.Dialector.Name() == database.BackendPostgres {
+		query = strings.Join(terms, " | ")
+		match = postgresSearchVector + " @@ to_tsquery('simple', ?)"
+		score = "ts_rank(" + postgresSearchVector + ", to_tsquery('simple', ?))"
+	} else {
+		query = strings.Join(terms, " ")
+		// The terms carry no punctuation, so none is read as an operator.
+		match = "MATCH(name) AGAINST (? IN BOOLEAN MODE)"
+		score = match
+	}
+
+	if err := db.Model(&productModel{}).Where(match, query).Count(&result.Total).Error; err != nil {
+		return nil, err
+	}
+	var rows []productSearchRow
+	err := db.Model(&productModel{}).
+		Select("*, "+score+" AS score", query).
+		Where(match, query).
+		Order("score DESC, id").
+		Offset(search.Offset).
+		Limit(search.Limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result.Hits = append(result.Hits, entity.ProductHit{Product: row.toEntity(), Score: row.Score})
+	}
+	return result, nil
+}
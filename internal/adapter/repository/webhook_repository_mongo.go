@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/port"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// webhookDocument and webhookDeliveryDocument are the shapes of documents in
+// the webhooks and webhook_deliveries collections.
+type webhookDocument struct {
+	ID         string    `bson:"_id"`
+	URL        string    `bson:"url"`
+	Events     []string  `bson:"events"`
+	ProductIDs []string  `bson:"product_ids,omitempty"`
+	Secret     string    `bson:"secret"`
+	Active     bool      `bson:"active"`
+	CreatedAt  time.Time `bson:"created_at"`
+	UpdatedAt  time.Time `bson:"updated_at"`
+}
+
+type webhookDeliveryDocument struct {
+	ID            string     `bson:"_id"`
+	WebhookID     string     `bson:"webhook_id"`
+	Event         string     `bson:"event"`
+	Payload       string     `bson:"payload"`
+	Status        string     `bson:"status"`
+	Attempts      int        `bson:"attempts"`
+	StatusCode    int        `bson:"status_code,omitempty"`
+	LastError     string     `bson:"last_error,omitempty"`
+	NextAttemptAt *time.Time `bson:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time  `bson:"created_at"`
+	DeliveredAt   *time.Time `bson:"delivered_at,omitempty"`
+}
+
+type WebhookRepositoryMongo struct {
+	DB         *mongo.Collection
+	Deliveries *mongo.Collection
+}
+
+func NewWebhookRepositoryMongo(db *mongo.Database) port.WebhookRepository {
+	return &WebhookRepositoryMongo{
+		DB:         db.Collection("webhooks"),
+		Deliveries: db.Collection("webhook_deliveries"),
+	}
+}
+
+func (r *WebhookRepositoryMongo) Create(webhook *entity.Webhook) error {
+	_, err := r.DB.InsertOne(context.Background(), webhookDocument(*webhook))
+	return err
+}
+
+func (r *WebhookRepositoryMongo) Update(webhook *entity.Webhook) error {
+	result, err := r.DB.ReplaceOne(context.Background(), bson.M{"_id": webhook.ID}, webhookDocument(*webhook))
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return entity.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepositoryMongo) GetByID(id string) (*entity.Webhook, error) {
+	var document webhookDocument
+	if err := r.DB.FindOne(context.Background(), bson.M{"_id": id}).Decode(&document); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, entity.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	webhook := entity.Webhook(document)
+	return &webhook, nil
+}
+
+func (r *WebhookRepositoryMongo) List() ([]entity.Webhook, error) {
+	cursor, err := r.DB.Find(context.Background(), bson.D{}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var documents []webhookDocument
+	if err := cursor.All(context.Background(), &documents); err != nil {
+		return nil, err
+	}
+	webhooks := make([]entity.Webhook, 0, len(documents))
+	for _, document := range documents {
+		webhooks = append(webhooks, entity.Webhook(document))
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookRepositoryMongo) Delete(id string) error {
+	result, err := r.DB.DeleteOne(context.Background(), bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return entity.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepositoryMongo) SaveDelivery(delivery *entity.WebhookDelivery) error {
+	_, err := r.Deliveries.ReplaceOne(context.Background(), bson.M{"_id": delivery.ID}, webhookDeliveryDocument(*delivery), options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *WebhookRepositoryMongo) GetDelivery(id string) (*entity.WebhookDelivery, error) {
+	var document webhookDeliveryDocument
+	if err := r.Deliveries.FindOne(context.Background(), bson.M{"_id": id}).Decode(&document); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, entity.ErrDeliveryNotFound
+		}
+		return nil, err
+	}
+	delivery := entity.WebhookDelivery(document)
+	return &delivery, nil
+}
+
+func (r *WebhookRepositoryMongo) ListDeliveries(webhookID string) ([]entity.WebhookDelivery, error) {
+	return r.findDeliveries(bson.M{"webhook_id": webhookID}, newestFirst())
+}
+
+func (r *WebhookRepositoryMongo) ListDeadLetters() ([]entity.WebhookDelivery, error) {
+	return r.findDeliveries(bson.M{"status": entity.DeliveryDead}, newestFirst())
+}
+
+func (r *WebhookRepositoryMongo) ListDueDeliveries(now time.Time, limit int) ([]entity.WebhookDelivery, error) {
+	filter := bson.M{
+		"status": entity.DeliveryPending,
+		"$or":    bson.A{bson.M{"next_attempt_at": nil}, bson.M{"next_attempt_at": bson.M{"$lte": now}}},
+	}
+	return r.findDeliveries(filter, options.Find().SetSort(bson.M{"next_attempt_at": 1}).SetLimit(int64(limit)))
+}
+
+func newestFirst() *options.FindOptions {
+	return options.Find().SetSort(bson.M{"created_at": -1})
+}
+
+func (r *WebhookRepositoryMongo) findDeliveries(filter bson.M, opts *options.FindOptions) ([]entity.WebhookDelivery, error) {
+	cursor, err := r.Deliveries.Find(context.Background(), filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var documents []webhookDeliveryDocument
+	if err := cursor.All(context.Background(), &documents); err != nil {
+		return nil, err
+	}
+	deliveries := make([]entity.WebhookDelivery, 0, len(documents))
+	for _, document := range documents {
+		deliveries = append(deliveries, entity.WebhookDelivery(document))
+	}
+	return deliveries, nil
+}
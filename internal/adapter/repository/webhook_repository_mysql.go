@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/port"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// webhookModel and webhookDeliveryModel are the row layouts of the webhooks
+// and webhook_deliveries tables.
+type webhookModel struct {
+	ID         string    `gorm:"primaryKey;column:id;size:32"`
+	URL        string    `gorm:"column:url"`
+	Events     []string  `gorm:"column:events;type:text;serializer:json"`
+	ProductIDs []string  `gorm:"column:product_ids;type:text;serializer:json"`
+	Secret     string    `gorm:"column:secret"`
+	Active     bool      `gorm:"column:active"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+	UpdatedAt  time.Time `gorm:"column:updated_at"`
+}
+
+func (webhookModel) TableName() string {
+	return "webhooks"
+}
+
+type webhookDeliveryModel struct {
+	ID            string     `gorm:"primaryKey;column:id;size:32"`
+	WebhookID     string     `gorm:"column:webhook_id;size:32;index"`
+	Event         string     `gorm:"column:event"`
+	Payload       string     `gorm:"column:payload;type:text"`
+	Status        string     `gorm:"column:status;index"`
+	Attempts      int        `gorm:"column:attempts"`
+	StatusCode    int        `gorm:"column:status_code"`
+	LastError     string     `gorm:"column:last_error;type:text"`
+	NextAttemptAt *time.Time `gorm:"column:next_attempt_at;index"`
+	CreatedAt     time.Time  `gorm:"column:created_at"`
+	DeliveredAt   *time.Time `gorm:"column:delivered_at"`
+}
+
+func (webhookDeliveryModel) TableName() string {
+	return "webhook_deliveries"
+}
+
+// MigrateWebhooks creates or updates the webhooks and webhook_deliveries
+// tables.
+func MigrateWebhooks(db *gorm.DB) error {
+	return db.AutoMigrate(&webhookModel{}, &webhookDeliveryModel{})
+}
+
+type WebhookRepositoryMySQL struct {
+	DB *gorm.DB
+}
+
+func NewWebhookRepositoryMySQL(db *gorm.DB) port.WebhookRepository {
+	return &WebhookRepositoryMySQL{DB: db}
+}
+
+func (r *WebhookRepositoryMySQL) Create(webhook *entity.Webhook) error {
+	return r.DB.Create((*webhookModel)(webhook)).Error
+}
+
+func (r *WebhookRepositoryMySQL) Update(webhook *entity.Webhook) error {
+	result := r.DB.Model(&webhookModel{}).Where("id = ?", webhook.ID).Select("*").Omit("id", "created_at").Updates((*webhookModel)(webhook))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepositoryMySQL) GetByID(id string) (*entity.Webhook, error) {
+	var model webhookModel
+	if err := r.DB.Where("id = ?", id).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, entity.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	webhook := entity.Webhook(model)
+	return &webhook, nil
+}
+
+func (r *WebhookRepositoryMySQL) List() ([]entity.Webhook, error) {
+	var models []webhookModel
+	if err := r.DB.Order("created_at").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	webhooks := make([]entity.Webhook, 0, len(models))
+	for _, model := range models {
+		webhooks = append(webhooks, entity.Webhook(model))
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookRepositoryMySQL) Delete(id string) error {
+	result := r.DB.Where("id = ?", id).Delete(&webhookModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepositoryMySQL) SaveDelivery(delivery *entity.WebhookDelivery) error {
+	return r.DB.Save((*webhookDeliveryModel)(delivery)).Error
+}
+
+func (r *WebhookRepositoryMySQL) GetDelivery(id string) (*entity.WebhookDelivery, error) {
+	var model webhookDeliveryModel
+	if err := r.DB.Where("id = ?", id).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, entity.ErrDeliveryNotFound
+		}
+		return nil, err
+	}
+	delivery := entity.WebhookDelivery(model)
+	return &delivery, nil
+}
+
+func (r *WebhookRepositoryMySQL) ListDeliveries(webhookID string) ([]entity.WebhookDelivery, error) {
+	return r.findDeliveries("webhook_id = ?", webhookID)
+}
+
+func (r *WebhookRepositoryMySQL) ListDeadLetters() ([]entity.WebhookDelivery, error) {
+	return r.findDeliveries("status = ?", entity.DeliveryDead)
+}
+
+func (r *WebhookRepositoryMySQL) ListDueDeliveries(now time.Time, limit int) ([]entity.WebhookDelivery, error) {
+	var models []webhookDeliveryModel
+	err := r.DB.Where("status = ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)", entity.DeliveryPending, now).
+		Order("next_attempt_at").Limit(limit).Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	return toWebhookDeliveries(models), nil
+}
+
+func (r *WebhookRepositoryMySQL) findDeliveries(query string, args ...interface{}) ([]entity.WebhookDelivery, error) {
+	var models []webhookDeliveryModel
+	if err := r.DB.Where(query, args...).Order("created_at DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return toWebhookDeliveries(models), nil
+}
+
+func toWebhookDeliveries(models []webhookDeliveryModel) []entity.WebhookDelivery {
+	deliveries := make([]entity.WebhookDelivery, 0, len(models))
+	for _, model := range models {
+		deliveries = append(deliveries, entity.WebhookDelivery(model))
+	}
+	return deliveries
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"go-hexagon/internal/adapter/handler/rest"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AuditRoutes(router fiber.Router, auditHandler *rest.AuditHandler) {
+	router.Get("/audit", auditHandler.ListAudit)
+}
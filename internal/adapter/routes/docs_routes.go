@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"go-hexagon/internal/adapter/openapi"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func DocsRoutes(app *fiber.App, docsHandler *openapi.Handler) {
+	app.Get("/openapi.json", docsHandler.Spec)
+	app.Get("/docs", docsHandler.Docs)
+}
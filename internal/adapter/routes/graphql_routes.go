@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"go-hexagon/internal/adapter/handler/graphql"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func GraphQLRoutes(app *fiber.App, graphqlHandler *graphql.Handler) {
+	app.Get("/graphql", graphqlHandler.Serve)
+	app.Post("/graphql", graphqlHandler.Serve)
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"go-hexagon/internal/adapter/health"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// legacyChecksDeprecated is when /readyz replaced /check-mysql and
+// /check-mongo; the aliases are removed in the release after it.
+var legacyChecksDeprecated = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+func HealthRoutes(app *fiber.App, h *health.Health) {
+	app.Get("/healthz", h.Liveness)
+	app.Get("/readyz", h.Readiness)
+
+	// Deprecated aliases that check only the database, for monitors that
+	// still poll them.
+	app.Get("/check-mysql", deprecatedCheck, h.Component("mysql"))
+	app.Get("/check-mongo", deprecatedCheck, h.Component("mongodb"))
+}
+
+func deprecatedCheck(c *fiber.Ctx) error {
+	// RFC 9745 and RFC 8288.
+	c.Set("Deprecation", "@"+strconv.FormatInt(legacyChecksDeprecated.Unix(), 10))
+	c.Append(fiber.HeaderLink, `</readyz>; rel="successor-version"`)
+	return c.Next()
+}
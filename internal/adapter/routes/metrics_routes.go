@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"go-hexagon/internal/adapter/metrics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func MetricsRoutes(app *fiber.App, m *metrics.Metrics) {
+	app.Get("/metrics", m.Handler())
+}
@@ -6,10 +6,22 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func ProductRoutesMongodb(app *fiber.App, productHandler *rest.ProductHandlerMongo) {
-	app.Get("/products", productHandler.ListProducts)
-	app.Get("/products/:id", productHandler.GetProductByID)
-	app.Post("/products", productHandler.CreateProduct)
-	app.Put("/products/:id", productHandler.UpdateProduct)
-	app.Delete("/products/:id", productHandler.DeleteProduct)
+func ProductRoutesMongodb(router fiber.Router, productHandler *rest.ProductHandlerMongo) {
+	router.Get("/products", productHandler.ListProducts)
+	router.Get("/products/search", productHandler.SearchProducts)
+	router.Get("/products/:id", productHandler.GetProductByID)
+	router.Post("/products", productHandler.CreateProduct)
+	router.Put("/products/:id", productHandler.UpdateProduct)
+	router.Delete("/products/:id", productHandler.DeleteProduct)
+}
+
+// ProductRoutesMongodbV2 differs from v1 in that the list is paginated and
+// wrapped in an envelope.
+func ProductRoutesMongodbV2(router fiber.Router, productHandler *rest.ProductHandlerMongo) {
+	router.Get("/products", productHandler.ListProductsPage)
+	router.Get("/products/search", productHandler.SearchProducts)
+	router.Get("/products/:id", productHandler.GetProductByID)
+	router.Post("/products", productHandler.CreateProduct)
+	router.Put("/products/:id", productHandler.UpdateProduct)
+	router.Delete("/products/:id", productHandler.DeleteProduct)
 }
@@ -6,11 +6,23 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func ProductRoutesMySQL(app *fiber.App, productHandler *rest.ProductHandlerMySQL) {
-	app.Get("/products", productHandler.ListProducts)
-	app.Get("/products/:id", productHandler.GetProductByID)
-	app.Post("/products", productHandler.CreateProduct)
-	app.Put("/products/:id", productHandler.UpdateProduct)
-	app.Delete("/products/:id", productHandler.DeleteProduct)
+func ProductRoutesMySQL(router fiber.Router, productHandler *rest.ProductHandlerMySQL) {
+	router.Get("/products", productHandler.ListProducts)
+	router.Get("/products/search", productHandler.SearchProducts)
+	router.Get("/products/:id", productHandler.GetProductByID)
+	router.Post("/products", productHandler.CreateProduct)
+	router.Put("/products/:id", productHandler.UpdateProduct)
+	router.Delete("/products/:id", productHandler.DeleteProduct)
 
 }
+
+// ProductRoutesMySQLV2 differs from v1 in that the list is paginated and
+// wrapped in an envelope.
+func ProductRoutesMySQLV2(router fiber.Router, productHandler *rest.ProductHandlerMySQL) {
+	router.Get("/products", productHandler.ListProductsPage)
+	router.Get("/products/search", productHandler.SearchProducts)
+	router.Get("/products/:id", productHandler.GetProductByID)
+	router.Post("/products", productHandler.CreateProduct)
+	router.Put("/products/:id", productHandler.UpdateProduct)
+	router.Delete("/products/:id", productHandler.DeleteProduct)
+}
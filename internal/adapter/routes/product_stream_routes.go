@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"go-hexagon/internal/adapter/handler/rest"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ProductStreamRoutes must be registered before the product routes, otherwise
+// /products/:id matches /products/stream first.
+func ProductStreamRoutes(router fiber.Router, streamHandler *rest.ProductStreamHandler) {
+	router.Get("/products/stream", streamHandler.Stream)
+}
@@ -0,0 +1,114 @@
+package routes
+
+import (
+	"go-hexagon/internal/adapter/handler/rest"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// versionMediaType is the vendor media type clients send in Accept to pick a
+// version on unversioned paths, e.g. application/vnd.go-hexagon.v2+json.
+var versionMediaType = regexp.MustCompile(`application/vnd\.go-hexagon\.(v[0-9]+)\+json`)
+
+type APIVersion struct {
+	Name string
+	// Deprecated is when the version was deprecated; zero while it is current.
+	Deprecated time.Time
+	// Sunset is when the version stops being served; zero when not planned.
+	Sunset time.Time
+	// Successor names the version clients should move to.
+	Successor string
+}
+
+// APIVersions mounts handler sets under /api/<version>. Requests to the old
+// unversioned paths (/products, /webhooks, ...) are rewritten to the version
+// asked for in the Accept header, or to the default version.
+type APIVersions struct {
+	app      *fiber.App
+	fallback string
+	versions map[string]APIVersion
+	groups   map[string]fiber.Router
+	// resources holds the first path segment of every versioned route, which
+	// is how unversioned requests are recognised.
+	resources map[string]bool
+}
+
+// NewAPIVersions registers the negotiation middleware, so it has to be called
+// before any middleware that looks at the request path.
+func NewAPIVersions(app *fiber.App, fallback string, versions ...APIVersion) *APIVersions {
+	v := &APIVersions{
+		app:       app,
+		fallback:  fallback,
+		versions:  make(map[string]APIVersion),
+		groups:    make(map[string]fiber.Router),
+		resources: make(map[string]bool),
+	}
+	app.Use(v.negotiate)
+	for _, version := range versions {
+		v.versions[version.Name] = version
+		v.groups[version.Name] = app.Group("/api/"+version.Name, v.headers(version))
+	}
+	return v
+}
+
+// Handle registers the routes of one version.
+func (v *APIVersions) Handle(name string, register func(router fiber.Router)) {
+	group, ok := v.groups[name]
+	if !ok {
+		panic("routes: unknown API version " + name)
+	}
+	register(group)
+
+	prefix := "/api/" + name + "/"
+	for _, route := range v.app.GetRoutes(true) {
+		if relative, ok := strings.CutPrefix(route.Path, prefix); ok {
+			resource, _, _ := strings.Cut(relative, "/")
+			v.resources[resource] = true
+		}
+	}
+}
+
+func (v *APIVersions) negotiate(c *fiber.Ctx) error {
+	path := c.Path()
+	if strings.HasPrefix(path, "/api/") {
+		return c.Next()
+	}
+	resource, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if !v.resources[resource] {
+		return c.Next()
+	}
+
+	name := v.fallback
+	if match := versionMediaType.FindStringSubmatch(c.Get(fiber.HeaderAccept)); match != nil {
+		name = match[1]
+	}
+	if _, ok := v.versions[name]; !ok {
+		return c.Status(fiber.StatusNotAcceptable).JSON(rest.ErrorResponse{Error: "Unsupported API version " + name})
+	}
+
+	c.Vary(fiber.HeaderAccept)
+	c.Path("/api/" + name + path)
+	return c.Next()
+}
+
+func (v *APIVersions) headers(version APIVersion) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set("API-Version", version.Name)
+		if !version.Deprecated.IsZero() {
+			// RFC 9745 and RFC 8594.
+			c.Set("Deprecation", "@"+strconv.FormatInt(version.Deprecated.Unix(), 10))
+			if !version.Sunset.IsZero() {
+				c.Set("Sunset", version.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if version.Successor != "" {
+				c.Append(fiber.HeaderLink, `</api/`+version.Successor+`>; rel="successor-version"`)
+			}
+		}
+		return c.Next()
+	}
+}
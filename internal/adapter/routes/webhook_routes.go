@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"go-hexagon/internal/adapter/handler/rest"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func WebhookRoutes(router fiber.Router, webhookHandler *rest.WebhookHandler) {
+	router.Get("/webhooks", webhookHandler.ListWebhooks)
+	router.Get("/webhooks/dead-letters", webhookHandler.ListDeadLetters)
+	router.Post("/webhooks/deliveries/:id/retry", webhookHandler.RetryDelivery)
+	router.Get("/webhooks/:id", webhookHandler.GetWebhookByID)
+	router.Get("/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
+	router.Post("/webhooks", webhookHandler.CreateWebhook)
+	router.Put("/webhooks/:id", webhookHandler.UpdateWebhook)
+	router.Delete("/webhooks/:id", webhookHandler.DeleteWebhook)
+}
@@ -0,0 +1,259 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// OTLPFileExporter writes spans in the OTLP JSON encoding, one
+// ExportTraceServiceRequest per line. That is the format of the OpenTelemetry
+// Collector's file exporter, so the otlpjsonfile receiver can read it back.
+type OTLPFileExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewOTLPFileExporter(w io.Writer) *OTLPFileExporter {
+	return &OTLPFileExporter{w: w}
+}
+
+func (e *OTLPFileExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	line, err := marshalOTLP(newOTLPRequest(spans))
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.w.Write(append(line, '\n'))
+	return err
+}
+
+// Shutdown does nothing; the writer belongs to the caller.
+func (e *OTLPFileExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// OTLP/JSON is the protobuf JSON mapping with two differences: enums are
+// written as numbers and trace and span IDs as hex instead of base64.
+var otlpJSON = protojson.MarshalOptions{UseEnumNumbers: true}
+
+// otlpIDFields are the bytes fields of spans and links holding IDs.
+var otlpIDFields = []string{"traceId", "spanId", "parentSpanId"}
+
+func marshalOTLP(request *collectorpb.ExportTraceServiceRequest) ([]byte, error) {
+	encoded, err := otlpJSON.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var document map[string]interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	for _, resourceSpans := range objects(document["resourceSpans"]) {
+		for _, scopeSpans := range objects(resourceSpans["scopeSpans"]) {
+			for _, span := range objects(scopeSpans["spans"]) {
+				if err := hexIDs(span); err != nil {
+					return nil, err
+				}
+				for _, link := range objects(span["links"]) {
+					if err := hexIDs(link); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	return json.Marshal(document)
+}
+
+func objects(value interface{}) []map[string]interface{} {
+	list, _ := value.([]interface{})
+	result := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if object, ok := item.(map[string]interface{}); ok {
+			result = append(result, object)
+		}
+	}
+	return result
+}
+
+// hexIDs rewrites the base64 IDs protojson wrote in object as hex.
+func hexIDs(object map[string]interface{}) error {
+	for _, field := range otlpIDFields {
+		encoded, ok := object[field].(string)
+		if !ok {
+			continue
+		}
+		id, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return err
+		}
+		object[field] = hex.EncodeToString(id)
+	}
+	return nil
+}
+
+// newOTLPRequest groups the spans by resource and then by instrumentation
+// scope, keeping the order they were ended in.
+func newOTLPRequest(spans []sdktrace.ReadOnlySpan) *collectorpb.ExportTraceServiceRequest {
+	request := &collectorpb.ExportTraceServiceRequest{}
+	resources := map[attribute.Distinct]*tracepb.ResourceSpans{}
+	scopes := map[attribute.Distinct]map[instrumentation.Scope]*tracepb.ScopeSpans{}
+
+	for _, span := range spans {
+		res := span.Resource()
+		if res == nil {
+			res = resource.Empty()
+		}
+		key := res.Equivalent()
+		resourceSpans, ok := resources[key]
+		if !ok {
+			resourceSpans = &tracepb.ResourceSpans{
+				Resource:  &resourcepb.Resource{Attributes: otlpAttributes(res.Attributes())},
+				SchemaUrl: res.SchemaURL(),
+			}
+			resources[key] = resourceSpans
+			scopes[key] = map[instrumentation.Scope]*tracepb.ScopeSpans{}
+			request.ResourceSpans = append(request.ResourceSpans, resourceSpans)
+		}
+
+		scope := span.InstrumentationScope()
+		scopeSpans, ok := scopes[key][scope]
+		if !ok {
+			scopeSpans = &tracepb.ScopeSpans{
+				Scope:     &commonpb.InstrumentationScope{Name: scope.Name, Version: scope.Version},
+				SchemaUrl: scope.SchemaURL,
+			}
+			scopes[key][scope] = scopeSpans
+			resourceSpans.ScopeSpans = append(resourceSpans.ScopeSpans, scopeSpans)
+		}
+		scopeSpans.Spans = append(scopeSpans.Spans, newOTLPSpan(span))
+	}
+	return request
+}
+
+func newOTLPSpan(span sdktrace.ReadOnlySpan) *tracepb.Span {
+	spanContext := span.SpanContext()
+	traceID, spanID := spanContext.TraceID(), spanContext.SpanID()
+	result := &tracepb.Span{
+		TraceId:                traceID[:],
+		SpanId:                 spanID[:],
+		TraceState:             spanContext.TraceState().String(),
+		Name:                   span.Name(),
+		Kind:                   tracepb.Span_SpanKind(span.SpanKind()),
+		StartTimeUnixNano:      unixNano(span.StartTime()),
+		EndTimeUnixNano:        unixNano(span.EndTime()),
+		Attributes:             otlpAttributes(span.Attributes()),
+		DroppedAttributesCount: uint32(span.DroppedAttributes()),
+		DroppedEventsCount:     uint32(span.DroppedEvents()),
+		DroppedLinksCount:      uint32(span.DroppedLinks()),
+	}
+	if parent := span.Parent(); parent.IsValid() {
+		parentID := parent.SpanID()
+		result.ParentSpanId = parentID[:]
+	}
+	if span.SpanKind() == trace.SpanKindUnspecified {
+		result.Kind = tracepb.Span_SPAN_KIND_INTERNAL
+	}
+
+	for _, event := range span.Events() {
+		result.Events = append(result.Events, &tracepb.Span_Event{
+			TimeUnixNano:           unixNano(event.Time),
+			Name:                   event.Name,
+			Attributes:             otlpAttributes(event.Attributes),
+			DroppedAttributesCount: uint32(event.DroppedAttributeCount),
+		})
+	}
+	for _, link := range span.Links() {
+		linkTraceID, linkSpanID := link.SpanContext.TraceID(), link.SpanContext.SpanID()
+		result.Links = append(result.Links, &tracepb.Span_Link{
+			TraceId:                linkTraceID[:],
+			SpanId:                 linkSpanID[:],
+			TraceState:             link.SpanContext.TraceState().String(),
+			Attributes:             otlpAttributes(link.Attributes),
+			DroppedAttributesCount: uint32(link.DroppedAttributeCount),
+		})
+	}
+
+	// codes.Ok and codes.Error are numbered the other way round in OTLP.
+	status := span.Status()
+	switch status.Code {
+	case codes.Ok:
+		result.Status = &tracepb.Status{Code: tracepb.Status_STATUS_CODE_OK}
+	case codes.Error:
+		result.Status = &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR, Message: status.Description}
+	default:
+		result.Status = &tracepb.Status{}
+	}
+	return result
+}
+
+func otlpAttributes(attributes []attribute.KeyValue) []*commonpb.KeyValue {
+	if len(attributes) == 0 {
+		return nil
+	}
+	result := make([]*commonpb.KeyValue, 0, len(attributes))
+	for _, kv := range attributes {
+		result = append(result, &commonpb.KeyValue{Key: string(kv.Key), Value: otlpValue(kv.Value)})
+	}
+	return result
+}
+
+func otlpValue(value attribute.Value) *commonpb.AnyValue {
+	switch value.Type() {
+	case attribute.BOOL:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: value.AsBool()}}
+	case attribute.INT64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: value.AsInt64()}}
+	case attribute.FLOAT64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: value.AsFloat64()}}
+	case attribute.BOOLSLICE:
+		return otlpArray(value.AsBoolSlice(), attribute.BoolValue)
+	case attribute.INT64SLICE:
+		return otlpArray(value.AsInt64Slice(), attribute.Int64Value)
+	case attribute.FLOAT64SLICE:
+		return otlpArray(value.AsFloat64Slice(), attribute.Float64Value)
+	case attribute.STRINGSLICE:
+		return otlpArray(value.AsStringSlice(), attribute.StringValue)
+	}
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value.Emit()}}
+}
+
+func otlpArray[T any](values []T, toValue func(T) attribute.Value) *commonpb.AnyValue {
+	array := &commonpb.ArrayValue{Values: make([]*commonpb.AnyValue, 0, len(values))}
+	for _, v := range values {
+		array.Values = append(array.Values, otlpValue(toValue(v)))
+	}
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: array}}
+}
+
+func unixNano(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(t.UnixNano())
+}
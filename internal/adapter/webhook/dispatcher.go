@@ -0,0 +1,408 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/port"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	Workers     int
+	QueueSize   int
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	Timeout     time.Duration
+	// PollInterval is how often stored pending deliveries are checked for a
+	// due attempt.
+	PollInterval time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Workers:      4,
+		QueueSize:    1024,
+		MaxAttempts:  6,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   5 * time.Minute,
+		Timeout:      10 * time.Second,
+		PollInterval: time.Second,
+	}
+}
+
+// job is either an event to fan out or the ID of a stored delivery to send.
+type job struct {
+	event      *entity.ProductEvent
+	deliveryID string
+}
+
+// Dispatcher sends product events to the matching webhooks. Every delivery is
+// stored as pending with the time of its next attempt before it is sent, so a
+// failed attempt never holds a worker: the scheduler queues it again once it
+// is due, including after a restart.
+type Dispatcher struct {
+	Repo   port.WebhookRepository
+	Client *http.Client
+	Config Config
+	Logger *slog.Logger
+
+	queue chan job
+	done  chan struct{}
+	wg    sync.WaitGroup
+	once  sync.Once
+
+	mu sync.Mutex
+	// queued holds the deliveries in the queue or being sent, so the
+	// scheduler does not queue them twice.
+	queued map[string]bool
+}
+
+func NewDispatcher(repo port.WebhookRepository, logger *slog.Logger, config Config) *Dispatcher {
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultConfig().PollInterval
+	}
+	return &Dispatcher{
+		Repo:   repo,
+		Client: &http.Client{Timeout: config.Timeout},
+		Config: config,
+		Logger: logger,
+		queue:  make(chan job, config.QueueSize),
+		done:   make(chan struct{}),
+		queued: map[string]bool{},
+	}
+}
+
+// Start runs the workers and the scheduler, which first resumes the pending
+// deliveries left by a previous run.
+func (d *Dispatcher) Start() {
+	for i := 0; i < d.Config.Workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+	d.wg.Add(1)
+	go d.schedule()
+}
+
+// Stop stops accepting events and waits for in-flight deliveries. Events still
+// in the queue are stored as pending deliveries, which the next Start resumes
+// along with the deliveries waiting for a retry.
+func (d *Dispatcher) Stop() {
+	d.once.Do(func() {
+		close(d.done)
+		d.wg.Wait()
+		d.drain()
+	})
+}
+
+// Check reports the dispatcher as unhealthy once it is stopped or its queue
+// is full, since new events are dropped in both cases.
+func (d *Dispatcher) Check(ctx context.Context) error {
+	select {
+	case <-d.done:
+		return errors.New("dispatcher is stopped")
+	default:
+	}
+	if len(d.queue) >= cap(d.queue) {
+		return fmt.Errorf("queue is full (%d events)", cap(d.queue))
+	}
+	return nil
+}
+
+func (d *Dispatcher) Publish(event entity.ProductEvent) {
+	select {
+	case <-d.done:
+		d.Logger.Warn("Webhook dispatcher stopped, dropping event", slog.String("event", event.Type), slog.String("product_id", event.Product.ID))
+	case d.queue <- job{event: &event}:
+	default:
+		d.Logger.Error("Webhook queue is full, dropping event", slog.String("event", event.Type), slog.String("product_id", event.Product.ID))
+	}
+}
+
+func (d *Dispatcher) Redeliver(delivery *entity.WebhookDelivery) error {
+	now := time.Now().UTC()
+	delivery.Status = entity.DeliveryPending
+	delivery.Attempts = 0
+	delivery.LastError = ""
+	delivery.StatusCode = 0
+	delivery.NextAttemptAt = &now
+	if err := d.Repo.SaveDelivery(delivery); err != nil {
+		return err
+	}
+	d.enqueue(delivery.ID)
+	return nil
+}
+
+// enqueue queues a stored delivery unless it is already queued. A delivery
+// that does not fit stays pending until the scheduler finds it.
+func (d *Dispatcher) enqueue(deliveryID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.queued[deliveryID] {
+		return
+	}
+	select {
+	case <-d.done:
+	case d.queue <- job{deliveryID: deliveryID}:
+		d.queued[deliveryID] = true
+	default:
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.done:
+			return
+		case j := <-d.queue:
+			if j.event != nil {
+				d.fanOut(*j.event)
+				continue
+			}
+			d.deliver(j.deliveryID)
+			d.mu.Lock()
+			delete(d.queued, j.deliveryID)
+			d.mu.Unlock()
+		}
+	}
+}
+
+// schedule queues the stored deliveries that are due, once at start and then
+// every PollInterval.
+func (d *Dispatcher) schedule() {
+	defer d.wg.Done()
+	ticker := time.NewTicker(d.Config.PollInterval)
+	defer ticker.Stop()
+	for {
+		d.resume()
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) resume() {
+	deliveries, err := d.Repo.ListDueDeliveries(time.Now().UTC(), cap(d.queue))
+	if err != nil {
+		d.Logger.Error("Failed to list due webhook deliveries", slog.String("error", err.Error()))
+		return
+	}
+	for _, delivery := range deliveries {
+		d.enqueue(delivery.ID)
+	}
+}
+
+// drain stores the events left in the queue once the workers have stopped.
+// Queued deliveries are already stored.
+func (d *Dispatcher) drain() {
+	for {
+		select {
+		case j := <-d.queue:
+			if j.event != nil {
+				d.store(*j.event)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// fanOut stores a delivery for every matching webhook and queues them, so they
+// are sent by the workers in parallel.
+func (d *Dispatcher) fanOut(event entity.ProductEvent) {
+	for _, deliveryID := range d.store(event) {
+		d.enqueue(deliveryID)
+	}
+}
+
+func (d *Dispatcher) store(event entity.ProductEvent) []string {
+	webhooks, err := d.Repo.List()
+	if err != nil {
+		d.Logger.Error("Failed to list webhooks", slog.String("event", event.Type), slog.String("error", err.Error()))
+		return nil
+	}
+	var deliveryIDs []string
+	for i := range webhooks {
+		if !webhooks[i].Matches(event.Type, event.Product.ID) {
+			continue
+		}
+		delivery, err := newDelivery(webhooks[i].ID, event)
+		if err != nil {
+			d.Logger.Error("Failed to build webhook payload", slog.String("webhook_id", webhooks[i].ID), slog.String("error", err.Error()))
+			continue
+		}
+		if err := d.Repo.SaveDelivery(delivery); err != nil {
+			d.Logger.Error("Failed to save webhook delivery", slog.String("delivery_id", delivery.ID), slog.String("webhook_id", delivery.WebhookID), slog.String("error", err.Error()))
+			continue
+		}
+		deliveryIDs = append(deliveryIDs, delivery.ID)
+	}
+	return deliveryIDs
+}
+
+// deliver makes one attempt at a stored delivery and, when it fails, stores
+// the time of the next attempt for the scheduler.
+func (d *Dispatcher) deliver(deliveryID string) {
+	delivery, err := d.Repo.GetDelivery(deliveryID)
+	if err != nil {
+		d.Logger.Error("Failed to load webhook delivery", slog.String("delivery_id", deliveryID), slog.String("error", err.Error()))
+		return
+	}
+	// The scheduler may have read the delivery before its last attempt ended.
+	if delivery.Status != entity.DeliveryPending || (delivery.NextAttemptAt != nil && delivery.NextAttemptAt.After(time.Now())) {
+		return
+	}
+	webhook, err := d.Repo.GetByID(delivery.WebhookID)
+	if err != nil {
+		delivery.LastError = err.Error()
+		d.markDead(delivery)
+		return
+	}
+
+	delivery.Attempts++
+	statusCode, err := d.send(webhook, delivery)
+	delivery.StatusCode = statusCode
+	if err == nil {
+		now := time.Now().UTC()
+		delivery.Status = entity.DeliverySucceeded
+		delivery.LastError = ""
+		delivery.NextAttemptAt = nil
+		delivery.DeliveredAt = &now
+		d.save(delivery)
+		return
+	}
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= d.Config.MaxAttempts {
+		d.markDead(delivery)
+		return
+	}
+	next := time.Now().UTC().Add(d.backoff(delivery.Attempts))
+	delivery.NextAttemptAt = &next
+	d.save(delivery)
+	d.Logger.Warn("Webhook delivery failed, retrying",
+		slog.String("delivery_id", delivery.ID),
+		slog.String("webhook_id", delivery.WebhookID),
+		slog.Int("attempt", delivery.Attempts),
+		slog.Time("next_attempt_at", next),
+		slog.String("error", delivery.LastError),
+	)
+}
+
+func (d *Dispatcher) send(webhook *entity.Webhook, delivery *entity.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-hexagon-webhooks/1.0")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.Config.BaseBackoff << (attempt - 1)
+	if delay <= 0 || delay > d.Config.MaxBackoff {
+		return d.Config.MaxBackoff
+	}
+	return delay
+}
+
+func (d *Dispatcher) markDead(delivery *entity.WebhookDelivery) {
+	delivery.Status = entity.DeliveryDead
+	delivery.NextAttemptAt = nil
+	d.save(delivery)
+	d.Logger.Error("Webhook delivery moved to dead letters",
+		slog.String("delivery_id", delivery.ID),
+		slog.String("webhook_id", delivery.WebhookID),
+		slog.Int("attempts", delivery.Attempts),
+		slog.String("error", delivery.LastError),
+	)
+}
+
+func (d *Dispatcher) save(delivery *entity.WebhookDelivery) {
+	if err := d.Repo.SaveDelivery(delivery); err != nil {
+		d.Logger.Error("Failed to save webhook delivery", slog.String("delivery_id", delivery.ID), slog.String("webhook_id", delivery.WebhookID), slog.String("error", err.Error()))
+	}
+}
+
+type productPayload struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Stock int    `json:"stock"`
+}
+
+type eventPayload struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Product    productPayload  `json:"product"`
+	Previous   *productPayload `json:"previous,omitempty"`
+}
+
+func newDelivery(webhookID string, event entity.ProductEvent) (*entity.WebhookDelivery, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	payload := eventPayload{
+		ID:         hex.EncodeToString(id),
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Product:    toProductPayload(event.Product),
+	}
+	if event.Previous != nil {
+		previous := toProductPayload(*event.Previous)
+		payload.Previous = &previous
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	return &entity.WebhookDelivery{
+		ID:            payload.ID,
+		WebhookID:     webhookID,
+		Event:         event.Type,
+		Payload:       string(body),
+		Status:        entity.DeliveryPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+	}, nil
+}
+
+func toProductPayload(product entity.Product) productPayload {
+	return productPayload{ID: product.ID, Name: product.Name, Stock: product.Stock}
+}
@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	AuditEntityProduct = "product"
+
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+var ErrInvalidAuditQuery = errors.New("entity must be product")
+
+// AuditRecord tells who changed an entity, how and from where. Before is nil
+// for a create and After is nil for a delete.
+type AuditRecord struct {
+	ID         string
+	Entity     string
+	EntityID   string
+	Action     string
+	Principal  Principal
+	Before     *Product
+	After      *Product
+	Changes    []FieldChange
+	RequestID  string
+	ClientIP   string
+	OccurredAt time.Time
+}
+
+// FieldChange is one field that differs between Before and After.
+type FieldChange struct {
+	Field  string
+	Before interface{}
+	After  interface{}
+}
+
+type AuditQuery struct {
+	Entity   string
+	EntityID string
+	Limit    int
+}
+
+// DiffProducts lists the fields that differ between two states of a
+// product; a nil state counts as every field being unset.
+func DiffProducts(before, after *Product) []FieldChange {
+	var fromName, toName, fromStock, toStock interface{}
+	if before != nil {
+		fromName, fromStock = before.Name, before.Stock
+	}
+	if after != nil {
+		toName, toStock = after.Name, after.Stock
+	}
+
+	var changes []FieldChange
+	if fromName != toName {
+		changes = append(changes, FieldChange{Field: "name", Before: fromName, After: toName})
+	}
+	if fromStock != toStock {
+		changes = append(changes, FieldChange{Field: "stock", Before: fromStock, After: toStock})
+	}
+	return changes
+}
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrForbidden is matched by the errors returned when the principal lacks a
+// role the policy requires.
+var ErrForbidden = errors.New("Forbidden")
+
+const (
+	AuthMethodJWT    = "jwt"
+	AuthMethodAPIKey = "api_key"
+)
+
+// Principal is the authenticated caller of a request: the subject of a JWT or
+// the name of an API key.
+type Principal struct {
+	Subject string
+	Roles   []string
+	Method  string
+}
+
+func (p Principal) HasRole(role string) bool {
+	return contains(p.Roles, role)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx that carries the principal. Inbound
+// adapters call it once the request is authenticated.
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the principal stored in ctx, if any.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
@@ -1,10 +1,26 @@
 package entity
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"errors"
+	"time"
+)
 
+var (
+	ErrProductNotFound = errors.New("ID Not Found")
+	ErrInvalidProduct  = errors.New("Name and Stock fields are required")
+	ErrInvalidID       = errors.New("Invalid product ID")
+	ErrNegativeStock   = errors.New("Stock cannot go below zero")
+	ErrInvalidSearch   = errors.New("Search text is required")
+	ErrNoSearch        = errors.New("Search is not available")
+)
+
+// Product is the domain representation of a product. ID is assigned by the
+// repository and is opaque to the core: a decimal number for SQL backends and
+// an ObjectID hex string for MongoDB. UpdatedAt is also set by the repository
+// on every write; it is zero for products stored before it was recorded.
 type Product struct {
-	MySQLID uint               `json:"id,omitempty" gorm:"primaryKey;autoIncrement;column:id" bson:"-"`
-	MongoID primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty" gorm:"-"`
-	Name    string             `json:"name" gorm:"column:name" bson:"name"`
-	Stock   int                `json:"stock" gorm:"column:stock" bson:"stock"`
+	ID        string
+	Name      string
+	Stock     int
+	UpdatedAt time.Time
 }
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+const (
+	ProductCreated      = "product.created"
+	ProductUpdated      = "product.updated"
+	ProductDeleted      = "product.deleted"
+	ProductStockChanged = "product.stock_changed"
+)
+
+var ProductEventTypes = []string{ProductCreated, ProductUpdated, ProductDeleted, ProductStockChanged}
+
+type ProductEvent struct {
+	Type       string
+	Product    Product
+	Previous   *Product
+	OccurredAt time.Time
+}
@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"strings"
+	"unicode"
+)
+
+type ProductQuery struct {
+	Offset       int
+	Limit        int
+	NameContains string
+	MinStock     *int
+	MaxStock     *int
+}
+
+type ProductPage struct {
+	Products []Product
+	Total    int64
+	Offset   int
+	Limit    int
+}
+
+// HasMore reports whether products exist after this page.
+func (p ProductPage) HasMore() bool {
+	return int64(p.Offset+len(p.Products)) < p.Total
+}
+
+// ProductSearch is a full-text query over the searchable fields of a
+// product, which is only its name for now.
+type ProductSearch struct {
+	Text   string
+	Offset int
+	Limit  int
+}
+
+// ProductHit is a product matching a search. Scores come from the backend
+// and only order the hits of one search.
+type ProductHit struct {
+	Product Product
+	Score   float64
+}
+
+// ProductSearchResult is one page of hits, most relevant first.
+type ProductSearchResult struct {
+	Hits   []ProductHit
+	Total  int64
+	Offset int
+	Limit  int
+}
+
+// SearchTerms splits text into the lower case words a search matches, any
+// of which is enough for a product to be found. Punctuation is dropped, so
+// the terms can be passed to a query syntax without escaping.
+func SearchTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	terms := make([]string, 0, len(words))
+	seen := make(map[string]bool, len(words))
+	for _, word := range words {
+		if !seen[word] {
+			seen[word] = true
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
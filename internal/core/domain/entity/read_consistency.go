@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Reads may be served by replicas that lag behind the primary. A request
+// with read-your-writes reads from the primary once it has written, or from
+// the start when the client asked for it.
+type readYourWritesKey struct{}
+
+type readPin struct {
+	primary atomic.Bool
+}
+
+// WithReadYourWrites returns a copy of ctx that tracks the writes of one
+// request. With primary set, all its reads go to the primary.
+func WithReadYourWrites(ctx context.Context, primary bool) context.Context {
+	pin := &readPin{}
+	pin.primary.Store(primary)
+	return context.WithValue(ctx, readYourWritesKey{}, pin)
+}
+
+// MarkWritten pins the remaining reads of the request in ctx to the primary.
+// Repositories call it before they write.
+func MarkWritten(ctx context.Context) {
+	if pin, ok := ctx.Value(readYourWritesKey{}).(*readPin); ok {
+		pin.primary.Store(true)
+	}
+}
+
+// WithPrimaryReads returns a ctx whose reads go to the primary, for the reads
+// a write is based on. The request in ctx, if any, is pinned as well, since it
+// is about to write.
+func WithPrimaryReads(ctx context.Context) context.Context {
+	if pin, ok := ctx.Value(readYourWritesKey{}).(*readPin); ok {
+		pin.primary.Store(true)
+		return ctx
+	}
+	return WithReadYourWrites(ctx, true)
+}
+
+// ReadFromPrimary reports whether reads in ctx must not go to a replica.
+func ReadFromPrimary(ctx context.Context) bool {
+	pin, ok := ctx.Value(readYourWritesKey{}).(*readPin)
+	return ok && pin.primary.Load()
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "context"
+
+// RequestInfo identifies the request a change was made in, for audit records
+// and logs.
+type RequestInfo struct {
+	ID       string
+	ClientIP string
+}
+
+type requestInfoKey struct{}
+
+// WithRequestInfo returns a copy of ctx that carries info. Inbound adapters
+// call it before anything else handles the request.
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFrom returns the request info stored in ctx, if any.
+func RequestInfoFrom(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info, ok
+}
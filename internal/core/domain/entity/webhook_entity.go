@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryDead      = "dead"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("Webhook Not Found")
+	ErrDeliveryNotFound = errors.New("Delivery Not Found")
+)
+
+type Webhook struct {
+	ID         string
+	URL        string
+	Events     []string
+	ProductIDs []string
+	Secret     string
+	Active     bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Matches reports whether the webhook subscribed to the event type and,
+// when a product filter is set, to the product identified by productID.
+func (w Webhook) Matches(eventType, productID string) bool {
+	if !w.Active {
+		return false
+	}
+	if len(w.Events) > 0 && !contains(w.Events, eventType) && !contains(w.Events, "*") {
+		return false
+	}
+	if len(w.ProductIDs) > 0 && !contains(w.ProductIDs, productID) {
+		return false
+	}
+	return true
+}
+
+type WebhookDelivery struct {
+	ID         string
+	WebhookID  string
+	Event      string
+	Payload    string
+	Status     string
+	Attempts   int
+	StatusCode int
+	LastError  string
+	// NextAttemptAt is when a pending delivery is due to be sent.
+	NextAttemptAt *time.Time
+	CreatedAt     time.Time
+	DeliveredAt   *time.Time
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
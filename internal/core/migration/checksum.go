@@ -0,0 +1,108 @@
+package migration
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/port"
+	"strconv"
+)
+
+// Summary fingerprints the products of a store.
+type Summary struct {
+	Count    int    `json:"count"`
+	Checksum string `json:"checksum"`
+}
+
+// checksum adds up a hash of the ID, name and stock of every product, so
+// the result does not depend on the order the products were read in.
+// UpdatedAt is left out since every store sets it on its own.
+type checksum struct {
+	count int
+	sum   [4]uint64
+}
+
+func (c *checksum) add(id string, product entity.Product) {
+	h := sha256.New()
+	h.Write([]byte(id))
+	h.Write([]byte{0})
+	h.Write([]byte(product.Name))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(product.Stock)))
+	digest := h.Sum(nil)
+	for i := range c.sum {
+		c.sum[i] += binary.BigEndian.Uint64(digest[i*8:])
+	}
+	c.count++
+}
+
+func (c *checksum) summary() Summary {
+	var b [32]byte
+	for i, part := range c.sum {
+		binary.BigEndian.PutUint64(b[i*8:], part)
+	}
+	return Summary{Count: c.count, Checksum: hex.EncodeToString(b[:])}
+}
+
+// walk calls visit with every page of repo in ID order.
+func walk(ctx context.Context, repo port.ProductRepository, batchSize, offset int, visit func(products []entity.Product) error) error {
+	for {
+		page, err := repo.ListPage(ctx, entity.ProductQuery{Offset: offset, Limit: batchSize})
+		if err != nil {
+			return err
+		}
+		if len(page.Products) == 0 {
+			return nil
+		}
+		if err := visit(page.Products); err != nil {
+			return err
+		}
+		offset += len(page.Products)
+	}
+}
+
+// Summarize fingerprints source and target. Target products are identified
+// by the source ID they are mapped to in ids, so both summaries match when
+// target holds a copy of every product of source and nothing else.
+func Summarize(ctx context.Context, source, target port.ProductRepository, ids port.ProductIDMap, batchSize int) (Summary, Summary, error) {
+	var sourceSum, targetSum checksum
+	err := walk(ctx, source, batchSize, 0, func(products []entity.Product) error {
+		for _, product := range products {
+			sourceSum.add(product.ID, product)
+		}
+		return nil
+	})
+	if err != nil {
+		return Summary{}, Summary{}, err
+	}
+
+	err = walk(ctx, target, batchSize, 0, func(products []entity.Product) error {
+		mapped, err := ids.Reverse(ctx, productIDs(products))
+		if err != nil {
+			return err
+		}
+		for _, product := range products {
+			// Unmapped products still count, under an ID no source has.
+			id, ok := mapped[product.ID]
+			if !ok {
+				id = "target:" + product.ID
+			}
+			targetSum.add(id, product)
+		}
+		return nil
+	})
+	if err != nil {
+		return Summary{}, Summary{}, err
+	}
+	return sourceSum.summary(), targetSum.summary(), nil
+}
+
+func productIDs(products []entity.Product) []string {
+	ids := make([]string, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+	return ids
+}
@@ -0,0 +1,200 @@
+package migration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/port"
+	"log/slog"
+	"os"
+	"time"
+)
+
+const DefaultBatchSize = 500
+
+// Checkpoint is the progress of a copy, saved after every batch so an
+// interrupted copy resumes where it stopped.
+type Checkpoint struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	// Offset is the number of source products, in ID order, already handled.
+	Offset    int       `json:"offset"`
+	Copied    int       `json:"copied"`
+	Skipped   int       `json:"skipped"`
+	Done      bool      `json:"done"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CopyConfig struct {
+	// Source and Target name the stores, e.g. mysql and mongodb. A checkpoint
+	// is only resumed for the same pair.
+	Source    string
+	Target    string
+	BatchSize int
+	// CheckpointFile is where progress is kept; empty starts over every time.
+	CheckpointFile string
+}
+
+type CopyResult struct {
+	Copied   int     `json:"copied"`
+	Skipped  int     `json:"skipped"`
+	Source   Summary `json:"source"`
+	Target   Summary `json:"target"`
+	Verified bool    `json:"verified"`
+}
+
+// Copier copies every product of Source to Target and maps their IDs in
+// IDs, source ID to target ID. Products that are already mapped are skipped,
+// so a copy can be repeated, e.g. to pick up products created since.
+type Copier struct {
+	Source port.ProductRepository
+	Target port.ProductRepository
+	IDs    port.ProductIDMap
+	Config CopyConfig
+	Logger *slog.Logger
+}
+
+func NewCopier(source, target port.ProductRepository, ids port.ProductIDMap, config CopyConfig) *Copier {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	return &Copier{Source: source, Target: target, IDs: ids, Config: config, Logger: slog.Default()}
+}
+
+// Run copies the products in batches, then compares the count and checksum
+// of both stores. The copy is not verified when the source changed in the
+// meantime, or when an earlier copy was interrupted between writing a batch
+// and mapping its IDs, which leaves unmapped products in the target.
+func (c *Copier) Run(ctx context.Context) (*CopyResult, error) {
+	checkpoint, err := c.loadCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint.Offset > 0 && !checkpoint.Done {
+		c.Logger.InfoContext(ctx, "Resuming copy", slog.Int("offset", checkpoint.Offset), slog.Int("copied", checkpoint.Copied))
+	}
+
+	// A finished copy is walked again from the start to pick up products
+	// created since; the mapped ones are skipped.
+	offset := checkpoint.Offset
+	if checkpoint.Done {
+		offset = 0
+		checkpoint.Done = false
+	}
+	err = walk(ctx, c.Source, c.Config.BatchSize, offset, func(products []entity.Product) error {
+		copied, err := c.copyBatch(ctx, products)
+		if err != nil {
+			return err
+		}
+		checkpoint.Offset += len(products)
+		checkpoint.Copied += copied
+		checkpoint.Skipped += len(products) - copied
+		c.Logger.InfoContext(ctx, "Copied batch", slog.Int("offset", checkpoint.Offset), slog.Int("copied", checkpoint.Copied), slog.Int("skipped", checkpoint.Skipped))
+		return c.saveCheckpoint(checkpoint)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint.Done = true
+	if err := c.saveCheckpoint(checkpoint); err != nil {
+		return nil, err
+	}
+
+	result := &CopyResult{Copied: checkpoint.Copied, Skipped: checkpoint.Skipped}
+	if result.Source, result.Target, err = Summarize(ctx, c.Source, c.Target, c.IDs, c.Config.BatchSize); err != nil {
+		return nil, fmt.Errorf("verify copy: %w", err)
+	}
+	result.Verified = result.Source == result.Target
+	return result, nil
+}
+
+// copyBatch writes the products that are not mapped yet and maps them.
+func (c *Copier) copyBatch(ctx context.Context, products []entity.Product) (int, error) {
+	mapped, err := c.IDs.Lookup(ctx, productIDs(products))
+	if err != nil {
+		return 0, err
+	}
+
+	var sourceIDs []string
+	var copies []entity.Product
+	for _, product := range products {
+		if _, ok := mapped[product.ID]; ok {
+			continue
+		}
+		sourceIDs = append(sourceIDs, product.ID)
+		product.ID = ""
+		copies = append(copies, product)
+	}
+	if len(copies) == 0 {
+		return 0, nil
+	}
+
+	if err := c.create(ctx, copies); err != nil {
+		return 0, err
+	}
+	translated := make(map[string]string, len(copies))
+	for i, product := range copies {
+		translated[sourceIDs[i]] = product.ID
+	}
+	if err := c.IDs.Save(ctx, translated); err != nil {
+		return 0, fmt.Errorf("save ID mapping: %w", err)
+	}
+	return len(copies), nil
+}
+
+// create inserts products in one call if the target supports it.
+func (c *Copier) create(ctx context.Context, products []entity.Product) error {
+	if batch, ok := c.Target.(port.ProductBatchCreator); ok {
+		return batch.CreateMany(ctx, products)
+	}
+	for i := range products {
+		if err := c.Target.Create(ctx, &products[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Copier) loadCheckpoint() (Checkpoint, error) {
+	checkpoint := Checkpoint{Source: c.Config.Source, Target: c.Config.Target}
+	if c.Config.CheckpointFile == "" {
+		return checkpoint, nil
+	}
+	data, err := os.ReadFile(c.Config.CheckpointFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return checkpoint, err
+	}
+
+	var saved Checkpoint
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return checkpoint, fmt.Errorf("parse checkpoint %s: %w", c.Config.CheckpointFile, err)
+	}
+	if saved.Source != checkpoint.Source || saved.Target != checkpoint.Target {
+		return checkpoint, fmt.Errorf("checkpoint %s is for a copy from %s to %s", c.Config.CheckpointFile, saved.Source, saved.Target)
+	}
+	return saved, nil
+}
+
+// saveCheckpoint replaces the file atomically, so a crash leaves either the
+// old or the new checkpoint.
+func (c *Copier) saveCheckpoint(checkpoint Checkpoint) error {
+	if c.Config.CheckpointFile == "" {
+		return nil
+	}
+	checkpoint.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := c.Config.CheckpointFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.Config.CheckpointFile)
+}
@@ -0,0 +1,283 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/port"
+	"log/slog"
+	"time"
+)
+
+const (
+	// KindMissing is a source product without a copy in the target.
+	KindMissing = "missing"
+	// KindExtra is a target product that is not a copy of a source product.
+	KindExtra = "extra"
+	// KindDiffers is a product whose copy has different fields.
+	KindDiffers = "differs"
+)
+
+const (
+	TruthSource = "source"
+	TruthTarget = "target"
+)
+
+// FieldDiff is a field that differs between a product and its copy.
+type FieldDiff struct {
+	Field  string `json:"field"`
+	Source any    `json:"source"`
+	Target any    `json:"target"`
+}
+
+type Difference struct {
+	Kind     string      `json:"kind"`
+	SourceID string      `json:"source_id,omitempty"`
+	TargetID string      `json:"target_id,omitempty"`
+	Fields   []FieldDiff `json:"fields,omitempty"`
+	Repaired bool        `json:"repaired,omitempty"`
+	// Error is why the repair failed.
+	Error string `json:"error,omitempty"`
+
+	source *entity.Product
+	target *entity.Product
+}
+
+type Report struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	// Truth is the store the other one was repaired from; empty when only
+	// checked.
+	Truth       string       `json:"truth,omitempty"`
+	SourceCount int          `json:"source_count"`
+	TargetCount int          `json:"target_count"`
+	Missing     int          `json:"missing"`
+	Extra       int          `json:"extra"`
+	Differing   int          `json:"differing"`
+	Repaired    int          `json:"repaired"`
+	Differences []Difference `json:"differences"`
+	// Consistent is true when the stores agree, after the repair if any.
+	Consistent bool      `json:"consistent"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+type VerifyConfig struct {
+	// Source and Target name the stores in the report.
+	Source    string
+	Target    string
+	BatchSize int
+	// Repair is TruthSource or TruthTarget to make the other store match
+	// it, or empty to only report the differences.
+	Repair string
+}
+
+// Verifier checks that Target holds a copy of every product of Source and
+// nothing else, pairing products through IDs as the copy and dual-write do.
+// Writes made while it runs can show up as differences, so it is best run
+// against idle stores or repeated.
+type Verifier struct {
+	Source port.ProductRepository
+	Target port.ProductRepository
+	IDs    port.ProductIDMap
+	Config VerifyConfig
+	Logger *slog.Logger
+}
+
+func NewVerifier(source, target port.ProductRepository, ids port.ProductIDMap, config VerifyConfig) *Verifier {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	return &Verifier{Source: source, Target: target, IDs: ids, Config: config, Logger: slog.Default()}
+}
+
+// Run walks Source and then Target in ID order and compares every product
+// with its copy. Repairs are made once both walks are done, so they do not
+// shift the pages being walked.
+func (v *Verifier) Run(ctx context.Context) (*Report, error) {
+	if v.Config.Repair != "" && v.Config.Repair != TruthSource && v.Config.Repair != TruthTarget {
+		return nil, fmt.Errorf("unknown source of truth %q", v.Config.Repair)
+	}
+	report := &Report{Source: v.Config.Source, Target: v.Config.Target, Truth: v.Config.Repair, Differences: []Difference{}, StartedAt: time.Now().UTC()}
+
+	err := walk(ctx, v.Source, v.Config.BatchSize, 0, func(products []entity.Product) error {
+		report.SourceCount += len(products)
+		return v.checkSource(ctx, report, products)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk source: %w", err)
+	}
+	err = walk(ctx, v.Target, v.Config.BatchSize, 0, func(products []entity.Product) error {
+		report.TargetCount += len(products)
+		return v.checkTarget(ctx, report, products)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk target: %w", err)
+	}
+	v.Logger.InfoContext(ctx, "Compared product stores",
+		slog.Int("source_count", report.SourceCount), slog.Int("target_count", report.TargetCount),
+		slog.Int("missing", report.Missing), slog.Int("extra", report.Extra), slog.Int("differing", report.Differing))
+
+	if v.Config.Repair != "" {
+		for i := range report.Differences {
+			difference := &report.Differences[i]
+			if err := v.repair(ctx, difference); err != nil {
+				difference.Error = err.Error()
+				v.Logger.ErrorContext(ctx, "Failed to repair product", slog.String("kind", difference.Kind), slog.String("source_id", difference.SourceID), slog.String("target_id", difference.TargetID), slog.String("error", err.Error()))
+				continue
+			}
+			difference.Repaired = true
+			report.Repaired++
+		}
+	}
+	report.Consistent = report.Repaired == len(report.Differences)
+	report.FinishedAt = time.Now().UTC()
+	return report, nil
+}
+
+// checkSource reports the products of a source page that have no copy or
+// a differing one.
+func (v *Verifier) checkSource(ctx context.Context, report *Report, products []entity.Product) error {
+	mapped, err := v.IDs.Lookup(ctx, productIDs(products))
+	if err != nil {
+		return err
+	}
+	targetIDs := make([]string, 0, len(mapped))
+	for _, id := range mapped {
+		targetIDs = append(targetIDs, id)
+	}
+	copies, err := getByIDs(ctx, v.Target, targetIDs)
+	if err != nil {
+		return err
+	}
+
+	for i, product := range products {
+		targetID := mapped[product.ID]
+		duplicate, ok := copies[targetID]
+		if !ok {
+			report.Missing++
+			report.Differences = append(report.Differences, Difference{Kind: KindMissing, SourceID: product.ID, TargetID: targetID, source: &products[i]})
+			continue
+		}
+		if fields := diffProducts(product, duplicate); len(fields) > 0 {
+			report.Differing++
+			report.Differences = append(report.Differences, Difference{Kind: KindDiffers, SourceID: product.ID, TargetID: targetID, Fields: fields, source: &products[i], target: &duplicate})
+		}
+	}
+	return nil
+}
+
+// checkTarget reports the products of a target page that are not mapped to
+// a source product, or are mapped to one that no longer exists. Mapped pairs
+// were already compared by checkSource.
+func (v *Verifier) checkTarget(ctx context.Context, report *Report, products []entity.Product) error {
+	mapped, err := v.IDs.Reverse(ctx, productIDs(products))
+	if err != nil {
+		return err
+	}
+	sourceIDs := make([]string, 0, len(mapped))
+	for _, id := range mapped {
+		sourceIDs = append(sourceIDs, id)
+	}
+	originals, err := getByIDs(ctx, v.Source, sourceIDs)
+	if err != nil {
+		return err
+	}
+
+	for i, product := range products {
+		sourceID := mapped[product.ID]
+		if _, ok := originals[sourceID]; ok {
+			continue
+		}
+		report.Extra++
+		report.Differences = append(report.Differences, Difference{Kind: KindExtra, SourceID: sourceID, TargetID: product.ID, target: &products[i]})
+	}
+	return nil
+}
+
+// repair makes the store that is not the source of truth agree with it for
+// one difference, keeping the ID mapping in step.
+func (v *Verifier) repair(ctx context.Context, difference *Difference) error {
+	switch {
+	case v.Config.Repair == TruthSource && difference.Kind == KindMissing:
+		product := *difference.source
+		product.ID = ""
+		if err := v.Target.Create(ctx, &product); err != nil {
+			return err
+		}
+		return v.IDs.Save(ctx, map[string]string{difference.SourceID: product.ID})
+	case v.Config.Repair == TruthSource && difference.Kind == KindExtra:
+		if err := ignoreNotFound(v.Target.Delete(ctx, difference.TargetID)); err != nil {
+			return err
+		}
+		return v.unmap(ctx, difference.SourceID)
+	case v.Config.Repair == TruthSource && difference.Kind == KindDiffers:
+		product := *difference.source
+		product.ID = difference.TargetID
+		return v.Target.Update(ctx, &product)
+	case v.Config.Repair == TruthTarget && difference.Kind == KindMissing:
+		if err := ignoreNotFound(v.Source.Delete(ctx, difference.SourceID)); err != nil {
+			return err
+		}
+		return v.unmap(ctx, difference.SourceID)
+	case v.Config.Repair == TruthTarget && difference.Kind == KindExtra:
+		// The stale mapping goes first, since a target ID is mapped once.
+		if err := v.unmap(ctx, difference.SourceID); err != nil {
+			return err
+		}
+		product := *difference.target
+		product.ID = ""
+		if err := v.Source.Create(ctx, &product); err != nil {
+			return err
+		}
+		return v.IDs.Save(ctx, map[string]string{product.ID: difference.TargetID})
+	case v.Config.Repair == TruthTarget && difference.Kind == KindDiffers:
+		product := *difference.target
+		product.ID = difference.SourceID
+		return v.Source.Update(ctx, &product)
+	}
+	return fmt.Errorf("cannot repair %s product from %s", difference.Kind, v.Config.Repair)
+}
+
+func (v *Verifier) unmap(ctx context.Context, sourceID string) error {
+	if sourceID == "" {
+		return nil
+	}
+	return v.IDs.Delete(ctx, sourceID)
+}
+
+// diffProducts compares the fields a copy keeps. UpdatedAt is set by each
+// store on its own and is not compared.
+func diffProducts(source, target entity.Product) []FieldDiff {
+	var fields []FieldDiff
+	if source.Name != target.Name {
+		fields = append(fields, FieldDiff{Field: "name", Source: source.Name, Target: target.Name})
+	}
+	if source.Stock != target.Stock {
+		fields = append(fields, FieldDiff{Field: "stock", Source: source.Stock, Target: target.Stock})
+	}
+	return fields
+}
+
+func getByIDs(ctx context.Context, repo port.ProductRepository, ids []string) (map[string]entity.Product, error) {
+	if len(ids) == 0 {
+		return map[string]entity.Product{}, nil
+	}
+	products, err := repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]entity.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+	return byID, nil
+}
+
+func ignoreNotFound(err error) error {
+	if errors.Is(err, entity.ErrProductNotFound) {
+		return nil
+	}
+	return err
+}
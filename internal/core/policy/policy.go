@@ -0,0 +1,168 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-hexagon/internal/core/domain/entity"
+	"os"
+	"strings"
+)
+
+// Actions checked by the product service. Adapters that bypass the service,
+// like the event streams, check ActionList themselves.
+const (
+	ActionList          = "product:list"
+	ActionGet           = "product:get"
+	ActionCreate        = "product:create"
+	ActionUpdate        = "product:update"
+	ActionDelete        = "product:delete"
+	ActionBulk          = "product:bulk"
+	ActionManageWebhook = "webhook:manage"
+	ActionReadAudit     = "audit:read"
+)
+
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+// DeniedError is returned when the principal may not perform an action. It
+// matches entity.ErrForbidden and its message is the reason.
+type DeniedError struct {
+	Action string
+	Reason string
+}
+
+func (e *DeniedError) Error() string {
+	return e.Reason
+}
+
+func (e *DeniedError) Unwrap() error {
+	return entity.ErrForbidden
+}
+
+// RouteRule restricts an HTTP route. Path segments written as * or :name
+// match any single segment and a trailing ** matches the rest of the path.
+// Other segments compare case-insensitively, as Fiber routes them. The rule
+// grants Roles, or the roles of Action when Roles is empty.
+type RouteRule struct {
+	Method string   `json:"method"`
+	Path   string   `json:"path"`
+	Action string   `json:"action"`
+	Roles  []string `json:"roles"`
+}
+
+// Policy maps actions and routes to the roles allowed to use them.
+type Policy struct {
+	Actions map[string][]string `json:"actions"`
+	Routes  []RouteRule         `json:"routes"`
+}
+
+// Default lets viewers read, editors also write and only admins delete, run
+// bulk operations, manage webhooks and read the audit log.
+func Default() *Policy {
+	return &Policy{
+		Actions: map[string][]string{
+			ActionList:          {RoleViewer, RoleEditor, RoleAdmin},
+			ActionGet:           {RoleViewer, RoleEditor, RoleAdmin},
+			ActionCreate:        {RoleEditor, RoleAdmin},
+			ActionUpdate:        {RoleEditor, RoleAdmin},
+			ActionDelete:        {RoleAdmin},
+			ActionBulk:          {RoleAdmin},
+			ActionManageWebhook: {RoleAdmin},
+			ActionReadAudit:     {RoleAdmin},
+		},
+		Routes: []RouteRule{
+			{Method: "GET", Path: "/api/*/products/stream", Action: ActionList},
+			{Method: "*", Path: "/api/*/webhooks/**", Action: ActionManageWebhook},
+		},
+	}
+}
+
+// Load reads a JSON file of the same shape as Policy on top of Default. Its
+// actions replace the default roles of the same action and its routes are
+// matched before the default routes.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file Policy
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse policy %s: %w", path, err)
+	}
+
+	p := Default()
+	for action, roles := range file.Actions {
+		p.Actions[action] = roles
+	}
+	for _, rule := range file.Routes {
+		if rule.Action == "" && len(rule.Roles) == 0 {
+			return nil, fmt.Errorf("policy route %s %s: action or roles is required", rule.Method, rule.Path)
+		}
+	}
+	p.Routes = append(file.Routes, p.Routes...)
+	return p, nil
+}
+
+// Authorize checks that the principal in ctx holds one of the roles of action.
+// Unknown actions are denied.
+func (p *Policy) Authorize(ctx context.Context, action string) error {
+	return p.check(ctx, action, p.Actions[action])
+}
+
+// AuthorizeRoute checks the first route rule matching the request. Requests
+// no rule matches are allowed; the service still checks the action.
+func (p *Policy) AuthorizeRoute(ctx context.Context, method, path string) error {
+	for _, rule := range p.Routes {
+		if !rule.matches(method, path) {
+			continue
+		}
+		if len(rule.Roles) > 0 {
+			return p.check(ctx, rule.Method+" "+rule.Path, rule.Roles)
+		}
+		return p.Authorize(ctx, rule.Action)
+	}
+	return nil
+}
+
+func (p *Policy) check(ctx context.Context, action string, roles []string) error {
+	principal, ok := entity.PrincipalFrom(ctx)
+	if !ok {
+		return &DeniedError{Action: action, Reason: "No authenticated principal"}
+	}
+	for _, role := range roles {
+		if principal.HasRole(role) {
+			return nil
+		}
+	}
+	if len(roles) == 0 {
+		return &DeniedError{Action: action, Reason: fmt.Sprintf("%s is not allowed for any role", action)}
+	}
+	return &DeniedError{
+		Action: action,
+		Reason: fmt.Sprintf("%s requires one of the roles %s", action, strings.Join(roles, ", ")),
+	}
+}
+
+func (r RouteRule) matches(method, path string) bool {
+	if r.Method != "*" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	pattern := strings.Split(strings.Trim(r.Path, "/"), "/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range pattern {
+		if part == "**" && i == len(pattern)-1 {
+			return true
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if part != "*" && !strings.HasPrefix(part, ":") && !strings.EqualFold(part, segments[i]) {
+			return false
+		}
+	}
+	return len(pattern) == len(segments)
+}
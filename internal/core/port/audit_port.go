@@ -0,0 +1,12 @@
+package port
+
+import (
+	"context"
+	"go-hexagon/internal/core/domain/entity"
+)
+
+type AuditRepository interface {
+	Save(ctx context.Context, record *entity.AuditRecord) error
+	// List returns the newest records matching query first.
+	List(query entity.AuditQuery) ([]entity.AuditRecord, error)
+}
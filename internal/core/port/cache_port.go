@@ -0,0 +1,24 @@
+package port
+
+import "time"
+
+// Cache stores opaque values by key. Values are bytes so the same adapters
+// work for an in-process cache and one shared between instances.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	// Set stores value until ttl elapses; a ttl of zero never expires.
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(keys ...string)
+	DeletePrefix(prefix string)
+}
+
+// CacheStats counts the lookups a cache served and the ones it passed on.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// CacheStatsReporter is implemented by caches that count their lookups.
+type CacheStatsReporter interface {
+	Stats() CacheStats
+}
@@ -0,0 +1,7 @@
+package port
+
+import "go-hexagon/internal/core/domain/entity"
+
+type ProductEventPublisher interface {
+	Publish(event entity.ProductEvent)
+}
@@ -0,0 +1,18 @@
+package port
+
+import (
+	"go-hexagon/internal/core/domain/entity"
+	"time"
+)
+
+type IdempotencyStore interface {
+	// Reserve saves record unless its key is already taken by a record that
+	// has not expired; in that case the stored record is returned and
+	// reserved is false.
+	Reserve(record *entity.IdempotencyRecord) (existing *entity.IdempotencyRecord, reserved bool, err error)
+	// Complete stores the response of a reserved record.
+	Complete(record *entity.IdempotencyRecord) error
+	// Release drops a reservation so the request can be retried.
+	Release(key string) error
+	DeleteExpired(now time.Time) error
+}
@@ -0,0 +1,16 @@
+package port
+
+import "context"
+
+// ProductIDMap records the ID a product has in a second store, e.g. its
+// MongoDB ObjectID for its MySQL ID, since the IDs of the backends are not
+// interchangeable.
+type ProductIDMap interface {
+	// Save maps every ID in ids to its other ID.
+	Save(ctx context.Context, ids map[string]string) error
+	// Lookup returns the other IDs of the ids that are mapped.
+	Lookup(ctx context.Context, ids []string) (map[string]string, error)
+	// Reverse returns the IDs of the otherIDs that are mapped.
+	Reverse(ctx context.Context, otherIDs []string) (map[string]string, error)
+	Delete(ctx context.Context, id string) error
+}
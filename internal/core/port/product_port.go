@@ -1,11 +1,23 @@
 package port
 
-import "go-hexagon/internal/core/domain/entity"
+import (
+	"context"
+	"go-hexagon/internal/core/domain/entity"
+)
 
 type ProductRepository interface {
-	Create(product *entity.Product) error
-	Update(product *entity.Product) error
-	GetByID(id interface{}) (*entity.Product, error)
-	List() ([]entity.Product, error)
-	Delete(id interface{}) error
+	Create(ctx context.Context, product *entity.Product) error
+	Update(ctx context.Context, product *entity.Product) error
+	GetByID(ctx context.Context, id string) (*entity.Product, error)
+	GetByIDs(ctx context.Context, ids []string) ([]entity.Product, error)
+	List(ctx context.Context) ([]entity.Product, error)
+	ListPage(ctx context.Context, query entity.ProductQuery) (*entity.ProductPage, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// ProductBatchCreator is implemented by repositories that can insert many
+// products in one round trip. Like Create, it sets the ID and UpdatedAt of
+// every product.
+type ProductBatchCreator interface {
+	CreateMany(ctx context.Context, products []entity.Product) error
 }
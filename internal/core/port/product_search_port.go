@@ -0,0 +1,12 @@
+package port
+
+import (
+	"context"
+	"go-hexagon/internal/core/domain/entity"
+)
+
+// ProductSearcher ranks products by how well they match free text, using
+// the full-text index of the backend.
+type ProductSearcher interface {
+	Search(ctx context.Context, search entity.ProductSearch) (*entity.ProductSearchResult, error)
+}
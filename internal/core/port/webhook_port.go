@@ -0,0 +1,25 @@
+package port
+
+import (
+	"go-hexagon/internal/core/domain/entity"
+	"time"
+)
+
+type WebhookRepository interface {
+	Create(webhook *entity.Webhook) error
+	Update(webhook *entity.Webhook) error
+	GetByID(id string) (*entity.Webhook, error)
+	List() ([]entity.Webhook, error)
+	Delete(id string) error
+	SaveDelivery(delivery *entity.WebhookDelivery) error
+	GetDelivery(id string) (*entity.WebhookDelivery, error)
+	ListDeliveries(webhookID string) ([]entity.WebhookDelivery, error)
+	ListDeadLetters() ([]entity.WebhookDelivery, error)
+	// ListDueDeliveries returns up to limit pending deliveries whose next
+	// attempt is due at now, the earliest first.
+	ListDueDeliveries(now time.Time, limit int) ([]entity.WebhookDelivery, error)
+}
+
+type WebhookDeliverer interface {
+	Redeliver(delivery *entity.WebhookDelivery) error
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/policy"
+	"go-hexagon/internal/core/port"
+)
+
+const (
+	DefaultAuditLimit = 100
+	MaxAuditLimit     = 1000
+)
+
+type AuditService struct {
+	Repo port.AuditRepository
+	// Policy is consulted before reading records; nil allows everything.
+	Policy *policy.Policy
+}
+
+func NewAuditService(repo port.AuditRepository, p *policy.Policy) *AuditService {
+	return &AuditService{Repo: repo, Policy: p}
+}
+
+// ListRecords returns the newest audit records of an entity type, optionally
+// narrowed to one entity ID.
+func (s *AuditService) ListRecords(ctx context.Context, query entity.AuditQuery) ([]entity.AuditRecord, error) {
+	if s.Policy != nil {
+		if err := s.Policy.Authorize(ctx, policy.ActionReadAudit); err != nil {
+			return nil, err
+		}
+	}
+	if query.Entity != entity.AuditEntityProduct {
+		return nil, entity.ErrInvalidAuditQuery
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultAuditLimit
+	}
+	if query.Limit > MaxAuditLimit {
+		query.Limit = MaxAuditLimit
+	}
+	return s.Repo.List(query)
+}
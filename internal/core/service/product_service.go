@@ -1,34 +1,332 @@
 package service
 
 import (
+	"context"
 	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/policy"
 	"go-hexagon/internal/core/port"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
 )
 
+// tracer records a span per service call. It does nothing unless the
+// application installs a tracer provider.
+var tracer = otel.Tracer("go-hexagon/internal/core/service")
+
 type ProductService struct {
-	Repo port.ProductRepository
+	Repo       port.ProductRepository
+	Publishers []port.ProductEventPublisher
+	// Searcher serves SearchProducts; nil disables search.
+	Searcher port.ProductSearcher
+	// Policy is consulted before every operation; nil allows everything.
+	Policy *policy.Policy
+	// Audit receives a record of every create, update and delete; nil
+	// disables auditing.
+	Audit port.AuditRepository
+	// Logger receives errors the caller does not see, logged with the
+	// request context.
+	Logger *slog.Logger
+}
+
+// ProductChanges describes a partial update; nil fields are left untouched.
+type ProductChanges struct {
+	Name  *string
+	Stock *int
+}
+
+type Option func(*ProductService)
+
+func WithPublisher(publisher port.ProductEventPublisher) Option {
+	return func(s *ProductService) {
+		s.Publishers = append(s.Publishers, publisher)
+	}
+}
+
+func WithSearcher(searcher port.ProductSearcher) Option {
+	return func(s *ProductService) {
+		s.Searcher = searcher
+	}
+}
+
+func WithPolicy(p *policy.Policy) Option {
+	return func(s *ProductService) {
+		s.Policy = p
+	}
+}
+
+func WithAudit(repo port.AuditRepository) Option {
+	return func(s *ProductService) {
+		s.Audit = repo
+	}
+}
+
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *ProductService) {
+		s.Logger = logger
+	}
+}
+
+func NewProductService(repo port.ProductRepository, opts ...Option) *ProductService {
+	s := &ProductService{Repo: repo, Logger: slog.Default()}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+func (s *ProductService) CreateProduct(ctx context.Context, product *entity.Product) error {
+	ctx, span := tracer.Start(ctx, "ProductService.CreateProduct")
+	defer span.End()
+	if err := s.Authorize(ctx, policy.ActionCreate); err != nil {
+		return err
+	}
+	if product.Name == "" || product.Stock == 0 {
+		return entity.ErrInvalidProduct
+	}
+	if err := s.Repo.Create(ctx, product); err != nil {
+		return err
+	}
+	s.publish(entity.ProductCreated, *product, nil)
+	s.audit(ctx, entity.AuditCreate, product.ID, nil, product)
+	return nil
+}
+
+func (s *ProductService) UpdateProduct(ctx context.Context, product *entity.Product) error {
+	ctx, span := tracer.Start(ctx, "ProductService.UpdateProduct")
+	defer span.End()
+	if err := s.Authorize(ctx, policy.ActionUpdate); err != nil {
+		return err
+	}
+	previous := s.previous(ctx, product.ID)
+	if err := s.Repo.Update(ctx, product); err != nil {
+		return err
+	}
+	s.publish(entity.ProductUpdated, *product, previous)
+	if previous != nil && previous.Stock != product.Stock {
+		s.publish(entity.ProductStockChanged, *product, previous)
+	}
+	s.audit(ctx, entity.AuditUpdate, product.ID, previous, product)
+	return nil
+}
+
+func (s *ProductService) GetProductByID(ctx context.Context, id string) (*entity.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.GetProductByID")
+	defer span.End()
+	if err := s.Authorize(ctx, policy.ActionGet); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetByID(ctx, id)
+}
+
+func (s *ProductService) GetProductsByIDs(ctx context.Context, ids []string) ([]entity.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.GetProductsByIDs")
+	defer span.End()
+	if err := s.Authorize(ctx, policy.ActionGet); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetByIDs(ctx, ids)
+}
+
+func (s *ProductService) PatchProduct(ctx context.Context, id string, changes ProductChanges) (*entity.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.PatchProduct")
+	defer span.End()
+	if err := s.Authorize(ctx, policy.ActionUpdate); err != nil {
+		return nil, err
+	}
+	// The product is written back, so it must not come from a lagging replica.
+	ctx = entity.WithPrimaryReads(ctx)
+	product, err := s.Repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes.Name != nil {
+		product.Name = *changes.Name
+	}
+	if changes.Stock != nil {
+		if *changes.Stock < 0 {
+			return nil, entity.ErrNegativeStock
+		}
+		product.Stock = *changes.Stock
+	}
+
+	if err := s.UpdateProduct(ctx, product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+func (s *ProductService) AdjustStock(ctx context.Context, id string, delta int) (*entity.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.AdjustStock")
+	defer span.End()
+	if err := s.Authorize(ctx, policy.ActionUpdate); err != nil {
+		return nil, err
+	}
+	ctx = entity.WithPrimaryReads(ctx)
+	product, err := s.Repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stock := product.Stock + delta
+	if stock < 0 {
+		return nil, entity.ErrNegativeStock
+	}
+	product.Stock = stock
+
+	if err := s.UpdateProduct(ctx, product); err != nil {
+		return nil, err
+	}
+	return product, nil
 }
 
-func NewProductService(repo port.ProductRepository) *ProductService {
-	return &ProductService{Repo: repo}
+func (s *ProductService) ListProducts(ctx context.Context) ([]entity.Product, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.ListProducts")
+	defer span.End()
+	if err := s.Authorize(ctx, policy.ActionList); err != nil {
+		return nil, err
+	}
+	return s.Repo.List(ctx)
 }
 
-func (s *ProductService) CreateProduct(product *entity.Product) error {
-	return s.Repo.Create(product)
+func (s *ProductService) ListProductsPage(ctx context.Context, query entity.ProductQuery) (*entity.ProductPage, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.ListProductsPage")
+	defer span.End()
+	if err := s.Authorize(ctx, policy.ActionList); err != nil {
+		return nil, err
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultPageSize
+	}
+	if query.Limit > MaxPageSize {
+		query.Limit = MaxPageSize
+	}
+	return s.Repo.ListPage(ctx, query)
 }
 
-func (s *ProductService) UpdateProduct(product *entity.Product) error {
-	return s.Repo.Update(product)
+// SearchProducts returns a page of the products matching any word of
+// search.Text, most relevant first.
+func (s *ProductService) SearchProducts(ctx context.Context, search entity.ProductSearch) (*entity.ProductSearchResult, error) {
+	ctx, span := tracer.Start(ctx, "ProductService.SearchProducts")
+	defer span.End()
+	if err := s.Authorize(ctx, policy.ActionList); err != nil {
+		return nil, err
+	}
+	if len(entity.SearchTerms(search.Text)) == 0 {
+		return nil, entity.ErrInvalidSearch
+	}
+	if s.Searcher == nil {
+		return nil, entity.ErrNoSearch
+	}
+	if search.Offset < 0 {
+		search.Offset = 0
+	}
+	if search.Limit <= 0 {
+		search.Limit = DefaultPageSize
+	}
+	if search.Limit > MaxPageSize {
+		search.Limit = MaxPageSize
+	}
+	return s.Searcher.Search(ctx, search)
 }
 
-func (s *ProductService) GetProductByID(id interface{}) (*entity.Product, error) {
-	return s.Repo.GetByID(id)
+func (s *ProductService) DeleteProduct(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "ProductService.DeleteProduct")
+	defer span.End()
+	if err := s.Authorize(ctx, policy.ActionDelete); err != nil {
+		return err
+	}
+	previous := s.previous(ctx, id)
+	if err := s.Repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	if previous != nil {
+		s.publish(entity.ProductDeleted, *previous, previous)
+	}
+	s.audit(ctx, entity.AuditDelete, id, previous, nil)
+	return nil
 }
 
-func (s *ProductService) ListProducts() ([]entity.Product, error) {
-	return s.Repo.List()
+// Authorize checks action against the policy for the principal in ctx.
+// Adapters that serve product data without going through the service, such as
+// the event streams, call it directly.
+func (s *ProductService) Authorize(ctx context.Context, action string) error {
+	if s.Policy == nil {
+		return nil
+	}
+	return s.Policy.Authorize(ctx, action)
 }
 
-func (s *ProductService) DeleteProduct(id interface{}) error {
-	return s.Repo.Delete(id)
+// previous loads the stored state of a product before it is changed, which is
+// only needed when someone is listening for events or auditing. It is read
+// from the primary, since a replica may not have the latest change yet.
+func (s *ProductService) previous(ctx context.Context, id string) *entity.Product {
+	if len(s.Publishers) == 0 && s.Audit == nil {
+		return nil
+	}
+	product, err := s.Repo.GetByID(entity.WithPrimaryReads(ctx), id)
+	if err != nil || product == nil {
+		return nil
+	}
+	return product
+}
+
+func (s *ProductService) publish(eventType string, product entity.Product, previous *entity.Product) {
+	if len(s.Publishers) == 0 {
+		return
+	}
+	event := entity.ProductEvent{
+		Type:       eventType,
+		Product:    product,
+		Previous:   previous,
+		OccurredAt: time.Now().UTC(),
+	}
+	for _, publisher := range s.Publishers {
+		publisher.Publish(event)
+	}
+}
+
+// audit records a change together with the principal and request that made
+// it. The change is already stored, so a failure to write the record is
+// logged rather than returned to the caller.
+func (s *ProductService) audit(ctx context.Context, action, id string, before, after *entity.Product) {
+	if s.Audit == nil {
+		return
+	}
+	// The record outlives the call, so it keeps copies of the caller's values.
+	if after != nil {
+		snapshot := *after
+		after = &snapshot
+	}
+	principal, _ := entity.PrincipalFrom(ctx)
+	info, _ := entity.RequestInfoFrom(ctx)
+
+	record := &entity.AuditRecord{
+		ID:         randomHex(16),
+		Entity:     entity.AuditEntityProduct,
+		EntityID:   strings.Clone(id),
+		Action:     action,
+		Principal:  principal,
+		Before:     before,
+		After:      after,
+		Changes:    entity.DiffProducts(before, after),
+		RequestID:  info.ID,
+		ClientIP:   info.ClientIP,
+		OccurredAt: time.Now().UTC(),
+	}
+	if err := s.Audit.Save(ctx, record); err != nil {
+		s.Logger.ErrorContext(ctx, "Failed to write audit record",
+			slog.String("product_id", id), slog.String("error", err.Error()))
+	}
 }
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/port"
+	"net/url"
+	"time"
+)
+
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+type WebhookService struct {
+	Repo      port.WebhookRepository
+	Deliverer port.WebhookDeliverer
+}
+
+func NewWebhookService(repo port.WebhookRepository, deliverer port.WebhookDeliverer) *WebhookService {
+	return &WebhookService{Repo: repo, Deliverer: deliverer}
+}
+
+func (s *WebhookService) CreateWebhook(webhook *entity.Webhook) error {
+	if err := validateWebhook(webhook); err != nil {
+		return err
+	}
+	if webhook.Secret == "" {
+		webhook.Secret = randomHex(32)
+	}
+	webhook.ID = randomHex(16)
+	webhook.CreatedAt = time.Now().UTC()
+	webhook.UpdatedAt = webhook.CreatedAt
+	return s.Repo.Create(webhook)
+}
+
+func (s *WebhookService) UpdateWebhook(webhook *entity.Webhook) error {
+	if err := validateWebhook(webhook); err != nil {
+		return err
+	}
+	webhook.UpdatedAt = time.Now().UTC()
+	return s.Repo.Update(webhook)
+}
+
+func (s *WebhookService) GetWebhookByID(id string) (*entity.Webhook, error) {
+	return s.Repo.GetByID(id)
+}
+
+func (s *WebhookService) ListWebhooks() ([]entity.Webhook, error) {
+	return s.Repo.List()
+}
+
+func (s *WebhookService) DeleteWebhook(id string) error {
+	return s.Repo.Delete(id)
+}
+
+func (s *WebhookService) ListDeliveries(webhookID string) ([]entity.WebhookDelivery, error) {
+	if _, err := s.Repo.GetByID(webhookID); err != nil {
+		return nil, err
+	}
+	return s.Repo.ListDeliveries(webhookID)
+}
+
+func (s *WebhookService) ListDeadLetters() ([]entity.WebhookDelivery, error) {
+	return s.Repo.ListDeadLetters()
+}
+
+func (s *WebhookService) RetryDelivery(id string) (*entity.WebhookDelivery, error) {
+	delivery, err := s.Repo.GetDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status != entity.DeliveryDead {
+		return nil, fmt.Errorf("%w: only dead deliveries can be retried", ErrInvalidWebhook)
+	}
+	if err := s.Deliverer.Redeliver(delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func validateWebhook(webhook *entity.Webhook) error {
+	target, err := url.Parse(webhook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	for _, event := range webhook.Events {
+		if event == "*" {
+			continue
+		}
+		known := false
+		for _, eventType := range entity.ProductEventTypes {
+			if event == eventType {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, event)
+		}
+	}
+	return nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
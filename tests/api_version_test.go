@@ -0,0 +1,96 @@
+package handler_test
+
+import (
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/adapter/routes"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newVersionedApp(productRepoMock *ProductRepositoryMock) *fiber.App {
+	productHandler := rest.NewProductHandlerMySQL(service.NewProductService(productRepoMock))
+
+	app := fiber.New()
+	apiVersions := routes.NewAPIVersions(app, "v1",
+		routes.APIVersion{
+			Name:       "v1",
+			Deprecated: time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+			Sunset:     time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC),
+			Successor:  "v2",
+		},
+		routes.APIVersion{Name: "v2"},
+	)
+	apiVersions.Handle("v1", func(api fiber.Router) {
+		routes.ProductRoutesMySQL(api, productHandler)
+	})
+	apiVersions.Handle("v2", func(api fiber.Router) {
+		routes.ProductRoutesMySQLV2(api, productHandler)
+	})
+	return app
+}
+
+func TestAPIVersion_DeprecatedVersionHeaders(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("List").Return([]entity.Product{{ID: "1", Name: "Product A", Stock: 100}}, nil)
+	app := newVersionedApp(productRepoMock)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/products", nil), -1)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "v1", resp.Header.Get("API-Version"))
+	assert.Equal(t, "@1792368000", resp.Header.Get("Deprecation"))
+	assert.Equal(t, "Fri, 30 Apr 2027 00:00:00 GMT", resp.Header.Get("Sunset"))
+	assert.Equal(t, `</api/v2>; rel="successor-version"`, resp.Header.Get("Link"))
+	assert.JSONEq(t, `[{"id":1,"name":"Product A","stock":100}]`, getResponseBody(t, resp))
+}
+
+func TestAPIVersion_PathAndAcceptNegotiation(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("List").Return([]entity.Product{{ID: "1", Name: "Product A", Stock: 100}}, nil)
+	productRepoMock.On("ListPage", entity.ProductQuery{Offset: 0, Limit: 2}).Return(&entity.ProductPage{
+		Products: []entity.Product{{ID: "1", Name: "Product A", Stock: 100}},
+		Total:    3,
+		Limit:    2,
+	}, nil)
+	app := newVersionedApp(productRepoMock)
+
+	// Path tanpa versi dilayani oleh v1
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/products", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", resp.Header.Get("API-Version"))
+	assert.Equal(t, fiber.HeaderAccept, resp.Header.Get(fiber.HeaderVary))
+	assert.JSONEq(t, `[{"id":1,"name":"Product A","stock":100}]`, getResponseBody(t, resp))
+
+	// Header Accept memilih v2
+	req := httptest.NewRequest(http.MethodGet, "/products?limit=2", nil)
+	req.Header.Set(fiber.HeaderAccept, "application/vnd.go-hexagon.v2+json")
+	resp, err = app.Test(req, -1)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", resp.Header.Get("API-Version"))
+	assert.Empty(t, resp.Header.Get("Deprecation"))
+	expected := `{"items":[{"id":1,"name":"Product A","stock":100}],"total":3,"offset":0,"limit":2}`
+	assert.JSONEq(t, expected, getResponseBody(t, resp))
+
+	// Path dengan versi selalu menang atas header Accept
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/products?limit=2", nil)
+	req.Header.Set(fiber.HeaderAccept, "application/vnd.go-hexagon.v1+json")
+	resp, err = app.Test(req, -1)
+	require.NoError(t, err)
+	assert.JSONEq(t, expected, getResponseBody(t, resp))
+
+	// Versi yang tidak dikenal
+	req = httptest.NewRequest(http.MethodGet, "/products", nil)
+	req.Header.Set(fiber.HeaderAccept, "application/vnd.go-hexagon.v9+json")
+	resp, err = app.Test(req, -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
+}
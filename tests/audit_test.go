@@ -0,0 +1,166 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/adapter/routes"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// Repository audit in-memory untuk pengujian
+type AuditRepositoryFake struct {
+	mu       sync.Mutex
+	records  []entity.AuditRecord
+	subjects []string
+}
+
+func NewAuditRepositoryFake() *AuditRepositoryFake {
+	return &AuditRepositoryFake{}
+}
+
+func (r *AuditRepositoryFake) Save(ctx context.Context, record *entity.AuditRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, *record)
+	// Catat principal dari context untuk memastikan context request diteruskan
+	principal, _ := entity.PrincipalFrom(ctx)
+	r.subjects = append(r.subjects, principal.Subject)
+	return nil
+}
+
+func (r *AuditRepositoryFake) List(query entity.AuditQuery) ([]entity.AuditRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var records []entity.AuditRecord
+	for _, record := range r.records {
+		if record.Entity == query.Entity && (query.EntityID == "" || record.EntityID == query.EntityID) {
+			records = append(records, record)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].OccurredAt.After(records[j].OccurredAt) })
+	if len(records) > query.Limit {
+		records = records[:query.Limit]
+	}
+	return records, nil
+}
+
+func newAuditApp(productRepoMock *ProductRepositoryMock, auditRepo *AuditRepositoryFake) *fiber.App {
+	productService := service.NewProductService(productRepoMock, service.WithAudit(auditRepo))
+	auditHandler := rest.NewAuditHandler(service.NewAuditService(auditRepo, nil))
+
+	app := fiber.New()
+	app.Use(rest.RequestInfo())
+	app.Use(func(c *fiber.Ctx) error {
+		principal := entity.Principal{Subject: "alice", Roles: []string{"editor"}, Method: entity.AuthMethodJWT}
+		c.SetUserContext(entity.WithPrincipal(c.UserContext(), principal))
+		return c.Next()
+	})
+	routes.ProductRoutesMySQL(app, rest.NewProductHandlerMySQL(productService))
+	routes.AuditRoutes(app, auditHandler)
+	return app
+}
+
+func TestAudit_RecordsEveryMutation(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*entity.Product).ID = "1"
+	}).Return(nil)
+	// Setiap GetByID mengembalikan salinan baru, seperti repository sungguhan
+	for i := 0; i < 3; i++ {
+		productRepoMock.On("GetByID", "1").Return(&entity.Product{ID: "1", Name: "Product A", Stock: 10}, nil).Once()
+	}
+	productRepoMock.On("Update", mock.Anything).Return(nil)
+	productRepoMock.On("Delete", "1").Return(nil)
+	auditRepo := NewAuditRepositoryFake()
+	app := newAuditApp(productRepoMock, auditRepo)
+
+	req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{"name":"Product A","stock":10}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", "req-create")
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	assert.Equal(t, "req-create", resp.Header.Get("X-Request-ID"))
+
+	req = httptest.NewRequest(http.MethodPut, "/products/1", strings.NewReader(`{"stock":7}`))
+	req.Header.Set("Content-Type", "application/json")
+	_, err = app.Test(req, -1)
+	require.NoError(t, err)
+
+	_, err = app.Test(httptest.NewRequest(http.MethodDelete, "/products/1", nil), -1)
+	require.NoError(t, err)
+
+	require.Len(t, auditRepo.records, 3)
+	created, updated, deleted := auditRepo.records[0], auditRepo.records[1], auditRepo.records[2]
+
+	assert.Equal(t, entity.AuditCreate, created.Action)
+	assert.Equal(t, "1", created.EntityID)
+	assert.Equal(t, "alice", created.Principal.Subject)
+	assert.Equal(t, "req-create", created.RequestID)
+	assert.NotEmpty(t, created.ClientIP)
+	assert.Nil(t, created.Before)
+	assert.Equal(t, &entity.Product{ID: "1", Name: "Product A", Stock: 10}, created.After)
+
+	// Update hanya mencatat field yang berubah
+	assert.Equal(t, entity.AuditUpdate, updated.Action)
+	assert.NotEmpty(t, updated.RequestID)
+	assert.NotEqual(t, created.RequestID, updated.RequestID)
+	assert.Equal(t, []entity.FieldChange{{Field: "stock", Before: 10, After: 7}}, updated.Changes)
+
+	assert.Equal(t, entity.AuditDelete, deleted.Action)
+	assert.Equal(t, "Product A", deleted.Before.Name)
+	assert.Nil(t, deleted.After)
+	assert.Len(t, deleted.Changes, 2)
+
+	// Save menerima context request, bukan context.Background()
+	assert.Equal(t, []string{"alice", "alice", "alice"}, auditRepo.subjects)
+}
+
+func TestAudit_QueryEndpoint(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("GetByID", "1").Return(&entity.Product{ID: "1", Name: "Product A", Stock: 10}, nil)
+	productRepoMock.On("GetByID", "2").Return(&entity.Product{ID: "2", Name: "Product B", Stock: 5}, nil)
+	productRepoMock.On("Delete", mock.Anything).Return(nil)
+	auditRepo := NewAuditRepositoryFake()
+	app := newAuditApp(productRepoMock, auditRepo)
+
+	for _, id := range []string{"1", "2"} {
+		_, err := app.Test(httptest.NewRequest(http.MethodDelete, "/products/"+id, nil), -1)
+		require.NoError(t, err)
+	}
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/audit?entity=product&id=2", nil), -1)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var records []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(getResponseBody(t, resp)), &records))
+	require.Len(t, records, 1)
+	assert.Equal(t, "delete", records[0]["action"])
+	assert.Equal(t, "2", records[0]["entity_id"])
+	assert.Equal(t, map[string]interface{}{"subject": "alice", "roles": []interface{}{"editor"}, "method": "jwt"}, records[0]["principal"])
+	assert.Equal(t, map[string]interface{}{"id": "2", "name": "Product B", "stock": float64(5)}, records[0]["before"])
+	assert.NotContains(t, records[0], "after")
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/audit?entity=product", nil), -1)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(getResponseBody(t, resp)), &records))
+	assert.Len(t, records, 2)
+
+	// Entity wajib diisi dan hanya product yang dikenal
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/audit?entity=webhook", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,152 @@
+package handler_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"go-hexagon/internal/adapter/auth"
+	"go-hexagon/internal/core/domain/entity"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testJWTSecret = []byte("test-secret")
+
+func newAuthApp(t *testing.T, authenticator *auth.Authenticator) *fiber.App {
+	app := fiber.New()
+	app.Use(authenticator.Middleware("/docs"))
+	app.Get("/docs", func(c *fiber.Ctx) error { return c.SendString("docs") })
+	app.Get("/whoami", func(c *fiber.Ctx) error {
+		principal, ok := entity.PrincipalFrom(c.UserContext())
+		require.True(t, ok)
+		return c.JSON(principal)
+	})
+	return app
+}
+
+func signHS256(t *testing.T, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(testJWTSecret)
+	require.NoError(t, err)
+	return token
+}
+
+func get(t *testing.T, app *fiber.App, path string, headers map[string]string) (*http.Response, string) {
+	return send(t, app, http.MethodGet, path, headers)
+}
+
+func send(t *testing.T, app *fiber.App, method, path string, headers map[string]string) (*http.Response, string) {
+	req := httptest.NewRequest(method, path, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	return resp, getResponseBody(t, resp)
+}
+
+func TestAuth_BearerTokenAndAPIKey(t *testing.T) {
+	keys := auth.NewKeySet()
+	keys.AddHMAC("", testJWTSecret)
+	sum := sha256.Sum256([]byte("billing-key"))
+	authenticator, err := auth.NewAuthenticator(auth.Config{
+		Keys:    keys,
+		APIKeys: []auth.APIKey{{Name: "billing", SHA256: hex.EncodeToString(sum[:]), Roles: []string{"editor"}}},
+	})
+	require.NoError(t, err)
+	app := newAuthApp(t, authenticator)
+
+	token := signHS256(t, jwt.MapClaims{"sub": "alice", "roles": []string{"admin"}, "exp": time.Now().Add(time.Hour).Unix()})
+	resp, body := get(t, app, "/whoami", map[string]string{"Authorization": "Bearer " + token})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"Subject":"alice","Roles":["admin"],"Method":"jwt"}`, body)
+
+	resp, body = get(t, app, "/whoami", map[string]string{"X-API-Key": "billing-key"})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"Subject":"billing","Roles":["editor"],"Method":"api_key"}`, body)
+
+	// Path publik tidak butuh kredensial
+	resp, _ = get(t, app, "/docs", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestAuth_RejectsInvalidCredentials(t *testing.T) {
+	keys := auth.NewKeySet()
+	keys.AddHMAC("", testJWTSecret)
+	authenticator, err := auth.NewAuthenticator(auth.Config{Keys: keys, Issuer: "https://id.example.com"})
+	require.NoError(t, err)
+	app := newAuthApp(t, authenticator)
+
+	expired := signHS256(t, jwt.MapClaims{"sub": "alice", "iss": "https://id.example.com", "exp": time.Now().Add(-time.Hour).Unix()})
+	wrongIssuer := signHS256(t, jwt.MapClaims{"sub": "alice", "iss": "https://other.example.com", "exp": time.Now().Add(time.Hour).Unix()})
+	noExpiry := signHS256(t, jwt.MapClaims{"sub": "alice", "iss": "https://id.example.com"})
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}).SignedString([]byte("guess"))
+	require.NoError(t, err)
+
+	cases := map[string]map[string]string{
+		"missing":      nil,
+		"expired":      {"Authorization": "Bearer " + expired},
+		"wrong issuer": {"Authorization": "Bearer " + wrongIssuer},
+		"no expiry":    {"Authorization": "Bearer " + noExpiry},
+		"forged":       {"Authorization": "Bearer " + forged},
+		"basic auth":   {"Authorization": "Basic YWxpY2U6c2VjcmV0"},
+		"api key":      {"X-API-Key": "unknown"},
+	}
+	for name, headers := range cases {
+		resp, body := get(t, app, "/whoami", headers)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, name)
+		assert.Contains(t, resp.Header.Get("WWW-Authenticate"), "Bearer", name)
+		assert.Contains(t, body, `"error"`, name)
+	}
+}
+
+func TestAuth_RS256WithJWKSFile(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "key-1",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+	}}})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks, 0o600))
+
+	keys := auth.NewKeySet()
+	require.NoError(t, keys.LoadJWKS(path))
+	authenticator, err := auth.NewAuthenticator(auth.Config{Keys: keys})
+	require.NoError(t, err)
+	app := newAuthApp(t, authenticator)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "svc-catalog", "exp": time.Now().Add(time.Hour).Unix()})
+	token.Header["kid"] = "key-1"
+	signed, err := token.SignedString(privateKey)
+	require.NoError(t, err)
+
+	resp, body := get(t, app, "/whoami", map[string]string{"Authorization": "Bearer " + signed})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, body, `"Subject":"svc-catalog"`)
+
+	// Token HS256 yang ditandatangani dengan public key tidak boleh diterima
+	confused := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "mallory", "exp": time.Now().Add(time.Hour).Unix()})
+	confused.Header["kid"] = "key-1"
+	signed, err = confused.SignedString(privateKey.N.Bytes())
+	require.NoError(t, err)
+	resp, _ = get(t, app, "/whoami", map[string]string{"Authorization": "Bearer " + signed})
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
@@ -0,0 +1,123 @@
+package handler_test
+
+import (
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/adapter/routes"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newConditionalApp(productRepoMock *ProductRepositoryMock, config rest.CacheControlConfig) *fiber.App {
+	productHandler := rest.NewProductHandlerMySQL(service.NewProductService(productRepoMock))
+
+	app := fiber.New()
+	apiVersions := routes.NewAPIVersions(app, "v1", routes.APIVersion{Name: "v1"}, routes.APIVersion{Name: "v2"})
+	app.Use(rest.CacheControl(config))
+	apiVersions.Handle("v1", func(api fiber.Router) {
+		routes.ProductRoutesMySQL(api, productHandler)
+	})
+	apiVersions.Handle("v2", func(api fiber.Router) {
+		routes.ProductRoutesMySQLV2(api, productHandler)
+	})
+	return app
+}
+
+func TestConditionalGet_CreateIgnoresPreconditions(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("Create", mock.AnythingOfType("*entity.Product")).Run(func(args mock.Arguments) {
+		product := args.Get(0).(*entity.Product)
+		product.ID = "65f1c0a2b3c4d5e6f7a8b9c0"
+		product.UpdatedAt = time.Date(2026, time.October, 1, 8, 30, 0, 0, time.UTC)
+	}).Return(nil)
+	app := fiber.New()
+	routes.ProductRoutesMongodb(app, rest.NewProductHandlerMongo(service.NewProductService(productRepoMock)))
+
+	// Produk sudah tersimpan, jadi POST tidak boleh dijawab 304
+	req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{"name":"Product A","stock":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Modified-Since", "Thu, 01 Oct 2026 09:00:00 GMT")
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, getResponseBody(t, resp), `"name":"Product A"`)
+	productRepoMock.AssertCalled(t, "Create", mock.AnythingOfType("*entity.Product"))
+}
+
+func TestConditionalGet_Product(t *testing.T) {
+	updatedAt := time.Date(2026, time.October, 1, 8, 30, 0, 500_000_000, time.UTC)
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("GetByID", "1").Return(&entity.Product{ID: "1", Name: "Product A", Stock: 10, UpdatedAt: updatedAt}, nil)
+	app := newConditionalApp(productRepoMock, rest.DefaultCacheControlConfig())
+
+	resp, _ := get(t, app, "/products/1", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	assert.Regexp(t, `^W/"[0-9a-f]{32}"$`, etag)
+	assert.Equal(t, "Thu, 01 Oct 2026 08:30:00 GMT", resp.Header.Get("Last-Modified"))
+	assert.Equal(t, "private, no-cache", resp.Header.Get("Cache-Control"))
+
+	// ETag yang sama menghasilkan 304 tanpa body
+	resp, body := get(t, app, "/products/1", map[string]string{"If-None-Match": `"other", ` + etag})
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	assert.Empty(t, body)
+	assert.Equal(t, etag, resp.Header.Get("ETag"))
+	assert.Equal(t, "private, no-cache", resp.Header.Get("Cache-Control"))
+
+	resp, _ = get(t, app, "/products/1", map[string]string{"If-None-Match": `W/"other"`})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// If-Modified-Since hanya dipakai jika If-None-Match tidak dikirim
+	resp, _ = get(t, app, "/products/1", map[string]string{"If-Modified-Since": "Thu, 01 Oct 2026 08:30:00 GMT"})
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	resp, _ = get(t, app, "/products/1", map[string]string{"If-Modified-Since": "Thu, 01 Oct 2026 08:29:59 GMT"})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = get(t, app, "/products/1", map[string]string{
+		"If-None-Match":     `W/"other"`,
+		"If-Modified-Since": "Thu, 01 Oct 2026 08:30:00 GMT",
+	})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestConditionalGet_ListsAndCacheControlConfig(t *testing.T) {
+	updatedAt := time.Date(2026, time.October, 1, 8, 30, 0, 0, time.UTC)
+	products := []entity.Product{
+		{ID: "1", Name: "Product A", Stock: 10, UpdatedAt: updatedAt},
+		{ID: "2", Name: "Product B", Stock: 5, UpdatedAt: updatedAt.Add(time.Hour)},
+	}
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("List").Return(products, nil).Once()
+	productRepoMock.On("List").Return(products[:1], nil)
+	productRepoMock.On("ListPage", mock.Anything).Return(&entity.ProductPage{Products: products, Total: 2, Limit: 20}, nil)
+	config := rest.DefaultCacheControlConfig()
+	config.Routes["/api/v2/products"] = "private, max-age=30"
+	app := newConditionalApp(productRepoMock, config)
+
+	resp, _ := get(t, app, "/api/v1/products", nil)
+	etag := resp.Header.Get("ETag")
+	assert.Equal(t, "Thu, 01 Oct 2026 09:30:00 GMT", resp.Header.Get("Last-Modified"))
+	assert.Equal(t, "private, no-cache", resp.Header.Get("Cache-Control"))
+
+	// Menghapus produk mengubah ETag walaupun Last-Modified tidak maju
+	resp, _ = get(t, app, "/api/v1/products", map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEqual(t, etag, resp.Header.Get("ETag"))
+	resp, _ = get(t, app, "/api/v1/products", map[string]string{"If-Modified-Since": "Thu, 01 Oct 2026 10:00:00 GMT"})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Route yang lebih spesifik menang atas wildcard
+	resp, _ = get(t, app, "/api/v2/products", nil)
+	assert.Equal(t, "private, max-age=30", resp.Header.Get("Cache-Control"))
+	resp, _ = get(t, app, "/api/v2/products", map[string]string{"If-None-Match": resp.Header.Get("ETag")})
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	assert.Equal(t, "private, max-age=30", resp.Header.Get("Cache-Control"))
+}
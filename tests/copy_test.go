@@ -0,0 +1,159 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-hexagon/internal/adapter/repository"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/migration"
+	"go-hexagon/internal/core/port"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Target yang gagal setelah sejumlah batch, untuk mensimulasikan copy yang
+// terputus
+type failingTarget struct {
+	*repository.ProductRepositoryMemory
+	batches int
+}
+
+func (r *failingTarget) CreateMany(ctx context.Context, products []entity.Product) error {
+	if r.batches == 0 {
+		return errors.New("connection reset")
+	}
+	r.batches--
+	return r.ProductRepositoryMemory.CreateMany(ctx, products)
+}
+
+func seedProducts(t *testing.T, repo port.ProductRepository, n int) {
+	for i := 1; i <= n; i++ {
+		require.NoError(t, repo.Create(context.Background(), &entity.Product{Name: fmt.Sprintf("Product %d", i), Stock: i}))
+	}
+}
+
+func newTestCopier(source, target port.ProductRepository, ids port.ProductIDMap, checkpoint string) *migration.Copier {
+	copier := migration.NewCopier(source, target, ids, migration.CopyConfig{Source: "mysql", Target: "mongodb", BatchSize: 2, CheckpointFile: checkpoint})
+	copier.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	return copier
+}
+
+func TestCopyProducts(t *testing.T) {
+	source := repository.NewProductRepositoryMemory()
+	seedProducts(t, source, 5)
+	target := repository.NewProductRepositoryMemory()
+	// ID target digeser agar berbeda dari ID source
+	seedProducts(t, target, 3)
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, target.Delete(context.Background(), id))
+	}
+	ids := repository.NewProductIDMapMemory()
+
+	result, err := newTestCopier(source, target, ids, "").Run(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.Verified)
+	assert.Equal(t, 5, result.Copied)
+	assert.Equal(t, 5, result.Target.Count)
+	assert.Equal(t, result.Source, result.Target)
+
+	mapped, err := ids.Lookup(context.Background(), []string{"1", "5"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"1": "4", "5": "8"}, mapped)
+	product, err := target.GetByID(context.Background(), "8")
+	require.NoError(t, err)
+	assert.Equal(t, "Product 5", product.Name)
+	assert.Equal(t, 5, product.Stock)
+}
+
+func TestCopyProductsWithoutBatchInsert(t *testing.T) {
+	source := repository.NewProductRepositoryMemory()
+	seedProducts(t, source, 3)
+	target := NewProductRepositoryFake("mongo-")
+
+	result, err := newTestCopier(source, target, repository.NewProductIDMapMemory(), "").Run(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.Verified)
+	assert.Equal(t, 3, result.Copied)
+}
+
+func TestCopyProductsResumesFromCheckpoint(t *testing.T) {
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+	source := repository.NewProductRepositoryMemory()
+	seedProducts(t, source, 5)
+	target := &failingTarget{ProductRepositoryMemory: repository.NewProductRepositoryMemory(), batches: 1}
+	ids := repository.NewProductIDMapMemory()
+
+	// Batch kedua gagal, batch pertama tetap tercatat di checkpoint
+	_, err := newTestCopier(source, target, ids, checkpointFile).Run(context.Background())
+	require.Error(t, err)
+
+	data, err := os.ReadFile(checkpointFile)
+	require.NoError(t, err)
+	var checkpoint migration.Checkpoint
+	require.NoError(t, json.Unmarshal(data, &checkpoint))
+	assert.Equal(t, 2, checkpoint.Offset)
+	assert.Equal(t, 2, checkpoint.Copied)
+	assert.False(t, checkpoint.Done)
+
+	target.batches = -1
+	result, err := newTestCopier(source, target, ids, checkpointFile).Run(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.Verified)
+	assert.Equal(t, 5, result.Copied)
+	assert.Equal(t, 0, result.Skipped)
+	assert.Equal(t, 5, result.Target.Count)
+}
+
+func TestCopyProductsSkipsMappedProducts(t *testing.T) {
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+	source := repository.NewProductRepositoryMemory()
+	seedProducts(t, source, 3)
+	target := repository.NewProductRepositoryMemory()
+	ids := repository.NewProductIDMapMemory()
+
+	_, err := newTestCopier(source, target, ids, checkpointFile).Run(context.Background())
+	require.NoError(t, err)
+
+	// Copy ulang hanya menyalin produk yang dibuat sesudahnya
+	require.NoError(t, source.Create(context.Background(), &entity.Product{Name: "Product 4", Stock: 4}))
+	result, err := newTestCopier(source, target, ids, checkpointFile).Run(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.Verified)
+	assert.Equal(t, 4, result.Copied)
+	assert.Equal(t, 3, result.Skipped)
+	assert.Equal(t, 4, result.Target.Count)
+}
+
+func TestCopyProductsDetectsDivergence(t *testing.T) {
+	source := repository.NewProductRepositoryMemory()
+	seedProducts(t, source, 3)
+	target := repository.NewProductRepositoryMemory()
+	ids := repository.NewProductIDMapMemory()
+
+	_, err := newTestCopier(source, target, ids, "").Run(context.Background())
+	require.NoError(t, err)
+
+	// Produk yang tidak dipetakan di target membuat hasil tidak terverifikasi
+	require.NoError(t, target.Create(context.Background(), &entity.Product{Name: "Extra", Stock: 1}))
+	result, err := newTestCopier(source, target, ids, "").Run(context.Background())
+	require.NoError(t, err)
+	assert.False(t, result.Verified)
+	assert.Equal(t, 3, result.Source.Count)
+	assert.Equal(t, 4, result.Target.Count)
+}
+
+func TestCopyProductsRejectsForeignCheckpoint(t *testing.T) {
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+	require.NoError(t, os.WriteFile(checkpointFile, []byte(`{"source":"postgres","target":"mongodb","offset":2}`), 0o644))
+
+	_, err := newTestCopier(repository.NewProductRepositoryMemory(), repository.NewProductRepositoryMemory(), repository.NewProductIDMapMemory(), checkpointFile).Run(context.Background())
+	assert.ErrorContains(t, err, "postgres")
+}
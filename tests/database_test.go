@@ -0,0 +1,132 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"go-hexagon/internal/adapter/database"
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/adapter/logging"
+	"go-hexagon/internal/core/domain/entity"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// closedAddr mengembalikan alamat lokal yang menolak koneksi
+func closedAddr(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+	return addr
+}
+
+func TestDatabase_ConnectRetriesUntilMaxWait(t *testing.T) {
+	dsn := "root:@tcp(" + closedAddr(t) + ")/db_store_go"
+	config := &gorm.Config{Logger: logger.Discard}
+	retry := database.RetryConfig{MaxWait: 300 * time.Millisecond, BaseBackoff: 50 * time.Millisecond, MaxBackoff: 100 * time.Millisecond}
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.FormatJSON, "info")
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = database.ConnectSQL(context.Background(), logger, database.BackendMySQL, dsn, config, database.DefaultSQLPoolConfig(), retry)
+	require.Error(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), retry.MaxWait)
+	assert.Contains(t, err.Error(), "connect to mysql: giving up after")
+	assert.Contains(t, err.Error(), "connection refused")
+
+	// Setiap percobaan yang gagal dicatat ke logger yang diberikan
+	lines := logLines(t, &buf)
+	require.NotEmpty(t, lines)
+	assert.Equal(t, "WARN", lines[0]["level"])
+	assert.Equal(t, "Database not reachable, retrying", lines[0]["msg"])
+	assert.Equal(t, "mysql", lines[0]["db"])
+	assert.Equal(t, float64(1), lines[0]["attempt"])
+
+	// MaxWait 0 hanya mencoba sekali
+	_, err = database.ConnectSQL(context.Background(), logger, database.BackendMySQL, dsn, config, database.DefaultSQLPoolConfig(), database.RetryConfig{})
+	assert.ErrorContains(t, err, "giving up after 1 attempts")
+
+	// Context yang dibatalkan menghentikan retry
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	retry.MaxWait = time.Minute
+	_, err = database.ConnectSQL(ctx, logger, database.BackendMySQL, dsn, config, database.DefaultSQLPoolConfig(), retry)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestDatabase_PoolConfig(t *testing.T) {
+	db, err := sql.Open("mysql", "root:@tcp("+closedAddr(t)+")/db_store_go")
+	require.NoError(t, err)
+	defer db.Close()
+
+	database.SQLPoolConfig{MaxOpenConns: 7, MaxIdleConns: 3}.Apply(db)
+	assert.Equal(t, 7, db.Stats().MaxOpenConnections)
+
+	opts := database.MongoPoolConfig{MinPoolSize: 2, MaxPoolSize: 20, ServerSelectionTimeout: time.Second}.Options()
+	assert.Equal(t, uint64(2), *opts.MinPoolSize)
+	assert.Equal(t, uint64(20), *opts.MaxPoolSize)
+	assert.Equal(t, time.Second, *opts.ServerSelectionTimeout)
+	// Nilai nol memakai default driver
+	assert.Nil(t, opts.ConnectTimeout)
+}
+
+func openTestReplica(t *testing.T) *gorm.DB {
+	db, err := database.OpenReplica(database.BackendMySQL, "root:@tcp("+closedAddr(t)+")/db_store_go", &gorm.Config{Logger: logger.Discard}, database.SQLPoolConfig{})
+	require.NoError(t, err)
+	return db
+}
+
+func sqlConn(t *testing.T, db *gorm.DB) *sql.DB {
+	conn, err := db.DB()
+	require.NoError(t, err)
+	return conn
+}
+
+func TestDatabase_ReplicaRouting(t *testing.T) {
+	primary, replica0, replica1 := openTestReplica(t), openTestReplica(t), openTestReplica(t)
+	replicas := database.NewReplicaSet(primary, []*gorm.DB{replica0, replica1}, database.ReplicaConfig{HealthInterval: time.Hour, HealthTimeout: 100 * time.Millisecond})
+
+	// Read bergantian ke replica, write selalu ke primary
+	ctx := entity.WithReadYourWrites(context.Background(), false)
+	first := sqlConn(t, replicas.Reader(ctx))
+	second := sqlConn(t, replicas.Reader(ctx))
+	assert.ElementsMatch(t, []*sql.DB{sqlConn(t, replica0), sqlConn(t, replica1)}, []*sql.DB{first, second})
+	assert.Same(t, first, sqlConn(t, replicas.Reader(ctx)))
+
+	// Setelah write, read dalam request yang sama ke primary
+	assert.Same(t, sqlConn(t, primary), sqlConn(t, replicas.Writer(ctx)))
+	assert.Same(t, sqlConn(t, primary), sqlConn(t, replicas.Reader(ctx)))
+	assert.NotSame(t, sqlConn(t, primary), sqlConn(t, replicas.Reader(context.Background())))
+
+	// Replica yang gagal health check dilewati
+	replicas.Start()
+	defer replicas.Stop()
+	assert.Equal(t, 0, replicas.Healthy())
+	assert.Same(t, sqlConn(t, primary), sqlConn(t, replicas.Reader(context.Background())))
+}
+
+func TestDatabase_ReadYourWritesHeader(t *testing.T) {
+	app := fiber.New()
+	app.Use(rest.ReadYourWrites())
+	app.Post("/products", func(c *fiber.Ctx) error {
+		before := entity.ReadFromPrimary(c.UserContext())
+		entity.MarkWritten(c.UserContext())
+		return c.JSON([]bool{before, entity.ReadFromPrimary(c.UserContext())})
+	})
+
+	_, body := send(t, app, http.MethodPost, "/products", nil)
+	assert.JSONEq(t, `[false, true]`, body)
+
+	_, body = send(t, app, http.MethodPost, "/products", map[string]string{rest.HeaderReadYourWrites: "true"})
+	assert.JSONEq(t, `[true, true]`, body)
+}
@@ -0,0 +1,251 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-hexagon/internal/adapter/metrics"
+	"go-hexagon/internal/adapter/repository"
+	"go-hexagon/internal/core/domain/entity"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Repository produk in-memory untuk pengujian. ID diberi prefix agar ID dua
+// backend tidak pernah sama, seperti ID MySQL dan ObjectID MongoDB.
+type ProductRepositoryFake struct {
+	mu       sync.Mutex
+	prefix   string
+	nextID   int
+	products map[string]entity.Product
+	// Err, jika diisi, dikembalikan oleh semua write
+	Err error
+}
+
+func NewProductRepositoryFake(prefix string) *ProductRepositoryFake {
+	return &ProductRepositoryFake{prefix: prefix, products: map[string]entity.Product{}}
+}
+
+func (r *ProductRepositoryFake) Create(ctx context.Context, product *entity.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	r.nextID++
+	product.ID = fmt.Sprintf("%s%d", r.prefix, r.nextID)
+	r.products[product.ID] = *product
+	return nil
+}
+
+func (r *ProductRepositoryFake) Update(ctx context.Context, product *entity.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	if _, ok := r.products[product.ID]; !ok {
+		return entity.ErrProductNotFound
+	}
+	r.products[product.ID] = *product
+	return nil
+}
+
+func (r *ProductRepositoryFake) GetByID(ctx context.Context, id string) (*entity.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !strings.HasPrefix(id, r.prefix) {
+		return nil, entity.ErrInvalidID
+	}
+	product, ok := r.products[id]
+	if !ok {
+		return nil, entity.ErrProductNotFound
+	}
+	return &product, nil
+}
+
+func (r *ProductRepositoryFake) GetByIDs(ctx context.Context, ids []string) ([]entity.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var products []entity.Product
+	for _, id := range ids {
+		if product, ok := r.products[id]; ok {
+			products = append(products, product)
+		}
+	}
+	return products, nil
+}
+
+func (r *ProductRepositoryFake) List(ctx context.Context) ([]entity.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	products := make([]entity.Product, 0, len(r.products))
+	for _, product := range r.products {
+		products = append(products, product)
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return products, nil
+}
+
+func (r *ProductRepositoryFake) ListPage(ctx context.Context, query entity.ProductQuery) (*entity.ProductPage, error) {
+	products, _ := r.List(ctx)
+	page := &entity.ProductPage{Offset: query.Offset, Limit: query.Limit, Total: int64(len(products))}
+	if query.Offset < len(products) {
+		page.Products = products[query.Offset:min(query.Offset+query.Limit, len(products))]
+	}
+	return page, nil
+}
+
+func (r *ProductRepositoryFake) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Err != nil {
+		return r.Err
+	}
+	if _, ok := r.products[id]; !ok {
+		return entity.ErrProductNotFound
+	}
+	delete(r.products, id)
+	return nil
+}
+
+func newDualWrite(config repository.DualWriteConfig) (*repository.DualWriteProductRepository, *ProductRepositoryFake, *ProductRepositoryFake, *[]repository.ProductDivergence) {
+	primary, secondary := NewProductRepositoryFake("my-"), NewProductRepositoryFake("mongo-")
+	repo := repository.NewDualWriteProductRepository(primary, secondary, repository.NewProductIDMapMemory(), config)
+	repo.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	var divergences []repository.ProductDivergence
+	repo.OnDivergence = func(ctx context.Context, divergence repository.ProductDivergence) {
+		divergences = append(divergences, divergence)
+	}
+	return repo, primary, secondary, &divergences
+}
+
+func TestDualWrite_MirrorsWritesWithIDMapping(t *testing.T) {
+	ctx := context.Background()
+	repo, primary, secondary, divergences := newDualWrite(repository.DualWriteConfig{ReadFrom: repository.SidePrimary})
+
+	product := &entity.Product{Name: "Product A", Stock: 10}
+	require.NoError(t, repo.Create(ctx, product))
+	assert.Equal(t, "my-1", product.ID)
+	assert.Equal(t, "Product A", secondary.products["mongo-1"].Name)
+
+	mapped, err := repo.IDs.Lookup(ctx, []string{"my-1"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"my-1": "mongo-1"}, mapped)
+
+	product.Stock = 7
+	require.NoError(t, repo.Update(ctx, product))
+	assert.Equal(t, 7, secondary.products["mongo-1"].Stock)
+
+	require.NoError(t, repo.Delete(ctx, "my-1"))
+	assert.Empty(t, primary.products)
+	assert.Empty(t, secondary.products)
+	mapped, _ = repo.IDs.Lookup(ctx, []string{"my-1"})
+	assert.Empty(t, mapped)
+
+	// Error primary dikembalikan dan secondary tidak disentuh
+	assert.ErrorIs(t, repo.Delete(ctx, "my-1"), entity.ErrProductNotFound)
+	assert.Empty(t, *divergences)
+}
+
+func TestDualWrite_SecondaryFailuresAreDivergences(t *testing.T) {
+	ctx := context.Background()
+	repo, primary, secondary, divergences := newDualWrite(repository.DualWriteConfig{ReadFrom: repository.SidePrimary, CompareReads: true})
+	registry := metrics.New()
+	countDivergence, record := registry.DualWriteDivergences(), repo.OnDivergence
+	repo.OnDivergence = func(ctx context.Context, divergence repository.ProductDivergence) {
+		countDivergence(divergence.Kind, divergence.Operation)
+		record(ctx, divergence)
+	}
+
+	// Write ke secondary gagal, tetapi request tetap berhasil
+	secondary.Err = errors.New("connection refused")
+	product := &entity.Product{Name: "Product A", Stock: 10}
+	require.NoError(t, repo.Create(ctx, product))
+	require.Len(t, *divergences, 1)
+	assert.Equal(t, repository.ProductDivergence{Kind: repository.DivergenceWriteFailed, Operation: "Create", ID: "my-1", Detail: "connection refused"}, (*divergences)[0])
+
+	// Update berikutnya menyalin produk yang belum ada di secondary
+	secondary.Err = nil
+	product.Stock = 8
+	require.NoError(t, repo.Update(ctx, product))
+	assert.Equal(t, repository.DivergenceMissing, (*divergences)[1].Kind)
+	assert.Equal(t, 8, secondary.products["mongo-1"].Stock)
+
+	// Perbedaan field terdeteksi saat read dibandingkan
+	secondary.products["mongo-1"] = entity.Product{ID: "mongo-1", Name: "Product A", Stock: 3}
+	got, err := repo.GetByID(ctx, "my-1")
+	require.NoError(t, err)
+	assert.Equal(t, 8, got.Stock)
+	assert.Equal(t, repository.ProductDivergence{Kind: repository.DivergenceMismatch, Operation: "GetByID", ID: "my-1", SecondaryID: "mongo-1", Detail: "stock 8 != 3"}, (*divergences)[2])
+
+	expected := `
+# HELP go_hexagon_dual_write_divergences_total Differences between the primary and secondary product store by kind and operation.
+# TYPE go_hexagon_dual_write_divergences_total counter
+go_hexagon_dual_write_divergences_total{kind="mismatch",operation="GetByID"} 1
+go_hexagon_dual_write_divergences_total{kind="missing",operation="Update"} 1
+go_hexagon_dual_write_divergences_total{kind="write_failed",operation="Create"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry.Registry, strings.NewReader(expected), "go_hexagon_dual_write_divergences_total"))
+	assert.Len(t, primary.products, 1)
+}
+
+func TestDualWrite_ReadsFromSecondary(t *testing.T) {
+	ctx := context.Background()
+	repo, primary, secondary, divergences := newDualWrite(repository.DualWriteConfig{ReadFrom: repository.SideSecondary})
+	for _, name := range []string{"Product A", "Product B"} {
+		require.NoError(t, repo.Create(ctx, &entity.Product{Name: name, Stock: 1}))
+	}
+	// Nilai berbeda di secondary membuktikan read dilayani dari secondary
+	secondary.products["mongo-2"] = entity.Product{ID: "mongo-2", Name: "Product B (mongo)", Stock: 1}
+
+	// ID yang dikembalikan selalu ID primary
+	product, err := repo.GetByID(ctx, "my-2")
+	require.NoError(t, err)
+	assert.Equal(t, &entity.Product{ID: "my-2", Name: "Product B (mongo)", Stock: 1}, product)
+
+	products, err := repo.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"my-1", "my-2"}, productIDs(products))
+
+	// Produk yang hilang di secondary dibaca dari primary
+	delete(secondary.products, "mongo-1")
+	product, err = repo.GetByID(ctx, "my-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Product A", product.Name)
+	products, err = repo.GetByIDs(ctx, []string{"my-1", "my-2"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"my-1", "my-2"}, productIDs(products))
+
+	// Produk di secondary tanpa pasangan di primary tidak ikut dikembalikan
+	secondary.products["mongo-9"] = entity.Product{ID: "mongo-9", Name: "Orphan"}
+	page, err := repo.ListPage(ctx, entity.ProductQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"my-2"}, productIDs(page.Products))
+
+	kinds := make([]string, 0, len(*divergences))
+	for _, divergence := range *divergences {
+		kinds = append(kinds, divergence.Kind)
+	}
+	assert.Equal(t, []string{repository.DivergenceMissing, repository.DivergenceMissing, repository.DivergenceUnmapped}, kinds)
+
+	_, err = repo.GetByID(ctx, "my-404")
+	assert.ErrorIs(t, err, entity.ErrProductNotFound)
+	assert.Len(t, primary.products, 2)
+}
+
+func productIDs(products []entity.Product) []string {
+	ids := make([]string, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+	return ids
+}
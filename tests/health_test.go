@@ -0,0 +1,143 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-hexagon/internal/adapter/cache"
+	"go-hexagon/internal/adapter/health"
+	"go-hexagon/internal/adapter/routes"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newHealthApp(checks *health.Health) *fiber.App {
+	app := fiber.New()
+	routes.HealthRoutes(app, checks)
+	return app
+}
+
+func getHealthReport(t *testing.T, app *fiber.App, path string) (int, health.Report) {
+	resp, body := send(t, app, http.MethodGet, path, nil)
+	var report health.Report
+	require.NoError(t, json.Unmarshal([]byte(body), &report))
+	return resp.StatusCode, report
+}
+
+func TestHealth_ReadinessAggregatesCheckers(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	checks := health.New()
+	checks.Timeout = 50 * time.Millisecond
+	checks.AddReadiness("redis", health.Redis(client))
+	checks.AddReadiness("database", health.CheckerFunc(func(ctx context.Context) error { return nil }))
+	app := newHealthApp(checks)
+
+	status, report := getHealthReport(t, app, "/readyz")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, health.StatusOK, report.Status)
+	assert.Len(t, report.Components, 2)
+	assert.Equal(t, health.StatusOK, report.Components["redis"].Status)
+	assert.GreaterOrEqual(t, report.Components["redis"].LatencyMS, 0.0)
+
+	// Satu komponen gagal membuat readiness gagal, komponen lain tetap dilaporkan
+	server.Close()
+	checks.AddReadiness("cache", health.CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	status, report = getHealthReport(t, app, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, health.StatusFailing, report.Status)
+	assert.Equal(t, health.StatusFailing, report.Components["redis"].Status)
+	assert.NotEmpty(t, report.Components["redis"].Error)
+	assert.Equal(t, health.StatusOK, report.Components["database"].Status)
+	// Checker yang menggantung dihentikan oleh timeout
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Components["cache"].Error)
+}
+
+func TestHealth_LivenessAndDraining(t *testing.T) {
+	checks := health.New()
+	checks.AddReadiness("database", health.CheckerFunc(func(ctx context.Context) error { return nil }))
+	app := newHealthApp(checks)
+
+	// Liveness tanpa checker selalu sehat
+	status, report := getHealthReport(t, app, "/healthz")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, health.StatusOK, report.Status)
+	assert.Empty(t, report.Components)
+
+	// Saat draining readiness gagal walaupun semua komponen sehat
+	checks.Drain()
+	status, report = getHealthReport(t, app, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, health.StatusDraining, report.Status)
+	assert.Equal(t, health.StatusOK, report.Components["database"].Status)
+
+	status, _ = getHealthReport(t, app, "/healthz")
+	assert.Equal(t, http.StatusOK, status)
+
+	checks.AddLiveness("deadlock", health.CheckerFunc(func(ctx context.Context) error { return errors.New("stuck") }))
+	status, report = getHealthReport(t, app, "/healthz")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "stuck", report.Components["deadlock"].Error)
+}
+
+func TestHealth_WebhookDispatcher(t *testing.T) {
+	dispatcher := newTestDispatcher(NewWebhookRepositoryFake())
+	assert.NoError(t, dispatcher.Check(context.Background()))
+
+	dispatcher.Stop()
+	assert.EqualError(t, dispatcher.Check(context.Background()), "dispatcher is stopped")
+}
+
+func TestHealth_DeprecatedDatabaseChecks(t *testing.T) {
+	checks := health.New()
+	checks.AddReadiness("mysql", health.CheckerFunc(func(ctx context.Context) error { return errors.New("connection refused") }))
+	checks.AddReadiness("cache", health.CheckerFunc(func(ctx context.Context) error { return nil }))
+	app := newHealthApp(checks)
+
+	// Alias lama hanya menjalankan check database dan menandai dirinya deprecated
+	resp, body := send(t, app, http.MethodGet, "/check-mysql", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Deprecation"))
+	assert.Equal(t, `</readyz>; rel="successor-version"`, resp.Header.Get("Link"))
+	var report health.Report
+	require.NoError(t, json.Unmarshal([]byte(body), &report))
+	assert.Len(t, report.Components, 1)
+	assert.Equal(t, "connection refused", report.Components["mysql"].Error)
+
+	// Backend yang tidak dipakai server tidak ditemukan
+	resp, _ = send(t, app, http.MethodGet, "/check-mongo", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Deprecation"))
+
+	checks.AddReadiness("mongodb", health.CheckerFunc(func(ctx context.Context) error { return nil }))
+	status, report := getHealthReport(t, app, "/check-mongo")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, health.StatusOK, report.Components["mongodb"].Status)
+}
+
+// Cache yang membuang setiap write, seperti store bersama yang penuh
+type droppingCache struct{ *cache.LRU }
+
+func (droppingCache) Set(key string, value []byte, ttl time.Duration) {}
+
+func TestHealth_CacheChecker(t *testing.T) {
+	store := cache.NewLRU(10)
+	assert.NoError(t, health.Cache(store).Check(context.Background()))
+	// Nilai probe tidak tertinggal di cache
+	_, ok := store.Get("health:probe")
+	assert.False(t, ok)
+
+	assert.ErrorContains(t, health.Cache(droppingCache{store}).Check(context.Background()), "cache did not return")
+}
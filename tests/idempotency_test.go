@@ -0,0 +1,148 @@
+package handler_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/adapter/idempotency"
+	"go-hexagon/internal/adapter/routes"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// Idempotency store in-memory untuk pengujian
+type IdempotencyStoreFake struct {
+	mu      sync.Mutex
+	records map[string]entity.IdempotencyRecord
+}
+
+func NewIdempotencyStoreFake() *IdempotencyStoreFake {
+	return &IdempotencyStoreFake{records: map[string]entity.IdempotencyRecord{}}
+}
+
+func (s *IdempotencyStoreFake) Reserve(record *entity.IdempotencyRecord) (*entity.IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.records[record.Key]; ok && existing.ExpiresAt.After(time.Now()) {
+		return &existing, false, nil
+	}
+	s.records[record.Key] = *record
+	return nil, true, nil
+}
+
+func (s *IdempotencyStoreFake) Complete(record *entity.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.Key] = *record
+	return nil
+}
+
+func (s *IdempotencyStoreFake) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+func (s *IdempotencyStoreFake) DeleteExpired(now time.Time) error {
+	return nil
+}
+
+func newIdempotentApp(productRepoMock *ProductRepositoryMock, store *IdempotencyStoreFake) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if subject := c.Get("X-Subject"); subject != "" {
+			principal := entity.Principal{Subject: subject, Method: entity.AuthMethodAPIKey}
+			c.SetUserContext(entity.WithPrincipal(c.UserContext(), principal))
+		}
+		return c.Next()
+	})
+	app.Use(idempotency.New(store, time.Hour).Middleware())
+	routes.ProductRoutesMySQL(app, rest.NewProductHandlerMySQL(service.NewProductService(productRepoMock)))
+	return app
+}
+
+func postProduct(t *testing.T, app *fiber.App, key, subject, body string) (*http.Response, string) {
+	req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+	if subject != "" {
+		req.Header.Set("X-Subject", subject)
+	}
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	return resp, getResponseBody(t, resp)
+}
+
+func TestIdempotency_ReplaysFirstResponse(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	nextID := 0
+	productRepoMock.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		nextID++
+		args.Get(0).(*entity.Product).ID = fmt.Sprint(nextID)
+	}).Return(nil)
+	app := newIdempotentApp(productRepoMock, NewIdempotencyStoreFake())
+
+	resp, first := postProduct(t, app, "order-42", "billing", `{"name":"Product A","stock":10}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// Retry dengan key dan body yang sama tidak membuat produk baru
+	resp, replayed := postProduct(t, app, "order-42", "billing", `{"name":"Product A","stock":10}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "true", resp.Header.Get("Idempotent-Replayed"))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.JSONEq(t, first, replayed)
+	productRepoMock.AssertNumberOfCalls(t, "Create", 1)
+
+	// Key yang sama dengan body berbeda ditolak
+	resp, body := postProduct(t, app, "order-42", "billing", `{"name":"Product B","stock":10}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	assert.Contains(t, body, "different request")
+
+	// Key dicatat per client
+	resp, _ = postProduct(t, app, "order-42", "shop", `{"name":"Product A","stock":10}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Idempotent-Replayed"))
+	productRepoMock.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestIdempotency_ServerErrorsAndInFlightRequests(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("Create", mock.Anything).Return(fmt.Errorf("connection refused")).Once()
+	productRepoMock.On("Create", mock.Anything).Return(nil)
+	store := NewIdempotencyStoreFake()
+	app := newIdempotentApp(productRepoMock, store)
+
+	// Respons 5xx tidak disimpan sehingga retry dijalankan ulang
+	resp, _ := postProduct(t, app, "order-7", "", `{"name":"Product A","stock":1}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	resp, _ = postProduct(t, app, "order-7", "", `{"name":"Product A","stock":1}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	productRepoMock.AssertNumberOfCalls(t, "Create", 2)
+
+	// Request pertama dengan key yang sama masih berjalan
+	body := `{"name":"Product A","stock":1}`
+	hash := sha256.Sum256([]byte("POST /products\n" + body))
+	_, reserved, err := store.Reserve(&entity.IdempotencyRecord{
+		Key:         "anonymous:order-8",
+		RequestHash: hex.EncodeToString(hash[:]),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.True(t, reserved)
+	resp, _ = postProduct(t, app, "order-8", "", body)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	productRepoMock.AssertNumberOfCalls(t, "Create", 2)
+}
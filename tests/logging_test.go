@@ -0,0 +1,100 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/adapter/logging"
+	"go-hexagon/internal/adapter/routes"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logLines mengurai output JSON logger, satu record per baris.
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		lines = append(lines, record)
+	}
+	return lines
+}
+
+func TestLogging_AccessLogAndRepositoryErrorsShareRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.FormatJSON, "info")
+	require.NoError(t, err)
+
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("GetByID", "1").Return(nil, errors.New("connection refused"))
+	repo := logging.ProductRepository(productRepoMock, logger, "mongodb")
+	productService := service.NewProductService(repo, service.WithLogger(logger))
+
+	app := fiber.New()
+	app.Use(rest.RequestInfo())
+	app.Use(logging.AccessLog(logger))
+	app.Use(func(c *fiber.Ctx) error {
+		principal := entity.Principal{Subject: "alice", Method: entity.AuthMethodJWT}
+		c.SetUserContext(entity.WithPrincipal(c.UserContext(), principal))
+		return c.Next()
+	})
+	routes.ProductRoutesMySQL(app, rest.NewProductHandlerMySQL(productService))
+
+	resp, _ := get(t, app, "/products/1", map[string]string{"X-Request-ID": "req-42"})
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	lines := logLines(t, &buf)
+	require.Len(t, lines, 2)
+	repoLog, accessLog := lines[0], lines[1]
+
+	// Error database dicatat dengan request ID yang sama dengan access log
+	assert.Equal(t, "ERROR", repoLog["level"])
+	assert.Equal(t, "Repository call failed", repoLog["msg"])
+	assert.Equal(t, "GetByID", repoLog["method"])
+	assert.Equal(t, "connection refused", repoLog["error"])
+	assert.Equal(t, "req-42", repoLog["request_id"])
+
+	assert.Equal(t, "request", accessLog["msg"])
+	assert.Equal(t, "ERROR", accessLog["level"])
+	assert.Equal(t, "GET", accessLog["method"])
+	assert.Equal(t, "/products/:id", accessLog["route"])
+	assert.Equal(t, float64(500), accessLog["status"])
+	assert.Equal(t, "alice", accessLog["principal"])
+	assert.Equal(t, "req-42", accessLog["request_id"])
+	assert.Contains(t, accessLog, "latency_ms")
+}
+
+func TestLogging_GormLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.FormatJSON, "info")
+	require.NoError(t, err)
+	gormLogger := logging.NewGormLogger(logger)
+	ctx := entity.WithRequestInfo(context.Background(), entity.RequestInfo{ID: "req-7"})
+	statement := func() (string, int64) { return "SELECT * FROM `products` WHERE `id` = 1", 0 }
+
+	// Statement cepat yang berhasil hanya dicatat di level debug
+	gormLogger.Trace(ctx, time.Now(), statement, nil)
+	assert.Empty(t, buf.String())
+
+	gormLogger.Trace(ctx, time.Now(), statement, errors.New("Error 1146: Table doesn't exist"))
+	gormLogger.Trace(ctx, time.Now().Add(-time.Second), statement, nil)
+
+	lines := logLines(t, &buf)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "Database statement failed", lines[0]["msg"])
+	assert.Equal(t, "req-7", lines[0]["request_id"])
+	assert.Equal(t, "SELECT * FROM `products` WHERE `id` = 1", lines[0]["sql"])
+	assert.Equal(t, "Slow database statement", lines[1]["msg"])
+	assert.Equal(t, "WARN", lines[1]["level"])
+}
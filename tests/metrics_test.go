@@ -0,0 +1,94 @@
+package handler_test
+
+import (
+	"errors"
+	"go-hexagon/internal/adapter/cache"
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/adapter/metrics"
+	"go-hexagon/internal/adapter/repository"
+	"go-hexagon/internal/adapter/routes"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func TestMetrics_HTTPRepositoryAndCache(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("GetByID", "1").Return(&entity.Product{ID: "1", Name: "Product A", Stock: 10}, nil)
+	productRepoMock.On("GetByID", "9").Return(nil, entity.ErrProductNotFound)
+	productRepoMock.On("List").Return([]entity.Product(nil), errors.New("connection refused"))
+
+	m := metrics.New()
+	cached := repository.NewCachedProductRepository(m.ProductRepository(productRepoMock, "mysql"), cache.NewLRU(10), repository.DefaultCacheConfig())
+	m.RegisterProductCache(cached)
+
+	app := fiber.New()
+	app.Use(m.Middleware())
+	routes.ProductRoutesMySQL(app, rest.NewProductHandlerMySQL(service.NewProductService(cached)))
+	app.Get("/metrics", m.Handler())
+
+	get(t, app, "/products/1", nil)
+	get(t, app, "/products/1", nil)
+	get(t, app, "/products/9", nil)
+	get(t, app, "/products", nil)
+
+	resp, body := get(t, app, "/metrics", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Label route memakai pola route, bukan ID
+	assert.Contains(t, body, `go_hexagon_http_requests_total{method="GET",route="/products/:id",status="200"} 2`)
+	assert.Contains(t, body, `go_hexagon_http_requests_total{method="GET",route="/products/:id",status="404"} 1`)
+	assert.Contains(t, body, `go_hexagon_http_requests_total{method="GET",route="/products",status="500"} 1`)
+	assert.Contains(t, body, `go_hexagon_http_request_duration_seconds_count{method="GET",route="/products/:id",status="200"} 2`)
+
+	// Lookup kedua dilayani cache sehingga repository hanya dipanggil sekali untuk ID 1
+	assert.Contains(t, body, `go_hexagon_repository_call_duration_seconds_count{backend="mysql",method="GetByID"} 2`)
+	assert.Contains(t, body, `go_hexagon_repository_errors_total{backend="mysql",method="List"} 1`)
+	assert.NotContains(t, body, `go_hexagon_repository_errors_total{backend="mysql",method="GetByID"}`)
+	assert.Contains(t, body, "go_hexagon_product_cache_hits_total 1")
+	assert.Contains(t, body, "go_hexagon_product_cache_misses_total 3")
+}
+
+func TestMetrics_RegisterTwice(t *testing.T) {
+	m := metrics.New()
+
+	// Dua client MongoDB berbagi metrics pool yang sama, bukan panic
+	var first, second *event.PoolMonitor
+	assert.NotPanics(t, func() {
+		first = m.MongoPoolMonitor()
+		second = m.MongoPoolMonitor()
+	})
+	first.Event(&event.PoolEvent{Type: event.ConnectionCreated})
+	second.Event(&event.PoolEvent{Type: event.ConnectionCreated})
+	second.Event(&event.PoolEvent{Type: event.GetFailed})
+
+	var count, countAgain func(kind, operation string)
+	assert.NotPanics(t, func() {
+		count = m.DualWriteDivergences()
+		countAgain = m.DualWriteDivergences()
+	})
+	count("missing", "Update")
+	countAgain("missing", "Update")
+
+	expected := `
+# HELP go_hexagon_dual_write_divergences_total Differences between the primary and secondary product store by kind and operation.
+# TYPE go_hexagon_dual_write_divergences_total counter
+go_hexagon_dual_write_divergences_total{kind="missing",operation="Update"} 2
+# HELP go_hexagon_mongo_pool_checkout_failures_total Failed attempts to check a connection out of the MongoDB pool.
+# TYPE go_hexagon_mongo_pool_checkout_failures_total counter
+go_hexagon_mongo_pool_checkout_failures_total 1
+# HELP go_hexagon_mongo_pool_connections MongoDB driver connections by state (open or in_use).
+# TYPE go_hexagon_mongo_pool_connections gauge
+go_hexagon_mongo_pool_connections{state="in_use"} 0
+go_hexagon_mongo_pool_connections{state="open"} 2
+`
+	assert.NoError(t, testutil.GatherAndCompare(m.Registry, strings.NewReader(expected),
+		"go_hexagon_dual_write_divergences_total", "go_hexagon_mongo_pool_checkout_failures_total", "go_hexagon_mongo_pool_connections"))
+}
@@ -0,0 +1,144 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"go-hexagon/internal/adapter/eventstream"
+	"go-hexagon/internal/adapter/handler/graphql"
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/adapter/health"
+	"go-hexagon/internal/adapter/metrics"
+	"go-hexagon/internal/adapter/openapi"
+	"go-hexagon/internal/adapter/routes"
+	"go-hexagon/internal/core/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFullApp mendaftarkan semua route dari internal/adapter/routes
+func newFullApp(t *testing.T, mongo bool) *fiber.App {
+	productService := service.NewProductService(new(ProductRepositoryMock))
+	broker := eventstream.NewBroker(10)
+
+	graphqlHandler, err := graphql.NewHandler(productService)
+	require.NoError(t, err)
+	docsHandler, err := openapi.NewHandler()
+	require.NoError(t, err)
+
+	streamHandler := rest.NewProductStreamHandler(productService, broker)
+	webhookHandler := rest.NewWebhookHandler(service.NewWebhookService(NewWebhookRepositoryFake(), nil))
+	auditHandler := rest.NewAuditHandler(service.NewAuditService(NewAuditRepositoryFake(), nil))
+
+	app := fiber.New()
+	apiVersions := routes.NewAPIVersions(app, "v1", routes.APIVersion{Name: "v1"}, routes.APIVersion{Name: "v2"})
+	apiVersions.Handle("v1", func(api fiber.Router) {
+		routes.ProductStreamRoutes(api, streamHandler)
+		if mongo {
+			routes.ProductRoutesMongodb(api, rest.NewProductHandlerMongo(productService))
+		} else {
+			routes.ProductRoutesMySQL(api, rest.NewProductHandlerMySQL(productService))
+		}
+		routes.WebhookRoutes(api, webhookHandler)
+		routes.AuditRoutes(api, auditHandler)
+	})
+	apiVersions.Handle("v2", func(api fiber.Router) {
+		routes.ProductStreamRoutes(api, streamHandler)
+		if mongo {
+			routes.ProductRoutesMongodbV2(api, rest.NewProductHandlerMongo(productService))
+		} else {
+			routes.ProductRoutesMySQLV2(api, rest.NewProductHandlerMySQL(productService))
+		}
+		routes.WebhookRoutes(api, webhookHandler)
+		routes.AuditRoutes(api, auditHandler)
+	})
+	routes.GraphQLRoutes(app, graphqlHandler)
+	routes.DocsRoutes(app, docsHandler)
+	routes.HealthRoutes(app, health.New())
+	routes.MetricsRoutes(app, metrics.New())
+	return app
+}
+
+func fetchSpec(t *testing.T, app *fiber.App) map[string]interface{} {
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/openapi.json", nil), -1)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var spec map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(getResponseBody(t, resp)), &spec))
+	return spec
+}
+
+func specPath(route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func TestOpenAPI_EveryRouteIsDocumented(t *testing.T) {
+	for _, mongo := range []bool{false, true} {
+		app := newFullApp(t, mongo)
+		spec := fetchSpec(t, app)
+		paths := spec["paths"].(map[string]interface{})
+
+		for _, route := range app.GetRoutes(true) {
+			if route.Method == http.MethodHead {
+				continue
+			}
+			item, ok := paths[specPath(route.Path)].(map[string]interface{})
+			if !assert.True(t, ok, "route %s %s is missing from the OpenAPI spec", route.Method, route.Path) {
+				continue
+			}
+			assert.Contains(t, item, strings.ToLower(route.Method), "route %s %s is missing from the OpenAPI spec", route.Method, route.Path)
+		}
+	}
+}
+
+func TestOpenAPI_DocumentedRoutesExist(t *testing.T) {
+	app := newFullApp(t, false)
+	spec := fetchSpec(t, app)
+
+	registered := map[string]bool{}
+	for _, route := range app.GetRoutes(true) {
+		registered[route.Method+" "+specPath(route.Path)] = true
+	}
+
+	for path, item := range spec["paths"].(map[string]interface{}) {
+		for method := range item.(map[string]interface{}) {
+			assert.True(t, registered[strings.ToUpper(method)+" "+path], "documented route %s %s is not registered", method, path)
+		}
+	}
+}
+
+func TestOpenAPI_SchemasAndDocsPage(t *testing.T) {
+	app := newFullApp(t, false)
+	spec := fetchSpec(t, app)
+
+	assert.Equal(t, "3.0.3", spec["openapi"])
+	schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	productInput := schemas["ProductInput"].(map[string]interface{})
+	assert.ElementsMatch(t, []interface{}{"name", "stock"}, productInput["required"])
+	assert.Equal(t, []interface{}{"error"}, schemas["ErrorResponse"].(map[string]interface{})["required"])
+	assert.Contains(t, schemas, "HealthReport")
+
+	// Probe dan metrics dilayani tanpa kredensial
+	paths := spec["paths"].(map[string]interface{})
+	for _, path := range []string{"/healthz", "/readyz", "/metrics"} {
+		get := paths[path].(map[string]interface{})["get"].(map[string]interface{})
+		assert.Equal(t, []interface{}{}, get["security"], path)
+		assert.NotContains(t, get["responses"], "401", path)
+	}
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/docs", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, getResponseBody(t, resp), "openapi.json")
+}
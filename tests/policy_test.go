@@ -0,0 +1,159 @@
+package handler_test
+
+import (
+	"context"
+	"go-hexagon/internal/adapter/auth"
+	"go-hexagon/internal/adapter/eventstream"
+	"go-hexagon/internal/adapter/handler/graphql"
+	grpcadapter "go-hexagon/internal/adapter/handler/grpc"
+	"go-hexagon/internal/adapter/handler/grpc/productpb"
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/adapter/routes"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/policy"
+	"go-hexagon/internal/core/service"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newPolicyApp(t *testing.T, productRepoMock *ProductRepositoryMock, accessPolicy *policy.Policy) *fiber.App {
+	keys := auth.NewKeySet()
+	keys.AddHMAC("", testJWTSecret)
+	authenticator, err := auth.NewAuthenticator(auth.Config{Keys: keys})
+	require.NoError(t, err)
+
+	productService := service.NewProductService(productRepoMock, service.WithPolicy(accessPolicy))
+	graphqlHandler, err := graphql.NewHandler(productService)
+	require.NoError(t, err)
+
+	app := fiber.New()
+	apiVersions := routes.NewAPIVersions(app, "v1", routes.APIVersion{Name: "v1"})
+	app.Use(authenticator.Middleware())
+	app.Use(auth.Authorize(accessPolicy))
+	apiVersions.Handle("v1", func(api fiber.Router) {
+		routes.ProductRoutesMySQL(api, rest.NewProductHandlerMySQL(productService))
+		api.Get("/webhooks", func(c *fiber.Ctx) error { return c.JSON([]string{}) })
+	})
+	routes.GraphQLRoutes(app, graphqlHandler)
+	return app
+}
+
+func tokenWithRoles(t *testing.T, roles ...string) map[string]string {
+	token := signHS256(t, jwt.MapClaims{"sub": "alice", "roles": roles, "exp": time.Now().Add(time.Hour).Unix()})
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+func TestPolicy_RolesPerActionOverREST(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("List").Return([]entity.Product{{ID: "1", Name: "Product A", Stock: 100}}, nil)
+	productRepoMock.On("Delete", "1").Return(nil)
+	app := newPolicyApp(t, productRepoMock, policy.Default())
+
+	viewer := tokenWithRoles(t, policy.RoleViewer)
+	resp, _ := send(t, app, http.MethodGet, "/products", viewer)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Viewer tidak boleh menghapus, dan repository tidak tersentuh
+	resp, body := send(t, app, http.MethodDelete, "/products/1", viewer)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.JSONEq(t, `{"error":"product:delete requires one of the roles admin"}`, body)
+	productRepoMock.AssertNotCalled(t, "Delete", "1")
+
+	// Token tanpa role sama sekali
+	resp, _ = send(t, app, http.MethodGet, "/products", tokenWithRoles(t))
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, _ = send(t, app, http.MethodDelete, "/products/1", tokenWithRoles(t, policy.RoleAdmin))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	productRepoMock.AssertCalled(t, "Delete", "1")
+}
+
+func TestPolicy_RouteRules(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("List").Return([]entity.Product{}, nil)
+
+	// Rule dari file: /products hanya untuk role auditor, di luar rule default
+	path := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"actions": {"product:list": ["viewer", "auditor"]},
+		"routes": [{"method": "GET", "path": "/api/*/products", "roles": ["auditor"]}]
+	}`), 0o600))
+	accessPolicy, err := policy.Load(path)
+	require.NoError(t, err)
+	app := newPolicyApp(t, productRepoMock, accessPolicy)
+
+	resp, body := send(t, app, http.MethodGet, "/products", tokenWithRoles(t, policy.RoleViewer))
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Contains(t, body, "GET /api/*/products requires one of the roles auditor")
+
+	resp, _ = send(t, app, http.MethodGet, "/products", tokenWithRoles(t, "auditor"))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Rule default: webhook hanya untuk admin
+	resp, _ = send(t, app, http.MethodGet, "/webhooks", tokenWithRoles(t, policy.RoleEditor))
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp, _ = send(t, app, http.MethodGet, "/api/v1/webhooks", tokenWithRoles(t, policy.RoleAdmin))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Fiber tidak membedakan huruf besar, jadi rule juga tidak boleh
+	resp, _ = send(t, app, http.MethodGet, "/API/V1/Webhooks", tokenWithRoles(t, policy.RoleEditor))
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp, _ = send(t, app, http.MethodGet, "/api/v1/PRODUCTS", tokenWithRoles(t, policy.RoleViewer))
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestPolicy_StreamChecksActionList(t *testing.T) {
+	// Tanpa middleware Authorize, handler stream tetap memeriksa product:list
+	keys := auth.NewKeySet()
+	keys.AddHMAC("", testJWTSecret)
+	authenticator, err := auth.NewAuthenticator(auth.Config{Keys: keys})
+	require.NoError(t, err)
+
+	productService := service.NewProductService(new(ProductRepositoryMock), service.WithPolicy(policy.Default()))
+	app := fiber.New()
+	app.Use(authenticator.Middleware())
+	routes.ProductStreamRoutes(app, rest.NewProductStreamHandler(productService, eventstream.NewBroker(10)))
+
+	resp, body := send(t, app, http.MethodGet, "/products/stream", tokenWithRoles(t, "guest"))
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.JSONEq(t, `{"error":"product:list requires one of the roles viewer, editor, admin"}`, body)
+}
+
+func TestPolicy_GraphQLAndGRPC(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	app := newPolicyApp(t, productRepoMock, policy.Default())
+
+	// GraphQL melaporkan penolakan sebagai error dengan kode FORBIDDEN
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"mutation { deleteProduct(id: \"1\") }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", tokenWithRoles(t, policy.RoleEditor)["Authorization"])
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	body := getResponseBody(t, resp)
+	assert.Contains(t, body, `"code":"FORBIDDEN"`)
+	assert.Contains(t, body, "product:delete requires one of the roles admin")
+
+	// gRPC memetakan penolakan ke PermissionDenied
+	productService := service.NewProductService(productRepoMock, service.WithPolicy(policy.Default()))
+	server := grpcadapter.NewProductServer(productService, eventstream.NewBroker(10))
+	ctx := entity.WithPrincipal(context.Background(), entity.Principal{Subject: "alice", Roles: []string{policy.RoleViewer}})
+	_, err = server.CreateProduct(ctx, &productpb.CreateProductRequest{Name: "Product A", Stock: 1})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = server.CreateProduct(context.Background(), &productpb.CreateProductRequest{Name: "Product A", Stock: 1})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	productRepoMock.AssertNotCalled(t, "Create")
+	productRepoMock.AssertNotCalled(t, "Delete")
+}
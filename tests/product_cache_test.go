@@ -0,0 +1,153 @@
+package handler_test
+
+import (
+	"context"
+	"go-hexagon/internal/adapter/cache"
+	"go-hexagon/internal/adapter/repository"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/port"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductCache_LookupsAndInvalidation(t *testing.T) {
+	ctx := context.Background()
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("GetByID", "1").Return(&entity.Product{ID: "1", Name: "Product A", Stock: 10}, nil)
+	productRepoMock.On("ListPage", mock.Anything).Return(&entity.ProductPage{
+		Products: []entity.Product{{ID: "1", Name: "Product A", Stock: 10}}, Total: 1, Limit: 20,
+	}, nil)
+	productRepoMock.On("Update", mock.Anything).Return(nil)
+	productRepoMock.On("Create", mock.Anything).Return(nil)
+	repo := repository.NewCachedProductRepository(productRepoMock, cache.NewLRU(100), repository.DefaultCacheConfig())
+
+	product, err := repo.GetByID(ctx, "1")
+	require.NoError(t, err)
+	// Mengubah hasil lookup tidak mengubah isi cache
+	product.Stock = 99
+	product, err = repo.GetByID(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, 10, product.Stock)
+	productRepoMock.AssertNumberOfCalls(t, "GetByID", 1)
+
+	// Halaman dengan query berbeda disimpan terpisah
+	minStock := 5
+	for i := 0; i < 2; i++ {
+		_, err = repo.ListPage(ctx, entity.ProductQuery{Limit: 20})
+		require.NoError(t, err)
+		_, err = repo.ListPage(ctx, entity.ProductQuery{Limit: 20, MinStock: &minStock})
+		require.NoError(t, err)
+	}
+	productRepoMock.AssertNumberOfCalls(t, "ListPage", 2)
+	assert.Equal(t, port.CacheStats{Hits: 3, Misses: 3}, repo.Stats())
+
+	// Update menghapus produk tersebut dan semua halaman dari cache
+	require.NoError(t, repo.Update(ctx, &entity.Product{ID: "1", Name: "Product A", Stock: 7}))
+	_, err = repo.GetByID(ctx, "1")
+	require.NoError(t, err)
+	_, err = repo.ListPage(ctx, entity.ProductQuery{Limit: 20})
+	require.NoError(t, err)
+	productRepoMock.AssertNumberOfCalls(t, "GetByID", 2)
+	productRepoMock.AssertNumberOfCalls(t, "ListPage", 3)
+
+	// Create hanya menghapus halaman
+	require.NoError(t, repo.Create(ctx, &entity.Product{Name: "Product B", Stock: 1}))
+	_, err = repo.GetByID(ctx, "1")
+	require.NoError(t, err)
+	_, err = repo.ListPage(ctx, entity.ProductQuery{Limit: 20})
+	require.NoError(t, err)
+	productRepoMock.AssertNumberOfCalls(t, "GetByID", 2)
+	productRepoMock.AssertNumberOfCalls(t, "ListPage", 4)
+}
+
+func TestProductCache_ErrorsAreNotCached(t *testing.T) {
+	ctx := context.Background()
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("GetByID", "9").Return(nil, entity.ErrProductNotFound)
+	productRepoMock.On("GetByIDs", []string{"2"}).Return([]entity.Product{{ID: "2", Name: "Product B", Stock: 5}}, nil)
+	productRepoMock.On("GetByID", "2").Return(&entity.Product{ID: "2", Name: "Product B", Stock: 5}, nil)
+	repo := repository.NewCachedProductRepository(productRepoMock, cache.NewLRU(100), repository.DefaultCacheConfig())
+
+	for i := 0; i < 2; i++ {
+		_, err := repo.GetByID(ctx, "9")
+		assert.ErrorIs(t, err, entity.ErrProductNotFound)
+	}
+	productRepoMock.AssertNumberOfCalls(t, "GetByID", 2)
+
+	// Produk dari GetByIDs ikut disimpan untuk lookup per ID
+	products, err := repo.GetByIDs(ctx, []string{"2"})
+	require.NoError(t, err)
+	assert.Len(t, products, 1)
+	product, err := repo.GetByID(ctx, "2")
+	require.NoError(t, err)
+	assert.Equal(t, "Product B", product.Name)
+	productRepoMock.AssertNotCalled(t, "GetByID", "2")
+}
+
+func TestProductCache_PrimaryReadsAndFillDelay(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("GetByID", "1").Return(&entity.Product{ID: "1", Name: "Product A", Stock: 10}, nil)
+	productRepoMock.On("Update", mock.Anything).Return(nil)
+	config := repository.DefaultCacheConfig()
+	config.FillDelay = 50 * time.Millisecond
+	repo := repository.NewCachedProductRepository(productRepoMock, cache.NewLRU(100), config)
+
+	// Read yang dipin ke primary tidak membaca maupun mengisi cache
+	primary := entity.WithReadYourWrites(context.Background(), true)
+	for i := 0; i < 2; i++ {
+		_, err := repo.GetByID(primary, "1")
+		require.NoError(t, err)
+	}
+	productRepoMock.AssertNumberOfCalls(t, "GetByID", 2)
+	assert.Equal(t, port.CacheStats{}, repo.Stats())
+
+	_, err := repo.GetByID(context.Background(), "1")
+	require.NoError(t, err)
+	_, err = repo.GetByID(primary, "1")
+	require.NoError(t, err)
+	productRepoMock.AssertNumberOfCalls(t, "GetByID", 4)
+
+	// Tepat setelah write, read dari replica tidak disimpan ke cache
+	require.NoError(t, repo.Update(context.Background(), &entity.Product{ID: "1", Name: "Product A", Stock: 7}))
+	for i := 0; i < 2; i++ {
+		_, err = repo.GetByID(context.Background(), "1")
+		require.NoError(t, err)
+	}
+	productRepoMock.AssertNumberOfCalls(t, "GetByID", 6)
+
+	// Setelah FillDelay cache diisi lagi
+	time.Sleep(config.FillDelay)
+	for i := 0; i < 2; i++ {
+		_, err = repo.GetByID(context.Background(), "1")
+		require.NoError(t, err)
+	}
+	productRepoMock.AssertNumberOfCalls(t, "GetByID", 7)
+}
+
+func TestLRU_EvictionAndExpiry(t *testing.T) {
+	lru := cache.NewLRU(2)
+	lru.Set("a", []byte("1"), 0)
+	lru.Set("b", []byte("2"), 0)
+	_, _ = lru.Get("a")
+	lru.Set("c", []byte("3"), 0)
+
+	// "b" paling lama tidak dipakai sehingga dikeluarkan
+	_, ok := lru.Get("b")
+	assert.False(t, ok)
+	value, ok := lru.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "1", string(value))
+
+	lru.Set("d", []byte("4"), 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	_, ok = lru.Get("d")
+	assert.False(t, ok)
+
+	lru.Set("products:all", []byte("[]"), 0)
+	lru.DeletePrefix("products:")
+	assert.Equal(t, 1, lru.Len())
+}
@@ -0,0 +1,169 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"go-hexagon/internal/adapter/handler/graphql"
+	"go-hexagon/internal/adapter/routes"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newGraphQLApp(t *testing.T, productRepoMock *ProductRepositoryMock) *fiber.App {
+	graphqlHandler, err := graphql.NewHandler(service.NewProductService(productRepoMock))
+	require.NoError(t, err)
+
+	app := fiber.New()
+	routes.GraphQLRoutes(app, graphqlHandler)
+	return app
+}
+
+func doGraphQL(t *testing.T, app *fiber.App, query string, variables map[string]interface{}) map[string]interface{} {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(getResponseBody(t, resp)), &result))
+	return result
+}
+
+func TestGraphQL_BatchedProductLookups(t *testing.T) {
+	// Dua field product(id:) harus digabung menjadi satu panggilan GetByIDs
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("GetByIDs", []string{"1", "2"}).Return([]entity.Product{
+		{ID: "1", Name: "Product A", Stock: 100},
+		{ID: "2", Name: "Product B", Stock: 50},
+	}, nil).Once()
+	app := newGraphQLApp(t, productRepoMock)
+
+	result := doGraphQL(t, app, `{ a: product(id: "1") { id name } b: product(id: "2") { stock } }`, nil)
+
+	assert.Nil(t, result["errors"])
+	assert.Equal(t, map[string]interface{}{
+		"a": map[string]interface{}{"id": "1", "name": "Product A"},
+		"b": map[string]interface{}{"stock": float64(50)},
+	}, result["data"])
+	productRepoMock.AssertExpectations(t)
+}
+
+func TestGraphQL_DuplicateProductLookups(t *testing.T) {
+	// ID yang sama hanya diminta sekali
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("GetByIDs", []string{"1"}).Return([]entity.Product{
+		{ID: "1", Name: "Product A", Stock: 100},
+	}, nil).Once()
+	app := newGraphQLApp(t, productRepoMock)
+
+	result := doGraphQL(t, app, `{ a: product(id: "1") { id } b: product(id: "1") { name } }`, nil)
+
+	assert.Nil(t, result["errors"])
+	assert.Equal(t, map[string]interface{}{
+		"a": map[string]interface{}{"id": "1"},
+		"b": map[string]interface{}{"name": "Product A"},
+	}, result["data"])
+	productRepoMock.AssertExpectations(t)
+}
+
+func TestGraphQL_ProductListWithFilter(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	minStock := 10
+	productRepoMock.On("ListPage", entity.ProductQuery{Offset: 0, Limit: 1, NameContains: "Prod", MinStock: &minStock}).Return(&entity.ProductPage{
+		Products: []entity.Product{{ID: "1", Name: "Product A", Stock: 100}},
+		Total:    2, Offset: 0, Limit: 1,
+	}, nil)
+	app := newGraphQLApp(t, productRepoMock)
+
+	result := doGraphQL(t, app, `query ($min: Int) {
+		productList(filter: {nameContains: "Prod", minStock: $min}, limit: 1) { total hasMore items { id name } }
+	}`, map[string]interface{}{"min": 10})
+
+	assert.Nil(t, result["errors"])
+	assert.Equal(t, map[string]interface{}{
+		"productList": map[string]interface{}{
+			"total":   float64(2),
+			"hasMore": true,
+			"items":   []interface{}{map[string]interface{}{"id": "1", "name": "Product A"}},
+		},
+	}, result["data"])
+}
+
+func TestGraphQL_AdjustStock(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("GetByID", "1").Return(&entity.Product{ID: "1", Name: "Product A", Stock: 5}, nil)
+	productRepoMock.On("Update", mock.AnythingOfType("*entity.Product")).Return(nil)
+	app := newGraphQLApp(t, productRepoMock)
+
+	result := doGraphQL(t, app, `mutation { adjustStock(id: "1", delta: -3) { id stock } }`, nil)
+	assert.Nil(t, result["errors"])
+	assert.Equal(t, map[string]interface{}{"adjustStock": map[string]interface{}{"id": "1", "stock": float64(2)}}, result["data"])
+
+	// Stok tidak boleh negatif
+	result = doGraphQL(t, app, `mutation { adjustStock(id: "1", delta: -10) { stock } }`, nil)
+	require.NotNil(t, result["errors"])
+	gqlErr := result["errors"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "Stock cannot go below zero", gqlErr["message"])
+	assert.Equal(t, map[string]interface{}{"code": "BAD_USER_INPUT"}, gqlErr["extensions"])
+}
+
+func TestGraphQL_GetOnlyRunsQueries(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("GetByIDs", []string{"1"}).Return([]entity.Product{{ID: "1", Name: "Product A", Stock: 5}}, nil)
+	app := newGraphQLApp(t, productRepoMock)
+
+	get := func(params url.Values) *http.Response {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/graphql?"+params.Encode(), nil), -1)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Query melalui GET tetap dijalankan
+	resp := get(url.Values{"query": {`{ product(id: "1") { name } }`}})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Mutation melalui GET ditolak tanpa menyentuh repository
+	resp = get(url.Values{"query": {`mutation { adjustStock(id: "1", delta: -3) { stock } }`}})
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, http.MethodPost, resp.Header.Get("Allow"))
+
+	// Termasuk jika mutation dipilih lewat operationName
+	resp = get(url.Values{
+		"query":         {`query Read { product(id: "1") { name } } mutation Write { adjustStock(id: "1", delta: -3) { stock } }`},
+		"operationName": {"Write"},
+	})
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp = get(url.Values{
+		"query":         {`query Read { product(id: "1") { name } } mutation Write { adjustStock(id: "1", delta: -3) { stock } }`},
+		"operationName": {"Read"},
+	})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	productRepoMock.AssertNotCalled(t, "GetByID", "1")
+	productRepoMock.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestGraphQL_CreateProductValidation(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	app := newGraphQLApp(t, productRepoMock)
+
+	result := doGraphQL(t, app, `mutation { createProduct(name: "", stock: 1) { id } }`, nil)
+
+	require.NotNil(t, result["errors"])
+	gqlErr := result["errors"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "Name and Stock fields are required", gqlErr["message"])
+	productRepoMock.AssertNotCalled(t, "Create")
+}
@@ -0,0 +1,121 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"go-hexagon/internal/adapter/eventstream"
+	grpcadapter "go-hexagon/internal/adapter/handler/grpc"
+	"go-hexagon/internal/adapter/handler/grpc/productpb"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newGRPCClient(t *testing.T, productService *service.ProductService, broker *eventstream.Broker) productpb.ProductServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpcadapter.NewServer(grpcadapter.NewProductServer(productService, broker))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return productpb.NewProductServiceClient(conn)
+}
+
+func TestGRPC_GetProduct(t *testing.T) {
+	// Inisialisasi mock repository dan service
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("GetByID", "1").Return(&entity.Product{ID: "1", Name: "Product A", Stock: 100}, nil)
+	productRepoMock.On("GetByID", "2").Return(nil, entity.ErrProductNotFound)
+	productRepoMock.On("GetByID", "abc").Return(nil, entity.ErrInvalidID)
+	client := newGRPCClient(t, service.NewProductService(productRepoMock), eventstream.NewBroker(10))
+
+	product, err := client.GetProduct(context.Background(), &productpb.GetProductRequest{Id: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "1", product.GetId())
+	assert.Equal(t, "Product A", product.GetName())
+	assert.Equal(t, int32(100), product.GetStock())
+
+	// Assert pemetaan error ke status code gRPC
+	_, err = client.GetProduct(context.Background(), &productpb.GetProductRequest{Id: "2"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.GetProduct(context.Background(), &productpb.GetProductRequest{Id: "abc"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	productRepoMock.On("Update", mock.Anything).Return(fmt.Errorf("connection refused")).Once()
+	stock := int32(5)
+	_, err = client.UpdateProduct(context.Background(), &productpb.UpdateProductRequest{Id: "1", Stock: &stock})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestGRPC_CreateProductValidation(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	client := newGRPCClient(t, service.NewProductService(productRepoMock), eventstream.NewBroker(10))
+
+	_, err := client.CreateProduct(context.Background(), &productpb.CreateProductRequest{Name: "Product A"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	productRepoMock.AssertNotCalled(t, "Create")
+}
+
+func TestGRPC_ListProductsPagination(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("ListPage", entity.ProductQuery{Offset: 0, Limit: 2}).Return(&entity.ProductPage{
+		Products: []entity.Product{{ID: "1", Name: "A", Stock: 1}, {ID: "2", Name: "B", Stock: 2}},
+		Total:    3, Offset: 0, Limit: 2,
+	}, nil)
+	productRepoMock.On("ListPage", entity.ProductQuery{Offset: 2, Limit: 2}).Return(&entity.ProductPage{
+		Products: []entity.Product{{ID: "3", Name: "C", Stock: 3}},
+		Total:    3, Offset: 2, Limit: 2,
+	}, nil)
+	client := newGRPCClient(t, service.NewProductService(productRepoMock), eventstream.NewBroker(10))
+
+	first, err := client.ListProducts(context.Background(), &productpb.ListProductsRequest{PageSize: 2})
+	require.NoError(t, err)
+	assert.Len(t, first.GetProducts(), 2)
+	assert.Equal(t, int64(3), first.GetTotalSize())
+	require.NotEmpty(t, first.GetNextPageToken())
+
+	second, err := client.ListProducts(context.Background(), &productpb.ListProductsRequest{PageSize: 2, PageToken: first.GetNextPageToken()})
+	require.NoError(t, err)
+	require.Len(t, second.GetProducts(), 1)
+	assert.Equal(t, "3", second.GetProducts()[0].GetId())
+	assert.Empty(t, second.GetNextPageToken())
+}
+
+func TestGRPC_WatchProducts(t *testing.T) {
+	// Event sebelumnya ada di buffer dan dikirim ulang saat resume
+	broker := eventstream.NewBroker(10)
+	broker.Publish(entity.ProductEvent{Type: entity.ProductCreated, Product: entity.Product{ID: "1", Name: "A", Stock: 1}})
+	broker.Publish(entity.ProductEvent{Type: entity.ProductCreated, Product: entity.Product{ID: "2", Name: "B", Stock: 2}})
+	client := newGRPCClient(t, service.NewProductService(new(ProductRepositoryMock)), broker)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	stream, err := client.WatchProducts(ctx, &productpb.WatchProductsRequest{ProductIds: []string{"2"}, LastEventId: 0})
+	require.NoError(t, err)
+
+	time.Sleep(50 * time.Millisecond)
+	broker.Publish(entity.ProductEvent{Type: entity.ProductDeleted, Product: entity.Product{ID: "1"}})
+	broker.Publish(entity.ProductEvent{Type: entity.ProductStockChanged, Product: entity.Product{ID: "2", Name: "B", Stock: 3}})
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), event.GetId())
+	assert.Equal(t, entity.ProductStockChanged, event.GetType())
+	assert.Equal(t, int32(3), event.GetProduct().GetStock())
+}
@@ -0,0 +1,159 @@
+package handler_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"go-hexagon/internal/adapter/repository"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	alterAddColumn = regexp.MustCompile("^ALTER TABLE `(\\w+)` ADD `(\\w+)`")
+	createTable    = regexp.MustCompile("^CREATE TABLE `(\\w+)` \\((.*)\\)$")
+	columnName     = regexp.MustCompile("(?:^|,)`(\\w+)` ")
+)
+
+// Database palsu yang hanya mengenal information_schema, cukup untuk
+// migrator GORM MySQL. Tabel dibuat oleh CREATE TABLE dan kolom ditambahkan
+// oleh ALTER TABLE ... ADD.
+type schemaDatabase struct {
+	mu         sync.Mutex
+	tables     map[string][]string
+	statements []string
+}
+
+func (d *schemaDatabase) Connect(context.Context) (driver.Conn, error) { return schemaConn{d}, nil }
+func (d *schemaDatabase) Driver() driver.Driver                        { return nil }
+
+type schemaConn struct{ db *schemaDatabase }
+
+func (c schemaConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("unexpected prepare: %s", query)
+}
+func (c schemaConn) Close() error              { return nil }
+func (c schemaConn) Begin() (driver.Tx, error) { return nil, fmt.Errorf("unexpected transaction") }
+
+func (c schemaConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	switch {
+	case strings.Contains(query, "DATABASE()"), strings.Contains(query, "SCHEMATA"):
+		return &schemaRows{values: []driver.Value{"shop"}}, nil
+	case strings.Contains(query, "information_schema.tables"):
+		_, ok := c.db.tables[args[1].Value.(string)]
+		return &schemaRows{values: []driver.Value{count(ok)}}, nil
+	case strings.Contains(query, "INFORMATION_SCHEMA.columns"):
+		columns := c.db.tables[args[1].Value.(string)]
+		return &schemaRows{values: []driver.Value{count(slices.Contains(columns, args[2].Value.(string)))}}, nil
+	}
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+func (c schemaConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	if match := createTable.FindStringSubmatch(query); match != nil {
+		c.db.statements = append(c.db.statements, query)
+		columns := []string{}
+		for _, column := range columnName.FindAllStringSubmatch(match[2], -1) {
+			columns = append(columns, column[1])
+		}
+		c.db.tables[match[1]] = columns
+		return driver.RowsAffected(0), nil
+	}
+	match := alterAddColumn.FindStringSubmatch(query)
+	if match == nil {
+		return nil, fmt.Errorf("unexpected statement: %s", query)
+	}
+	c.db.statements = append(c.db.statements, query)
+	c.db.tables[match[1]] = append(c.db.tables[match[1]], match[2])
+	return driver.RowsAffected(0), nil
+}
+
+func count(ok bool) int64 {
+	if ok {
+		return 1
+	}
+	return 0
+}
+
+// schemaRows mengembalikan satu baris dengan satu kolom
+type schemaRows struct {
+	values []driver.Value
+	done   bool
+}
+
+func (r *schemaRows) Columns() []string { return []string{"value"} }
+func (r *schemaRows) Close() error      { return nil }
+func (r *schemaRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	copy(dest, r.values)
+	return nil
+}
+
+func openSchemaDatabase(t *testing.T, tables map[string][]string) (*gorm.DB, *schemaDatabase) {
+	schema := &schemaDatabase{tables: tables}
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sql.OpenDB(schema), SkipInitializeWithVersion: true}), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	return db, schema
+}
+
+func TestMigrateProducts_AddsUpdatedAtToOldTable(t *testing.T) {
+	// Tabel products dibuat sebelum kolom updated_at ada
+	db, schema := openSchemaDatabase(t, map[string][]string{"products": {"id", "name", "stock"}})
+
+	require.NoError(t, repository.MigrateProducts(db))
+	assert.Equal(t, []string{"ALTER TABLE `products` ADD `updated_at` datetime(3) NULL"}, schema.statements)
+	assert.Equal(t, []string{"id", "name", "stock", "updated_at"}, schema.tables["products"])
+
+	// Migrasi kedua tidak mengubah apa pun
+	require.NoError(t, repository.MigrateProducts(db))
+	assert.Len(t, schema.statements, 1)
+}
+
+func TestMigrateProducts_LeavesMissingTableAlone(t *testing.T) {
+	// Tabel products dikelola di luar aplikasi, jadi tidak dibuat di sini
+	db, schema := openSchemaDatabase(t, map[string][]string{})
+
+	require.NoError(t, repository.MigrateProducts(db))
+	assert.Empty(t, schema.statements)
+	assert.NotContains(t, schema.tables, "products")
+}
+
+func TestMigrateSQL_CreatesFeatureTables(t *testing.T) {
+	db, schema := openSchemaDatabase(t, map[string][]string{})
+
+	require.NoError(t, repository.MigrateSQL(db))
+	for _, table := range []string{"webhooks", "webhook_deliveries", "audit_records", "idempotency_keys", "product_id_map"} {
+		assert.Contains(t, schema.tables, table)
+	}
+	assert.Contains(t, schema.tables["webhook_deliveries"], "next_attempt_at")
+	// Tabel products tetap dikelola di luar aplikasi
+	assert.NotContains(t, schema.tables, "products")
+}
+
+func TestCreateProducts_EmptySecondary(t *testing.T) {
+	// Database --dual-write yang masih kosong
+	db, schema := openSchemaDatabase(t, map[string][]string{})
+
+	require.NoError(t, repository.CreateProducts(db))
+	require.Len(t, schema.statements, 1)
+	assert.Regexp(t, "^CREATE TABLE `products`", schema.statements[0])
+	assert.Equal(t, []string{"id", "name", "stock", "updated_at"}, schema.tables["products"])
+}
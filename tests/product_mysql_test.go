@@ -1,7 +1,7 @@
 package handler_test
 
 import (
-	"fmt"
+	"context"
 	"go-hexagon/internal/adapter/handler/rest"
 	"go-hexagon/internal/core/domain/entity"
 	"go-hexagon/internal/core/service"
@@ -18,7 +18,8 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-// Inisialisasi mock
+// Inisialisasi mock. Context tidak dicatat, sehingga ekspektasi cukup
+// menyebut argumen lainnya.
 type ProductRepositoryMock struct {
 	mock.Mock
 }
@@ -29,22 +30,35 @@ func getResponseBody(t *testing.T, resp *http.Response) string {
 	return string(bodyBytes)
 }
 
-func (m *ProductRepositoryMock) List() ([]entity.Product, error) {
+func (m *ProductRepositoryMock) List(ctx context.Context) ([]entity.Product, error) {
 	args := m.Called()
 	return args.Get(0).([]entity.Product), args.Error(1)
 }
 
-func (m *ProductRepositoryMock) Create(product *entity.Product) error {
+func (m *ProductRepositoryMock) GetByIDs(ctx context.Context, ids []string) ([]entity.Product, error) {
+	args := m.Called(ids)
+	return args.Get(0).([]entity.Product), args.Error(1)
+}
+
+func (m *ProductRepositoryMock) ListPage(ctx context.Context, query entity.ProductQuery) (*entity.ProductPage, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ProductPage), args.Error(1)
+}
+
+func (m *ProductRepositoryMock) Create(ctx context.Context, product *entity.Product) error {
 	args := m.Called(product)
 	return args.Error(0)
 }
 
-func (m *ProductRepositoryMock) Update(product *entity.Product) error {
+func (m *ProductRepositoryMock) Update(ctx context.Context, product *entity.Product) error {
 	args := m.Called(product)
 	return args.Error(0)
 }
 
-func (m *ProductRepositoryMock) GetByID(id interface{}) (*entity.Product, error) {
+func (m *ProductRepositoryMock) GetByID(ctx context.Context, id string) (*entity.Product, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -52,7 +66,7 @@ func (m *ProductRepositoryMock) GetByID(id interface{}) (*entity.Product, error)
 	return args.Get(0).(*entity.Product), args.Error(1)
 }
 
-func (m *ProductRepositoryMock) Delete(id interface{}) error {
+func (m *ProductRepositoryMock) Delete(ctx context.Context, id string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
@@ -66,8 +80,8 @@ func TestListProducts_Success(t *testing.T) {
 
 	// Data produk palsu
 	mockProducts := []entity.Product{
-		{MySQLID: 1, Name: "Product A", Stock: 100},
-		{MySQLID: 2, Name: "Product B", Stock: 50},
+		{ID: "1", Name: "Product A", Stock: 100},
+		{ID: "2", Name: "Product B", Stock: 50},
 	}
 
 	// Atur mock untuk mengembalikan daftar produk
@@ -173,7 +187,7 @@ func TestCreateProduct_InvalidInput(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
 	// Assert pesan error dalam respons
-	expectedBody := `{"error":"json: cannot unmarshal string into Go struct field Product.stock of type int"}`
+	expectedBody := `{"error":"json: cannot unmarshal string into Go struct field ProductRequest.stock of type int"}`
 	assert.JSONEq(t, expectedBody, getResponseBody(t, resp))
 
 	// Assert bahwa metode Create tidak dipanggil (karena input tidak valid)
@@ -188,10 +202,10 @@ func TestUpdateProduct_Success(t *testing.T) {
 	productHandler := rest.NewProductHandlerMySQL(productService)
 
 	// Produk yang ada di database
-	existingProduct := &entity.Product{MySQLID: 1, Name: "Old Product", Stock: 50}
+	existingProduct := &entity.Product{ID: "1", Name: "Old Product", Stock: 50}
 
 	// Setup mock untuk GetByID dan Update
-	productRepoMock.On("GetByID", uint(1)).Return(existingProduct, nil)
+	productRepoMock.On("GetByID", "1").Return(existingProduct, nil)
 	productRepoMock.On("Update", mock.AnythingOfType("*entity.Product")).Return(nil)
 
 	// Membuat request untuk update produk
@@ -223,7 +237,7 @@ func TestUpdateProduct_NotFound(t *testing.T) {
 	productHandler := rest.NewProductHandlerMySQL(productService)
 
 	// Setup mock untuk GetByID (produk tidak ditemukan)
-	productRepoMock.On("GetByID", uint(1)).Return(nil, fmt.Errorf("ID Not Found"))
+	productRepoMock.On("GetByID", "1").Return(nil, entity.ErrProductNotFound)
 
 	// Membuat request untuk update produk yang tidak ada
 	app := fiber.New()
@@ -244,7 +258,7 @@ func TestUpdateProduct_NotFound(t *testing.T) {
 	assert.JSONEq(t, expectedBody, getResponseBody(t, resp))
 
 	// Assert bahwa metode GetByID dipanggil tapi Update tidak
-	productRepoMock.AssertCalled(t, "GetByID", uint(1))
+	productRepoMock.AssertCalled(t, "GetByID", "1")
 	productRepoMock.AssertNotCalled(t, "Update")
 }
 
@@ -256,10 +270,10 @@ func TestGetProductByID_Success(t *testing.T) {
 	productHandler := rest.NewProductHandlerMySQL(productService)
 
 	// Produk yang ada di database
-	existingProduct := &entity.Product{MySQLID: 1, Name: "Product A", Stock: 100}
+	existingProduct := &entity.Product{ID: "1", Name: "Product A", Stock: 100}
 
 	// Setup mock untuk GetByID
-	productRepoMock.On("GetByID", uint(1)).Return(existingProduct, nil)
+	productRepoMock.On("GetByID", "1").Return(existingProduct, nil)
 
 	// Membuat request untuk mengambil produk berdasarkan ID
 	app := fiber.New()
@@ -287,7 +301,7 @@ func TestGetProductByID_NotFound(t *testing.T) {
 	productHandler := rest.NewProductHandlerMySQL(productService)
 
 	// Setup mock untuk GetByID (produk tidak ditemukan)
-	productRepoMock.On("GetByID", uint(1)).Return(nil, fmt.Errorf("ID not found"))
+	productRepoMock.On("GetByID", "1").Return(nil, entity.ErrProductNotFound)
 
 	// Membuat request untuk mengambil produk yang tidak ada
 	app := fiber.New()
@@ -316,7 +330,7 @@ func TestDeleteProductByID_Success(t *testing.T) {
 	productHandler := rest.NewProductHandlerMySQL(productService)
 
 	// Setup mock untuk Delete
-	productRepoMock.On("Delete", uint(1)).Return(nil)
+	productRepoMock.On("Delete", "1").Return(nil)
 
 	// Membuat request untuk menghapus produk berdasarkan ID
 	app := fiber.New()
@@ -344,7 +358,7 @@ func TestDeleteProductByID_NotFound(t *testing.T) {
 	productHandler := rest.NewProductHandlerMySQL(productService)
 
 	// Setup mock untuk Delete (produk tidak ditemukan)
-	productRepoMock.On("Delete", uint(1)).Return(fmt.Errorf("ID not found"))
+	productRepoMock.On("Delete", "1").Return(entity.ErrProductNotFound)
 
 	// Membuat request untuk menghapus produk yang tidak ada
 	app := fiber.New()
@@ -0,0 +1,161 @@
+package handler_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"go-hexagon/internal/adapter/database"
+	"go-hexagon/internal/adapter/repository"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var updateColumn = regexp.MustCompile("`(\\w+)`=\\?")
+
+// Database palsu berisi tabel products, cukup untuk GetByID, Update dan
+// Search repository MySQL. Setiap statement yang mengubah data atau index
+// dicatat, begitu pula query pencarian beserta argumennya.
+type productDatabase struct {
+	mu         sync.Mutex
+	rows       map[int64]map[string]driver.Value
+	statements []string
+	searches   []string
+	searchArgs []driver.Value
+}
+
+func (d *productDatabase) Connect(context.Context) (driver.Conn, error) { return productConn{d}, nil }
+func (d *productDatabase) Driver() driver.Driver                        { return nil }
+
+type productConn struct{ db *productDatabase }
+
+func (c productConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("unexpected prepare: %s", query)
+}
+func (c productConn) Close() error              { return nil }
+func (c productConn) Begin() (driver.Tx, error) { return productTx{}, nil }
+
+type productTx struct{}
+
+func (productTx) Commit() error   { return nil }
+func (productTx) Rollback() error { return nil }
+
+func (c productConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	switch {
+	case strings.Contains(query, "DATABASE()"):
+		return &schemaRows{values: []driver.Value{"shop"}}, nil
+	case strings.Contains(query, "information_schema.statistics"):
+		return &schemaRows{values: []driver.Value{count(false)}}, nil
+	case strings.Contains(query, "MATCH("):
+		c.db.searches = append(c.db.searches, query)
+		c.db.searchArgs = append(c.db.searchArgs, args[0].Value)
+		if strings.HasPrefix(query, "SELECT count(*)") {
+			return &schemaRows{values: []driver.Value{int64(0)}}, nil
+		}
+		return &productRows{}, nil
+	case !strings.HasPrefix(query, "SELECT * FROM `products` WHERE `products`.`id` = ?"):
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	}
+	rows := &productRows{}
+	if row, ok := c.db.rows[args[0].Value.(int64)]; ok {
+		rows.values = [][]driver.Value{{row["id"], row["name"], row["stock"], row["updated_at"]}}
+	}
+	return rows, nil
+}
+
+func (c productConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	if strings.HasPrefix(query, "SET SESSION ") || strings.HasPrefix(query, "CREATE FULLTEXT INDEX ") {
+		c.db.statements = append(c.db.statements, query)
+		return driver.RowsAffected(0), nil
+	}
+	set, where, ok := strings.Cut(strings.TrimPrefix(query, "UPDATE `products` SET "), " WHERE ")
+	if !ok || where != "`id` = ?" {
+		return nil, fmt.Errorf("unexpected statement: %s", query)
+	}
+	c.db.statements = append(c.db.statements, query)
+	row := c.db.rows[args[len(args)-1].Value.(int64)]
+	for i, match := range updateColumn.FindAllStringSubmatch(set, -1) {
+		row[match[1]] = args[i].Value
+	}
+	return driver.RowsAffected(1), nil
+}
+
+type productRows struct {
+	values [][]driver.Value
+}
+
+func (r *productRows) Columns() []string { return []string{"id", "name", "stock", "updated_at"} }
+func (r *productRows) Close() error      { return nil }
+func (r *productRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func openProductDatabase(t *testing.T, name string, stock int64) (*gorm.DB, *productDatabase) {
+	products := &productDatabase{rows: map[int64]map[string]driver.Value{
+		1: {"id": int64(1), "name": name, "stock": stock, "updated_at": nil},
+	}}
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sql.OpenDB(products), SkipInitializeWithVersion: true}), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	return db, products
+}
+
+type capturedEvents struct {
+	mu     sync.Mutex
+	events []entity.ProductEvent
+}
+
+func (c *capturedEvents) Publish(event entity.ProductEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, event)
+}
+
+func TestReplicas_PatchReadsFromPrimary(t *testing.T) {
+	// Replica tertinggal: stok di primary sudah berubah menjadi 10
+	primaryDB, primary := openProductDatabase(t, "Product A", 10)
+	replicaDB, replica := openProductDatabase(t, "Product A", 3)
+	replicas := database.NewReplicaSet(primaryDB, []*gorm.DB{replicaDB}, database.ReplicaConfig{HealthInterval: time.Hour, HealthTimeout: time.Second})
+
+	events := &capturedEvents{}
+	productService := service.NewProductService(repository.NewProductRepositoryReplicated(replicas), service.WithPublisher(events))
+
+	// Tanpa read-your-writes, seperti request gRPC atau GraphQL
+	name := "Product B"
+	product, err := productService.PatchProduct(context.Background(), "1", service.ProductChanges{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, 10, product.Stock)
+
+	// Hanya kolom yang berubah yang ditulis, dan tidak ada yang ditulis ke replica
+	require.Len(t, primary.statements, 1)
+	assert.Equal(t, "UPDATE `products` SET `name`=?,`updated_at`=? WHERE `id` = ?", primary.statements[0])
+	assert.Equal(t, int64(10), primary.rows[1]["stock"])
+	assert.Equal(t, "Product B", primary.rows[1]["name"])
+	assert.Empty(t, replica.statements)
+
+	// Event membawa keadaan sebelumnya dari primary
+	require.Len(t, events.events, 1)
+	assert.Equal(t, entity.ProductUpdated, events.events[0].Type)
+	assert.Equal(t, 10, events.events[0].Previous.Stock)
+	assert.Equal(t, "Product A", events.events[0].Previous.Name)
+}
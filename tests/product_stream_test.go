@@ -0,0 +1,148 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"go-hexagon/internal/adapter/eventstream"
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/adapter/routes"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroker_ResumeFromLastEventID(t *testing.T) {
+	broker := eventstream.NewBroker(2)
+	for i := 1; i <= 3; i++ {
+		broker.Publish(entity.ProductEvent{Type: entity.ProductCreated, Product: entity.Product{ID: strconv.Itoa(i)}})
+	}
+
+	// Event 1 sudah keluar dari buffer, jadi resume dari 1 tidak ada gap
+	sub := broker.Subscribe(1)
+	defer broker.Unsubscribe(sub)
+	assert.False(t, sub.Gap)
+	require.Len(t, sub.Backlog, 2)
+	assert.Equal(t, uint64(2), sub.Backlog[0].ID)
+	assert.Equal(t, "3", sub.Backlog[1].ProductID)
+
+	// Tanpa Last-Event-ID tidak ada backlog
+	fresh := broker.Subscribe(0)
+	defer broker.Unsubscribe(fresh)
+	assert.Empty(t, fresh.Backlog)
+
+	// Resume dari event yang sudah hilang menandakan gap
+	tooOld := eventstream.NewBroker(1)
+	tooOld.Publish(entity.ProductEvent{Type: entity.ProductCreated})
+	tooOld.Publish(entity.ProductEvent{Type: entity.ProductCreated})
+	tooOld.Publish(entity.ProductEvent{Type: entity.ProductCreated})
+	gap := tooOld.Subscribe(1)
+	defer tooOld.Unsubscribe(gap)
+	assert.True(t, gap.Gap)
+	assert.Equal(t, uint64(3), gap.LastID)
+}
+
+// newEventReader membaca satu event SSE (baris-baris hingga baris kosong)
+func newEventReader(t *testing.T, resp *http.Response) func() string {
+	reader := bufio.NewReader(resp.Body)
+	return func() string {
+		var lines []string
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimRight(line, "\n")
+			if line == "" {
+				if len(lines) > 0 {
+					return strings.Join(lines, "\n")
+				}
+				continue
+			}
+			lines = append(lines, line)
+		}
+	}
+}
+
+func startStreamApp(t *testing.T, broker *eventstream.Broker) (string, func()) {
+	app := fiber.New()
+	streamHandler := rest.NewProductStreamHandler(service.NewProductService(new(ProductRepositoryMock)), broker)
+	streamHandler.KeepAlive = 50 * time.Millisecond
+	routes.ProductStreamRoutes(app, streamHandler)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go app.Listener(listener)
+	return "http://" + listener.Addr().String(), func() { app.Shutdown() }
+}
+
+func TestProductStream_FilteredEvents(t *testing.T) {
+	// Inisialisasi service dengan broker sebagai publisher
+	broker := eventstream.NewBroker(100)
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("GetByID", "1").Return(&entity.Product{ID: "1", Name: "Product A", Stock: 10}, nil)
+	productRepoMock.On("GetByID", "2").Return(&entity.Product{ID: "2", Name: "Product B", Stock: 5}, nil)
+	productRepoMock.On("Update", mock.AnythingOfType("*entity.Product")).Return(nil)
+	productService := service.NewProductService(productRepoMock, service.WithPublisher(broker))
+
+	baseURL, shutdown := startStreamApp(t, broker)
+	defer shutdown()
+
+	// Berlangganan hanya untuk produk dengan ID 1
+	resp, err := http.Get(baseURL + "/products/stream?id=1")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	readEvent := newEventReader(t, resp)
+	assert.Equal(t, "retry: 3000", readEvent())
+
+	// Tunggu sampai subscriber terdaftar sebelum mengirim perubahan
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, productService.UpdateProduct(context.Background(), &entity.Product{ID: "2", Name: "Product B", Stock: 6}))
+	require.NoError(t, productService.UpdateProduct(context.Background(), &entity.Product{ID: "1", Name: "Product A", Stock: 10}))
+
+	event := readEvent()
+	for strings.HasPrefix(event, ":") {
+		event = readEvent()
+	}
+	assert.Contains(t, event, "id: 3\nevent: product.updated\n")
+	assert.Contains(t, event, `"product":{"id":"1","name":"Product A","stock":10}`)
+}
+
+func TestProductStream_ResetAfterGap(t *testing.T) {
+	broker := eventstream.NewBroker(1)
+	for i := 1; i <= 3; i++ {
+		broker.Publish(entity.ProductEvent{Type: entity.ProductCreated, Product: entity.Product{ID: strconv.Itoa(i)}})
+	}
+	baseURL, shutdown := startStreamApp(t, broker)
+	defer shutdown()
+
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/products/stream", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Reset membawa ID terakhir saat berlangganan, tanpa backlog lama
+	readEvent := newEventReader(t, resp)
+	assert.Equal(t, "retry: 3000", readEvent())
+	assert.Equal(t, "id: 3\nevent: reset\ndata: {}", readEvent())
+
+	time.Sleep(50 * time.Millisecond)
+	broker.Publish(entity.ProductEvent{Type: entity.ProductDeleted, Product: entity.Product{ID: "3"}})
+	event := readEvent()
+	for strings.HasPrefix(event, ":") {
+		event = readEvent()
+	}
+	assert.Contains(t, event, "id: 4\nevent: product.deleted\n")
+}
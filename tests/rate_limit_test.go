@@ -0,0 +1,135 @@
+package handler_test
+
+import (
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/adapter/ratelimit"
+	"go-hexagon/internal/core/domain/entity"
+	"net/http"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRateLimitedApp(store ratelimit.Store, config ratelimit.Config) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if key := c.Get("X-API-Key"); key != "" {
+			principal := entity.Principal{Subject: key, Method: entity.AuthMethodAPIKey}
+			c.SetUserContext(entity.WithPrincipal(c.UserContext(), principal))
+		}
+		return c.Next()
+	})
+	app.Use(ratelimit.New(config, store).Middleware())
+	app.Get("/products", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	app.Post("/products", func(c *fiber.Ctx) error { return c.SendString("created") })
+	return app
+}
+
+func testRateLimits(t *testing.T, store ratelimit.Store) {
+	config := ratelimit.Config{
+		Read:    ratelimit.Limit{Rate: 1, Burst: 3},
+		Write:   ratelimit.Limit{Rate: 0.5, Burst: 1},
+		Clients: map[string]ratelimit.ClientLimits{"bulk-import": {Write: &ratelimit.Limit{Rate: 10, Burst: 2}}},
+	}
+	app := newRateLimitedApp(store, config)
+
+	// Write lebih ketat: request kedua langsung ditolak
+	resp, _ := send(t, app, http.MethodPost, "/products", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "2", resp.Header.Get("X-RateLimit-Reset"))
+
+	resp, body := send(t, app, http.MethodPost, "/products", nil)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("Retry-After"))
+	assert.JSONEq(t, `{"error":"Rate limit exceeded"}`, body)
+
+	// Bucket read terpisah dari bucket write
+	for i := 2; i >= 0; i-- {
+		resp, _ = send(t, app, http.MethodGet, "/products", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, string(rune('0'+i)), resp.Header.Get("X-RateLimit-Remaining"))
+	}
+	resp, _ = send(t, app, http.MethodGet, "/products", nil)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	// Setiap API key punya bucket sendiri, dan override per client berlaku
+	headers := map[string]string{"X-API-Key": "bulk-import"}
+	for i := 0; i < 2; i++ {
+		resp, _ = send(t, app, http.MethodPost, "/products", headers)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	resp, _ = send(t, app, http.MethodPost, "/products", headers)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+}
+
+func TestRateLimit_IPLimitBeforeAuthentication(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Config{IP: ratelimit.Limit{Rate: 1, Burst: 2}}, ratelimit.NewMemoryStore())
+	app := fiber.New()
+	app.Use(limiter.IPMiddleware())
+	// Autentikasi yang selalu gagal
+	app.Use(func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid credentials"})
+	})
+
+	// Percobaan kredensial yang gagal tetap menghabiskan bucket IP
+	for i := 0; i < 2; i++ {
+		resp, _ := send(t, app, http.MethodGet, "/products", nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	}
+	resp, _ := send(t, app, http.MethodGet, "/products", nil)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+}
+
+func TestRateLimit_MemoryStore(t *testing.T) {
+	testRateLimits(t, ratelimit.NewMemoryStore())
+}
+
+func TestRateLimit_RedisStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	testRateLimits(t, ratelimit.NewRedisStore(client, "test:"))
+	assert.True(t, server.Exists("test:ip:0.0.0.0:write"))
+}
+
+func TestRateLimit_IPFromTrustedProxyOnly(t *testing.T) {
+	newApp := func(trustedProxies ...string) *fiber.App {
+		limiter := ratelimit.New(ratelimit.Config{IP: ratelimit.Limit{Rate: 1, Burst: 1}}, ratelimit.NewMemoryStore())
+		app := fiber.New(rest.ProxyConfig(trustedProxies, fiber.HeaderXForwardedFor))
+		app.Use(limiter.IPMiddleware())
+		app.Get("/ip", func(c *fiber.Ctx) error { return c.SendString(c.IP()) })
+		return app
+	}
+	forwardedFor := func(ip string) map[string]string {
+		return map[string]string{fiber.HeaderXForwardedFor: ip}
+	}
+
+	// Request dari app.Test datang dari 0.0.0.0, yang di sini adalah proxy tepercaya:
+	// setiap client di belakang proxy punya bucket sendiri
+	app := newApp("0.0.0.0")
+	resp, body := send(t, app, http.MethodGet, "/ip", forwardedFor("203.0.113.7"))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "203.0.113.7", body)
+	resp, _ = send(t, app, http.MethodGet, "/ip", forwardedFor("203.0.113.8"))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = send(t, app, http.MethodGet, "/ip", forwardedFor("203.0.113.7"))
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	// Header dari peer yang tidak tepercaya diabaikan, sehingga client tidak
+	// dapat menghindari batas dengan mengganti X-Forwarded-For
+	for _, app := range []*fiber.App{newApp(), newApp("10.0.0.0/8")} {
+		resp, body = send(t, app, http.MethodGet, "/ip", forwardedFor("203.0.113.7"))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "0.0.0.0", body)
+		resp, _ = send(t, app, http.MethodGet, "/ip", forwardedFor("203.0.113.8"))
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	}
+}
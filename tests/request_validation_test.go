@@ -0,0 +1,117 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/adapter/openapi"
+	"go-hexagon/internal/adapter/routes"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newValidatedApp(t *testing.T, productRepoMock *ProductRepositoryMock) *fiber.App {
+	validator, err := openapi.NewValidator(openapi.Document(), openapi.ValidatorConfig{MaxBodyBytes: 256})
+	require.NoError(t, err)
+
+	app := fiber.New()
+	apiVersions := routes.NewAPIVersions(app, "v1", routes.APIVersion{Name: "v1"})
+	app.Use(validator.Middleware())
+	apiVersions.Handle("v1", func(api fiber.Router) {
+		routes.ProductRoutesMySQL(api, rest.NewProductHandlerMySQL(service.NewProductService(productRepoMock)))
+	})
+	return app
+}
+
+func sendJSON(t *testing.T, app *fiber.App, method, path, body string) (int, rest.ErrorResponse) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+
+	var result rest.ErrorResponse
+	json.Unmarshal([]byte(getResponseBody(t, resp)), &result)
+	return resp.StatusCode, result
+}
+
+func TestValidation_ListsEveryViolation(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	app := newValidatedApp(t, productRepoMock)
+
+	// Tipe salah, field tidak dikenal dan field wajib yang hilang
+	status, result := sendJSON(t, app, http.MethodPost, "/products", `{"stock": "AAAA", "color": "red"}`)
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "Request validation failed", result.Error)
+	assert.ElementsMatch(t, []rest.Violation{
+		{Location: "body", Field: "name", Message: "is required"},
+		{Location: "body", Field: "stock", Message: "must be an integer"},
+		{Location: "body", Field: "color", Message: "is not a known field"},
+	}, result.Violations)
+	productRepoMock.AssertNotCalled(t, "Create")
+}
+
+func TestValidation_PathParamAndBodySize(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	app := newValidatedApp(t, productRepoMock)
+
+	status, result := sendJSON(t, app, http.MethodPut, "/products/not-an-id", `{"stock": -1}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.ElementsMatch(t, []rest.Violation{
+		{Location: "path", Field: "id", Message: "must match ^([0-9]+|[0-9a-fA-F]{24})$"},
+		{Location: "body", Field: "stock", Message: "must be greater than or equal to 0"},
+	}, result.Violations)
+
+	status, result = sendJSON(t, app, http.MethodPost, "/products", `{"name": "`+strings.Repeat("a", 300)+`", "stock": 1}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, []rest.Violation{{Location: "body", Message: "must not exceed 256 bytes"}}, result.Violations)
+}
+
+func TestValidation_MixedCasePath(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	app := newValidatedApp(t, productRepoMock)
+
+	// Router Fiber tidak membedakan huruf besar, jadi validator juga tidak
+	for _, path := range []string{"/API/V1/Products", "/api/v1/PRODUCTS/"} {
+		status, result := sendJSON(t, app, http.MethodPost, path, `{"stock": "AAAA"}`)
+		assert.Equal(t, http.StatusBadRequest, status, path)
+		assert.Equal(t, "Request validation failed", result.Error, path)
+	}
+	productRepoMock.AssertNotCalled(t, "Create")
+}
+
+func TestValidation_ValidRequestReachesHandler(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("Create", mock.AnythingOfType("*entity.Product")).Return(nil)
+	productRepoMock.On("GetByID", "1").Return(&entity.Product{ID: "1", Name: "Product A", Stock: 1}, nil)
+	app := newValidatedApp(t, productRepoMock)
+
+	status, _ := sendJSON(t, app, http.MethodPost, "/products", `{"name": "Product A", "stock": 10}`)
+	assert.Equal(t, http.StatusCreated, status)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/products/1", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	productRepoMock.AssertExpectations(t)
+}
+
+func TestValidation_IntegerQueryParams(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	app := newValidatedApp(t, productRepoMock)
+
+	// Angka yang bukan integer desimal ditolak validator, bukan oleh handler
+	for _, limit := range []string{"1e3", "10.0", "0x10", "5abc"} {
+		status, result := sendJSON(t, app, http.MethodGet, "/products/search?q=a&limit="+limit, "")
+		assert.Equal(t, http.StatusBadRequest, status, limit)
+		assert.Equal(t, []rest.Violation{{Location: "query", Field: "limit", Message: "must be an integer"}}, result.Violations, limit)
+	}
+	productRepoMock.AssertNotCalled(t, "Search")
+}
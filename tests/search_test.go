@@ -0,0 +1,130 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/adapter/repository"
+	"go-hexagon/internal/adapter/routes"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSearchRepository(t *testing.T, names ...string) *repository.ProductRepositoryMemory {
+	repo := repository.NewProductRepositoryMemory()
+	for _, name := range names {
+		require.NoError(t, repo.Create(context.Background(), &entity.Product{Name: name, Stock: 1}))
+	}
+	return repo
+}
+
+func searchNames(t *testing.T, repo *repository.ProductRepositoryMemory, text string) []string {
+	result, err := repo.Search(context.Background(), entity.ProductSearch{Text: text, Limit: 10})
+	require.NoError(t, err)
+	names := []string{}
+	for _, hit := range result.Hits {
+		names = append(names, hit.Product.Name)
+	}
+	return names
+}
+
+func TestSearchTerms(t *testing.T) {
+	assert.Equal(t, []string{"red", "shoes", "42"}, entity.SearchTerms("  Red, shoes! (42) red"))
+	assert.Empty(t, entity.SearchTerms(" -- !"))
+}
+
+func TestSearchMemoryRanksByRelevance(t *testing.T) {
+	repo := newSearchRepository(t, "Blue Shoes", "Red Hat", "Red Running Shoes", "Green Scarf")
+
+	// Produk yang cocok dengan lebih banyak kata berada di urutan pertama
+	assert.Equal(t, []string{"Red Running Shoes", "Blue Shoes", "Red Hat"}, searchNames(t, repo, "red shoes"))
+	assert.Equal(t, []string{"Green Scarf"}, searchNames(t, repo, "SCARF"))
+	assert.Empty(t, searchNames(t, repo, "sock"))
+}
+
+func TestSearchMemoryFollowsWrites(t *testing.T) {
+	repo := newSearchRepository(t, "Red Hat", "Blue Shoes")
+
+	require.NoError(t, repo.Update(context.Background(), &entity.Product{ID: "1", Name: "Green Hat", Stock: 1}))
+	assert.Empty(t, searchNames(t, repo, "red"))
+	assert.Equal(t, []string{"Green Hat"}, searchNames(t, repo, "green"))
+
+	require.NoError(t, repo.Delete(context.Background(), "2"))
+	assert.Empty(t, searchNames(t, repo, "shoes"))
+}
+
+func TestSearchMemoryPaginates(t *testing.T) {
+	repo := newSearchRepository(t, "Shoe A", "Shoe B", "Shoe C")
+
+	result, err := repo.Search(context.Background(), entity.ProductSearch{Text: "shoe", Offset: 1, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.Total)
+	require.Len(t, result.Hits, 1)
+	assert.Equal(t, "Shoe B", result.Hits[0].Product.Name)
+}
+
+func TestSearchMySQLKeepsStopwords(t *testing.T) {
+	db, products := openProductDatabase(t, "The Red Shoes", 1)
+	repo := repository.NewProductRepositoryMySQL(db).(*repository.ProductRepositoryMySQL)
+
+	// Index dibuat tanpa stopword, seperti backend lain
+	require.NoError(t, repo.EnsureSearchIndex(context.Background()))
+	assert.Equal(t, []string{
+		"SET SESSION innodb_ft_enable_stopword = OFF",
+		"CREATE FULLTEXT INDEX idx_products_search ON products (name)",
+	}, products.statements)
+
+	// Boolean mode tidak membuang kata yang ada di lebih dari separuh baris
+	_, err := repo.Search(context.Background(), entity.ProductSearch{Text: "The red, shoes!", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, products.searches, 2)
+	for i, query := range products.searches {
+		assert.Contains(t, query, "MATCH(name) AGAINST (? IN BOOLEAN MODE)")
+		assert.NotContains(t, query, "NATURAL LANGUAGE")
+		assert.Equal(t, "the red shoes", products.searchArgs[i])
+	}
+}
+
+func newSearchApp(productService *service.ProductService) *fiber.App {
+	app := fiber.New()
+	routes.ProductRoutesMySQLV2(app, rest.NewProductHandlerMySQL(productService))
+	return app
+}
+
+func TestSearchProductsEndpoint(t *testing.T) {
+	repo := newSearchRepository(t, "Blue Shoes", "Red Hat", "Red Running Shoes")
+	app := newSearchApp(service.NewProductService(repo, service.WithSearcher(repo)))
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/products/search?q=red+shoes&limit=2", nil), -1)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body rest.ProductSearchResponse
+	require.NoError(t, json.Unmarshal([]byte(getResponseBody(t, resp)), &body))
+	assert.Equal(t, int64(3), body.Total)
+	assert.Equal(t, 2, body.Limit)
+	require.Len(t, body.Items, 2)
+	// ID MySQL tetap berupa angka
+	assert.Equal(t, float64(3), body.Items[0].ID)
+	assert.Equal(t, "Red Running Shoes", body.Items[0].Name)
+	assert.Greater(t, body.Items[0].Score, body.Items[1].Score)
+}
+
+func TestSearchProductsEndpointErrors(t *testing.T) {
+	repo := newSearchRepository(t, "Red Hat")
+
+	resp, err := newSearchApp(service.NewProductService(repo, service.WithSearcher(repo))).Test(httptest.NewRequest(http.MethodGet, "/products/search?q=%20!", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = newSearchApp(service.NewProductService(repo)).Test(httptest.NewRequest(http.MethodGet, "/products/search?q=red", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+}
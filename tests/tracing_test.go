@@ -0,0 +1,139 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/adapter/routes"
+	"go-hexagon/internal/adapter/tracing"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Tracer global yang sudah diambil (mis. oleh service) tetap memakai provider
+// pertama, sehingga provider dan recorder dipasang sekali untuk semua test.
+var (
+	traceRecorder     = tracetest.NewSpanRecorder()
+	installTracerOnce sync.Once
+)
+
+// newTracedApp mengembalikan fungsi yang memberikan span yang selesai sejak
+// app dibuat.
+func newTracedApp(t *testing.T, productRepoMock *ProductRepositoryMock) (*fiber.App, func() []sdktrace.ReadOnlySpan) {
+	installTracerOnce.Do(func() {
+		_, err := tracing.Setup(tracing.Config{Exporter: tracing.ExporterNone})
+		require.NoError(t, err)
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(traceRecorder)))
+	})
+	seen := len(traceRecorder.Ended())
+
+	app := fiber.New()
+	app.Use(tracing.Middleware())
+	routes.ProductRoutesMySQL(app, rest.NewProductHandlerMySQL(service.NewProductService(productRepoMock)))
+	return app, func() []sdktrace.ReadOnlySpan { return traceRecorder.Ended()[seen:] }
+}
+
+func TestTracing_SpansFollowTraceparent(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("GetByID", "1").Return(&entity.Product{ID: "1", Name: "Product A", Stock: 10}, nil)
+	app, ended := newTracedApp(t, productRepoMock)
+
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	resp, _ := get(t, app, "/products/1", map[string]string{"traceparent": traceparent})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	spans := ended()
+	require.Len(t, spans, 2)
+	serviceSpan, serverSpan := spans[0], spans[1]
+
+	// Span server melanjutkan trace dari header traceparent
+	assert.Equal(t, "GET /products/:id", serverSpan.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", serverSpan.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", serverSpan.Parent().SpanID().String())
+	assert.Contains(t, serverSpan.Attributes(), attribute.String("http.route", "/products/:id"))
+	assert.Contains(t, serverSpan.Attributes(), attribute.String("url.path", "/products/1"))
+	assert.Contains(t, serverSpan.Attributes(), attribute.Int("http.response.status_code", 200))
+
+	// Span service adalah anak dari span server
+	assert.Equal(t, "ProductService.GetProductByID", serviceSpan.Name())
+	assert.Equal(t, serverSpan.SpanContext().SpanID(), serviceSpan.Parent().SpanID())
+}
+
+func TestTracing_ServerErrorsMarkTheSpan(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("List").Return([]entity.Product(nil), assert.AnError)
+	app, ended := newTracedApp(t, productRepoMock)
+
+	resp, _ := get(t, app, "/products", nil)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	spans := ended()
+	require.Len(t, spans, 2)
+	serverSpan := spans[1]
+	assert.Equal(t, codes.Error, serverSpan.Status().Code)
+	// Tanpa traceparent, span server menjadi root trace baru
+	assert.False(t, serverSpan.Parent().IsValid())
+}
+
+func TestTracing_OTLPFileExport(t *testing.T) {
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("List").Return([]entity.Product(nil), assert.AnError)
+	app, ended := newTracedApp(t, productRepoMock)
+
+	resp, _ := get(t, app, "/products", map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"})
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	var out bytes.Buffer
+	require.NoError(t, tracing.NewOTLPFileExporter(&out).ExportSpans(context.Background(), ended()))
+
+	// Satu ExportTraceServiceRequest OTLP JSON per baris
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 1)
+	var request struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Scope struct{ Name string }
+				Spans []map[string]interface{}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(lines[0], &request))
+	require.Len(t, request.ResourceSpans, 1)
+
+	var serverSpan map[string]interface{}
+	for _, scopeSpans := range request.ResourceSpans[0].ScopeSpans {
+		for _, span := range scopeSpans.Spans {
+			if span["name"] == "GET /products" {
+				serverSpan = span
+			}
+		}
+	}
+	require.NotNil(t, serverSpan)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", serverSpan["traceId"])
+	assert.Equal(t, "00f067aa0ba902b7", serverSpan["parentSpanId"])
+	// ID ditulis hex seperti OTLP/JSON, bukan base64 seperti protojson
+	assert.Regexp(t, `^[0-9a-f]{16}$`, serverSpan["spanId"])
+	// SPAN_KIND_SERVER dan STATUS_CODE_ERROR
+	assert.Equal(t, float64(2), serverSpan["kind"])
+	assert.Equal(t, float64(2), serverSpan["status"].(map[string]interface{})["code"])
+	assert.Regexp(t, `^[0-9]+$`, serverSpan["startTimeUnixNano"])
+	assert.Contains(t, serverSpan["attributes"], map[string]interface{}{
+		"key": "http.response.status_code", "value": map[string]interface{}{"intValue": "500"},
+	})
+	assert.Contains(t, serverSpan["attributes"], map[string]interface{}{
+		"key": "http.route", "value": map[string]interface{}{"stringValue": "/products"},
+	})
+}
@@ -0,0 +1,117 @@
+package handler_test
+
+import (
+	"context"
+	"go-hexagon/internal/adapter/repository"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/migration"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Menyiapkan dua store hasil copy, lalu membuat keduanya berbeda: produk 1
+// hilang di target, produk 2 berbeda stock, dan target punya produk tambahan
+func divergedStores(t *testing.T) (*repository.ProductRepositoryMemory, *repository.ProductRepositoryMemory, *repository.ProductIDMapMemory) {
+	ctx := context.Background()
+	source := repository.NewProductRepositoryMemory()
+	seedProducts(t, source, 3)
+	target := repository.NewProductRepositoryMemory()
+	ids := repository.NewProductIDMapMemory()
+	_, err := newTestCopier(source, target, ids, "").Run(ctx)
+	require.NoError(t, err)
+
+	mapped, err := ids.Lookup(ctx, []string{"1", "2"})
+	require.NoError(t, err)
+	require.NoError(t, target.Delete(ctx, mapped["1"]))
+	require.NoError(t, target.Update(ctx, &entity.Product{ID: mapped["2"], Name: "Product 2", Stock: 20}))
+	require.NoError(t, target.Create(ctx, &entity.Product{Name: "Extra", Stock: 7}))
+	return source, target, ids
+}
+
+func newTestVerifier(source, target *repository.ProductRepositoryMemory, ids *repository.ProductIDMapMemory, truth string) *migration.Verifier {
+	verifier := migration.NewVerifier(source, target, ids, migration.VerifyConfig{Source: "mysql", Target: "mongodb", BatchSize: 2, Repair: truth})
+	verifier.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	return verifier
+}
+
+func TestVerifyConsistentStores(t *testing.T) {
+	source := repository.NewProductRepositoryMemory()
+	seedProducts(t, source, 5)
+	target := repository.NewProductRepositoryMemory()
+	ids := repository.NewProductIDMapMemory()
+	_, err := newTestCopier(source, target, ids, "").Run(context.Background())
+	require.NoError(t, err)
+
+	report, err := newTestVerifier(source, target, ids, "").Run(context.Background())
+	require.NoError(t, err)
+	assert.True(t, report.Consistent)
+	assert.Equal(t, 5, report.SourceCount)
+	assert.Equal(t, 5, report.TargetCount)
+	assert.Empty(t, report.Differences)
+}
+
+func TestVerifyReportsDifferences(t *testing.T) {
+	source, target, ids := divergedStores(t)
+
+	report, err := newTestVerifier(source, target, ids, "").Run(context.Background())
+	require.NoError(t, err)
+	assert.False(t, report.Consistent)
+	assert.Equal(t, 1, report.Missing)
+	assert.Equal(t, 1, report.Extra)
+	assert.Equal(t, 1, report.Differing)
+	assert.Equal(t, 0, report.Repaired)
+
+	require.Len(t, report.Differences, 3)
+	assert.Equal(t, migration.KindMissing, report.Differences[0].Kind)
+	assert.Equal(t, "1", report.Differences[0].SourceID)
+	assert.Equal(t, migration.KindDiffers, report.Differences[1].Kind)
+	assert.Equal(t, []migration.FieldDiff{{Field: "stock", Source: 2, Target: 20}}, report.Differences[1].Fields)
+	assert.Equal(t, migration.KindExtra, report.Differences[2].Kind)
+	assert.Empty(t, report.Differences[2].SourceID)
+}
+
+func TestVerifyRepairsFromSource(t *testing.T) {
+	source, target, ids := divergedStores(t)
+
+	report, err := newTestVerifier(source, target, ids, migration.TruthSource).Run(context.Background())
+	require.NoError(t, err)
+	assert.True(t, report.Consistent)
+	assert.Equal(t, 3, report.Repaired)
+
+	// Pemeriksaan ulang tidak menemukan perbedaan, dan source tidak berubah
+	report, err = newTestVerifier(source, target, ids, "").Run(context.Background())
+	require.NoError(t, err)
+	assert.True(t, report.Consistent)
+	assert.Empty(t, report.Differences)
+	assert.Equal(t, 3, report.SourceCount)
+	product, err := source.GetByID(context.Background(), "2")
+	require.NoError(t, err)
+	assert.Equal(t, 2, product.Stock)
+}
+
+func TestVerifyRepairsFromTarget(t *testing.T) {
+	source, target, ids := divergedStores(t)
+
+	report, err := newTestVerifier(source, target, ids, migration.TruthTarget).Run(context.Background())
+	require.NoError(t, err)
+	assert.True(t, report.Consistent)
+	assert.Equal(t, 3, report.Repaired)
+
+	report, err = newTestVerifier(source, target, ids, "").Run(context.Background())
+	require.NoError(t, err)
+	assert.True(t, report.Consistent)
+	assert.Empty(t, report.Differences)
+
+	// Produk 1 dihapus dari source, produk 2 mengikuti target, dan produk
+	// tambahan dibuat di source
+	products, err := source.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, products, 3)
+	assert.Equal(t, "2", products[0].ID)
+	assert.Equal(t, 20, products[0].Stock)
+	assert.Equal(t, "Extra", products[2].Name)
+}
@@ -0,0 +1,365 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go-hexagon/internal/adapter/handler/rest"
+	"go-hexagon/internal/adapter/logging"
+	"go-hexagon/internal/adapter/routes"
+	"go-hexagon/internal/adapter/webhook"
+	"go-hexagon/internal/core/domain/entity"
+	"go-hexagon/internal/core/service"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// Repository webhook in-memory untuk pengujian
+type WebhookRepositoryFake struct {
+	mu         sync.Mutex
+	webhooks   map[string]entity.Webhook
+	deliveries map[string]entity.WebhookDelivery
+}
+
+func NewWebhookRepositoryFake() *WebhookRepositoryFake {
+	return &WebhookRepositoryFake{
+		webhooks:   map[string]entity.Webhook{},
+		deliveries: map[string]entity.WebhookDelivery{},
+	}
+}
+
+func (r *WebhookRepositoryFake) Create(webhook *entity.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.webhooks[webhook.ID] = *webhook
+	return nil
+}
+
+func (r *WebhookRepositoryFake) Update(webhook *entity.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.webhooks[webhook.ID]; !ok {
+		return entity.ErrWebhookNotFound
+	}
+	r.webhooks[webhook.ID] = *webhook
+	return nil
+}
+
+func (r *WebhookRepositoryFake) GetByID(id string) (*entity.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return nil, entity.ErrWebhookNotFound
+	}
+	return &webhook, nil
+}
+
+func (r *WebhookRepositoryFake) List() ([]entity.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var webhooks []entity.Webhook
+	for _, webhook := range r.webhooks {
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookRepositoryFake) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.webhooks[id]; !ok {
+		return entity.ErrWebhookNotFound
+	}
+	delete(r.webhooks, id)
+	return nil
+}
+
+func (r *WebhookRepositoryFake) SaveDelivery(delivery *entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (r *WebhookRepositoryFake) GetDelivery(id string) (*entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return nil, entity.ErrDeliveryNotFound
+	}
+	return &delivery, nil
+}
+
+func (r *WebhookRepositoryFake) ListDeliveries(webhookID string) ([]entity.WebhookDelivery, error) {
+	return r.filterDeliveries(func(d entity.WebhookDelivery) bool { return d.WebhookID == webhookID }), nil
+}
+
+func (r *WebhookRepositoryFake) ListDeadLetters() ([]entity.WebhookDelivery, error) {
+	return r.filterDeliveries(func(d entity.WebhookDelivery) bool { return d.Status == entity.DeliveryDead }), nil
+}
+
+func (r *WebhookRepositoryFake) ListDueDeliveries(now time.Time, limit int) ([]entity.WebhookDelivery, error) {
+	deliveries := r.filterDeliveries(func(d entity.WebhookDelivery) bool {
+		return d.Status == entity.DeliveryPending && (d.NextAttemptAt == nil || !d.NextAttemptAt.After(now))
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookRepositoryFake) filterDeliveries(match func(entity.WebhookDelivery) bool) []entity.WebhookDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deliveries []entity.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if match(delivery) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries
+}
+
+func newTestDispatcher(repo *WebhookRepositoryFake) *webhook.Dispatcher {
+	dispatcher := newStoppedDispatcher(repo)
+	dispatcher.Start()
+	return dispatcher
+}
+
+func newStoppedDispatcher(repo *WebhookRepositoryFake) *webhook.Dispatcher {
+	return webhook.NewDispatcher(repo, slog.New(slog.NewTextHandler(io.Discard, nil)), webhook.Config{
+		Workers:      1,
+		QueueSize:    16,
+		MaxAttempts:  3,
+		BaseBackoff:  10 * time.Millisecond,
+		MaxBackoff:   50 * time.Millisecond,
+		Timeout:      time.Second,
+		PollInterval: 5 * time.Millisecond,
+	})
+}
+
+func newCountingReceiver(status int) (*httptest.Server, *int32) {
+	var calls int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(status)
+	}))
+	return receiver, &calls
+}
+
+func TestWebhook_SignedDeliveryWithRetry(t *testing.T) {
+	// Receiver gagal dua kali lalu berhasil, dan memverifikasi tanda tangan
+	var calls int32
+	received := make(chan map[string]interface{}, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		if !webhook.Verify("s3cret", timestamp, body, r.Header.Get(webhook.HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var payload map[string]interface{}
+		json.Unmarshal(body, &payload)
+		received <- payload
+	}))
+	defer receiver.Close()
+
+	webhookRepo := NewWebhookRepositoryFake()
+	dispatcher := newTestDispatcher(webhookRepo)
+	defer dispatcher.Stop()
+
+	webhookService := service.NewWebhookService(webhookRepo, dispatcher)
+	require.NoError(t, webhookService.CreateWebhook(&entity.Webhook{
+		URL:    receiver.URL,
+		Events: []string{entity.ProductCreated},
+		Secret: "s3cret",
+		Active: true,
+	}))
+
+	// Buat produk melalui service agar event dikirim
+	productRepoMock := new(ProductRepositoryMock)
+	productRepoMock.On("Create", mock.AnythingOfType("*entity.Product")).Run(func(args mock.Arguments) {
+		args.Get(0).(*entity.Product).ID = "7"
+	}).Return(nil)
+	productService := service.NewProductService(productRepoMock, service.WithPublisher(dispatcher))
+	require.NoError(t, productService.CreateProduct(context.Background(), &entity.Product{Name: "Product A", Stock: 10}))
+
+	select {
+	case payload := <-received:
+		assert.Equal(t, entity.ProductCreated, payload["type"])
+		assert.Equal(t, map[string]interface{}{"id": "7", "name": "Product A", "stock": float64(10)}, payload["product"])
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+
+	// Assert bahwa delivery log mencatat tiga percobaan
+	assert.Eventually(t, func() bool {
+		deliveries := webhookRepo.filterDeliveries(func(entity.WebhookDelivery) bool { return true })
+		return len(deliveries) == 1 && deliveries[0].Status == entity.DeliverySucceeded && deliveries[0].Attempts == 3
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWebhook_DeadLetterAfterMaxAttempts(t *testing.T) {
+	// Receiver selalu gagal
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	var buf bytes.Buffer
+	webhookRepo := NewWebhookRepositoryFake()
+	dispatcher := newStoppedDispatcher(webhookRepo)
+	dispatcher.Logger, _ = logging.New(&buf, logging.FormatJSON, "info")
+	dispatcher.Start()
+	defer dispatcher.Stop()
+
+	webhookService := service.NewWebhookService(webhookRepo, dispatcher)
+	require.NoError(t, webhookService.CreateWebhook(&entity.Webhook{URL: receiver.URL, Active: true}))
+
+	dispatcher.Publish(entity.ProductEvent{Type: entity.ProductDeleted, Product: entity.Product{ID: "1"}})
+
+	// Assert bahwa delivery masuk ke dead letter
+	var deadLetters []entity.WebhookDelivery
+	require.Eventually(t, func() bool {
+		deadLetters, _ = webhookService.ListDeadLetters()
+		return len(deadLetters) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 3, deadLetters[0].Attempts)
+	assert.Equal(t, http.StatusInternalServerError, deadLetters[0].StatusCode)
+
+	// Dead letter dicatat sebagai ERROR beserta ID delivery dan webhook
+	dispatcher.Stop()
+	lines := logLines(t, &buf)
+	deadLog := lines[len(lines)-1]
+	assert.Equal(t, "ERROR", deadLog["level"])
+	assert.Equal(t, "Webhook delivery moved to dead letters", deadLog["msg"])
+	assert.Equal(t, deadLetters[0].ID, deadLog["delivery_id"])
+	assert.Equal(t, deadLetters[0].WebhookID, deadLog["webhook_id"])
+	assert.Equal(t, float64(3), deadLog["attempts"])
+	assert.Equal(t, "receiver responded with status 500", deadLog["error"])
+	assert.Equal(t, "WARN", lines[0]["level"])
+	assert.Equal(t, "Webhook delivery failed, retrying", lines[0]["msg"])
+}
+
+func TestWebhook_RetryDoesNotHoldWorker(t *testing.T) {
+	// Satu worker: retry webhook yang gagal tidak boleh menahan webhook lain
+	failing, _ := newCountingReceiver(http.StatusInternalServerError)
+	defer failing.Close()
+	healthy, healthyCalls := newCountingReceiver(http.StatusOK)
+	defer healthy.Close()
+
+	webhookRepo := NewWebhookRepositoryFake()
+	require.NoError(t, webhookRepo.Create(&entity.Webhook{ID: "failing", URL: failing.URL, Events: []string{entity.ProductDeleted}, Active: true}))
+	dispatcher := webhook.NewDispatcher(webhookRepo, slog.New(slog.NewTextHandler(io.Discard, nil)), webhook.Config{
+		Workers: 1, QueueSize: 16, MaxAttempts: 3,
+		BaseBackoff: time.Hour, MaxBackoff: time.Hour, Timeout: time.Second, PollInterval: 5 * time.Millisecond,
+	})
+	dispatcher.Start()
+	defer dispatcher.Stop()
+
+	dispatcher.Publish(entity.ProductEvent{Type: entity.ProductDeleted, Product: entity.Product{ID: "1"}})
+	require.Eventually(t, func() bool {
+		deliveries, _ := webhookRepo.ListDeliveries("failing")
+		return len(deliveries) == 1 && deliveries[0].Attempts == 1
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, webhookRepo.Create(&entity.Webhook{ID: "healthy", URL: healthy.URL, Events: []string{entity.ProductCreated}, Active: true}))
+	dispatcher.Publish(entity.ProductEvent{Type: entity.ProductCreated, Product: entity.Product{ID: "2"}})
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(healthyCalls) == 1 }, time.Second, 5*time.Millisecond)
+
+	// Retry berikutnya tersimpan untuk satu jam lagi
+	deliveries, _ := webhookRepo.ListDeliveries("failing")
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, entity.DeliveryPending, deliveries[0].Status)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *deliveries[0].NextAttemptAt, time.Minute)
+}
+
+func TestWebhook_ResumesPendingDeliveriesAfterRestart(t *testing.T) {
+	receiver, calls := newCountingReceiver(http.StatusOK)
+	defer receiver.Close()
+
+	webhookRepo := NewWebhookRepositoryFake()
+	require.NoError(t, webhookRepo.Create(&entity.Webhook{ID: "hook", URL: receiver.URL, Active: true}))
+
+	// Event yang masih di antrean saat Stop disimpan sebagai pending
+	stopped := newStoppedDispatcher(webhookRepo)
+	stopped.Publish(entity.ProductEvent{Type: entity.ProductDeleted, Product: entity.Product{ID: "1"}})
+	stopped.Stop()
+	deliveries, _ := webhookRepo.ListDeliveries("hook")
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, entity.DeliveryPending, deliveries[0].Status)
+	assert.Zero(t, atomic.LoadInt32(calls))
+
+	// Dispatcher baru melanjutkan pengiriman yang tersimpan
+	dispatcher := newTestDispatcher(webhookRepo)
+	defer dispatcher.Stop()
+	assert.Eventually(t, func() bool {
+		deliveries, _ := webhookRepo.ListDeliveries("hook")
+		return deliveries[0].Status == entity.DeliverySucceeded
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestWebhook_EventAndProductFilters(t *testing.T) {
+	webhook := entity.Webhook{Events: []string{entity.ProductStockChanged}, ProductIDs: []string{"1"}, Active: true}
+
+	assert.True(t, webhook.Matches(entity.ProductStockChanged, "1"))
+	assert.False(t, webhook.Matches(entity.ProductStockChanged, "2"))
+	assert.False(t, webhook.Matches(entity.ProductUpdated, "1"))
+
+	webhook.Active = false
+	assert.False(t, webhook.Matches(entity.ProductStockChanged, "1"))
+}
+
+func TestWebhookHandler_CreateAndValidate(t *testing.T) {
+	webhookRepo := NewWebhookRepositoryFake()
+	webhookHandler := rest.NewWebhookHandler(service.NewWebhookService(webhookRepo, nil))
+
+	app := fiber.New()
+	routes.WebhookRoutes(app, webhookHandler)
+
+	// Event yang tidak dikenal ditolak
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{"url":"http://example.com/hook","events":["product.renamed"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// Webhook valid dibuat dan secret dikembalikan sekali
+	req = httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(`{"url":"http://example.com/hook","events":["product.created"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req, -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var created rest.WebhookResponse
+	require.NoError(t, json.Unmarshal([]byte(getResponseBody(t, resp)), &created))
+	assert.NotEmpty(t, created.ID)
+	assert.NotEmpty(t, created.Secret)
+	assert.True(t, created.Active)
+
+	// Secret tidak ditampilkan saat GET
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/webhooks/"+created.ID, nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotContains(t, getResponseBody(t, resp), created.Secret)
+}